@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultKeyTTL applies when the key server sends no max-age
+	defaultKeyTTL = time.Hour
+
+	// minRefreshInterval throttles refetches, whether triggered by an
+	// expired key set or an unknown key ID
+	minRefreshInterval = 30 * time.Second
+)
+
+// jwk is a single RSA JSON Web Key
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// keySet caches the signing keys from a JWKS endpoint, honouring the
+// endpoint's Cache-Control max-age. Fetches run outside the lock, one at a
+// time, and cached keys keep being served while the set refreshes.
+type keySet struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu         sync.Mutex
+	keys       map[string]*rsa.PublicKey
+	expires    time.Time
+	fetchedAt  time.Time
+	fetchErr   error         // error of the last fetch, if it failed
+	refreshing chan struct{} // closed when the fetch in flight finishes
+}
+
+func newKeySet(url string, client *http.Client, now func() time.Time) *keySet {
+	return &keySet{url: url, client: client, now: now}
+}
+
+// key returns the public key with the given ID, refreshing the cache when it
+// has expired or when the key is unknown (keys are rotated regularly).
+// Refreshes start at most once per minRefreshInterval.
+func (s *keySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	now := s.now()
+	key, known := s.keys[kid]
+	if known && !now.After(s.expires) {
+		s.mu.Unlock()
+		return key, nil
+	}
+
+	if s.refreshing == nil && now.Sub(s.fetchedAt) < minRefreshInterval {
+		err := s.fetchErr
+		s.mu.Unlock()
+		if known {
+			return key, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, invalid("unknown key ID %q", kid)
+	}
+
+	// The fetch outlives the request that started it, since other requests
+	// may be waiting on it
+	done := s.refresh(context.WithoutCancel(ctx))
+	s.mu.Unlock()
+	if known {
+		// Keep serving the stale key rather than holding up the request
+		return key, nil
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", ErrKeysUnavailable, ctx.Err())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, known := s.keys[kid]; known {
+		return key, nil
+	}
+	if s.fetchErr != nil {
+		return nil, s.fetchErr
+	}
+	return nil, invalid("unknown key ID %q", kid)
+}
+
+// refresh starts fetching the key set unless a fetch is already in flight,
+// and returns a channel closed when the fetch finishes; callers must hold s.mu
+func (s *keySet) refresh(ctx context.Context) <-chan struct{} {
+	if s.refreshing != nil {
+		return s.refreshing
+	}
+
+	done := make(chan struct{})
+	s.refreshing = done
+	s.fetchedAt = s.now()
+	fetchedAt := s.fetchedAt
+
+	go func() {
+		defer close(done)
+		keys, ttl, err := s.fetch(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.refreshing = nil
+		s.fetchErr = err
+		if err == nil {
+			s.keys = keys
+			s.expires = fetchedAt.Add(ttl)
+		}
+	}()
+	return done
+}
+
+// fetch downloads the key set and returns it with how long it may be cached
+func (s *keySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("%w: key server returned %s", ErrKeysUnavailable, response.Status)
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&document); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrKeysUnavailable, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(document.Keys))
+	for _, entry := range document.Keys {
+		if entry.KeyType != "RSA" || (entry.Use != "" && entry.Use != "sig") {
+			continue
+		}
+		key, err := entry.publicKey()
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: key %q: %v", ErrKeysUnavailable, entry.KeyID, err)
+		}
+		keys[entry.KeyID] = key
+	}
+
+	return keys, maxAge(response.Header.Get("Cache-Control")), nil
+}
+
+// publicKey decodes the key's modulus and exponent
+func (k jwk) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("malformed modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("malformed exponent")
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("unsupported exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+// maxAge reads the max-age directive of a Cache-Control header
+func maxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultKeyTTL
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keyServer publishes RSA keys by ID, counting fetches. A fetch waits for
+// the gate while it is set and fails while failing is set.
+type keyServer struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetches int
+	gate    chan struct{}
+	failing bool
+}
+
+func (k *keyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	k.mu.Lock()
+	k.fetches++
+	gate, failing := k.gate, k.failing
+	entries := []jwk{}
+	for kid, key := range k.keys {
+		entries = append(entries, jwk{
+			KeyType: "RSA",
+			KeyID:   kid,
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	k.mu.Unlock()
+
+	if gate != nil {
+		<-gate
+	}
+	if failing {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Cache-Control", "max-age=60")
+	json.NewEncoder(w).Encode(map[string]any{"keys": entries})
+}
+
+func (k *keyServer) set(change func(k *keyServer)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	change(k)
+}
+
+func (k *keyServer) fetchCount() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.fetches
+}
+
+func newPublicKey(t *testing.T) *rsa.PublicKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return &key.PublicKey
+}
+
+// newTestKeySet returns a key set over a key server publishing "old", with
+// the key set's clock
+func newTestKeySet(t *testing.T) (*keySet, *keyServer, *time.Time) {
+	t.Helper()
+	keys := &keyServer{keys: map[string]*rsa.PublicKey{"old": newPublicKey(t)}}
+	server := httptest.NewServer(keys)
+	t.Cleanup(server.Close)
+
+	// The clock only moves while no request is running
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	set := newKeySet(server.URL, server.Client(), func() time.Time { return now })
+	if _, err := set.key(context.Background(), "old"); err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	return set, keys, &now
+}
+
+// settle waits for the fetch in flight, if any
+func (s *keySet) settle() {
+	s.mu.Lock()
+	done := s.refreshing
+	s.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+func TestKeySetServesCachedKeysWhileRefreshing(t *testing.T) {
+	set, keys, now := newTestKeySet(t)
+	ctx := context.Background()
+
+	// The key set expires and the key server stalls with a rotated key
+	*now = now.Add(2 * time.Minute)
+	gate := make(chan struct{})
+	keys.set(func(k *keyServer) {
+		k.gate = gate
+		k.keys["new"] = newPublicKey(t)
+	})
+
+	served := make(chan error)
+	go func() {
+		_, err := set.key(ctx, "old")
+		served <- err
+	}()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("stale key: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stale key waited on the refresh")
+	}
+
+	// Requests for the rotated key all wait on the fetch in flight
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := set.key(ctx, "new")
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("rotated key: %v", err)
+		}
+	}
+	if fetches := keys.fetchCount(); fetches != 2 {
+		t.Errorf("fetched %d times, want the initial fetch and one refresh", fetches)
+	}
+}
+
+func TestKeySetThrottlesStaleRefreshes(t *testing.T) {
+	set, keys, now := newTestKeySet(t)
+	ctx := context.Background()
+	keys.set(func(k *keyServer) { k.failing = true })
+
+	// Expired keys are served while refreshes fail
+	*now = now.Add(2 * time.Minute)
+	for range 10 {
+		if _, err := set.key(ctx, "old"); err != nil {
+			t.Fatalf("stale key: %v", err)
+		}
+		set.settle()
+	}
+	if fetches := keys.fetchCount(); fetches != 2 {
+		t.Errorf("fetched %d times, want one refresh per interval", fetches)
+	}
+
+	// Unknown keys fail with the fetch error until the next refresh
+	if _, err := set.key(ctx, "new"); !errors.Is(err, ErrKeysUnavailable) {
+		t.Errorf("unknown key error = %v, want ErrKeysUnavailable", err)
+	}
+	if fetches := keys.fetchCount(); fetches != 2 {
+		t.Errorf("fetched %d times, want the unknown key throttled", fetches)
+	}
+
+	*now = now.Add(minRefreshInterval)
+	keys.set(func(k *keyServer) { k.failing = false })
+	if _, err := set.key(ctx, "old"); err != nil {
+		t.Fatalf("stale key: %v", err)
+	}
+	set.settle()
+	if fetches := keys.fetchCount(); fetches != 3 {
+		t.Errorf("fetched %d times, want a refresh once the interval passed", fetches)
+	}
+	if _, err := set.key(ctx, "old"); err != nil {
+		t.Errorf("refreshed key: %v", err)
+	}
+	if fetches := keys.fetchCount(); fetches != 3 {
+		t.Errorf("fetched %d times, want the refreshed set cached", fetches)
+	}
+}
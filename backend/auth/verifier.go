@@ -0,0 +1,223 @@
+// Package auth verifies Firebase ID tokens.
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// GoogleJWKSURL publishes the public keys Firebase signs ID tokens with
+const GoogleJWKSURL = "https://www.googleapis.com/service_accounts/v1/jwk/securetoken@system.gserviceaccount.com"
+
+// clockSkew is how far the issuing server's clock may drift from ours
+const clockSkew = time.Minute
+
+var (
+	// ErrInvalidToken is returned for malformed, forged or expired tokens
+	ErrInvalidToken = errors.New("invalid ID token")
+
+	// ErrKeysUnavailable is returned when the signing keys cannot be fetched
+	ErrKeysUnavailable = errors.New("signing keys unavailable")
+)
+
+// Config configures a Verifier
+type Config struct {
+	// ProjectID is the Firebase project; tokens must be issued for it
+	ProjectID string
+
+	// JWKSURL serves the signing keys; defaults to GoogleJWKSURL
+	JWKSURL string
+
+	// HTTPClient fetches the keys; defaults to a client with a short timeout
+	HTTPClient *http.Client
+
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// ConfigFromEnv reads FIREBASE_PROJECT_ID and the optional FIREBASE_JWKS_URL
+func ConfigFromEnv() Config {
+	return Config{
+		ProjectID: os.Getenv("FIREBASE_PROJECT_ID"),
+		JWKSURL:   os.Getenv("FIREBASE_JWKS_URL"),
+	}
+}
+
+// Token is a verified Firebase ID token
+type Token struct {
+	UID           string    `json:"uid"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	IssuedAt      time.Time `json:"issued_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// Verifier checks Firebase ID tokens against the project's signing keys
+type Verifier struct {
+	projectID string
+	issuer    string
+	keys      *keySet
+	now       func() time.Time
+}
+
+// NewVerifier returns a verifier for the configured project
+func NewVerifier(config Config) (*Verifier, error) {
+	if config.ProjectID == "" {
+		return nil, fmt.Errorf("FIREBASE_PROJECT_ID is not set")
+	}
+	if config.JWKSURL == "" {
+		config.JWKSURL = GoogleJWKSURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &Verifier{
+		projectID: config.ProjectID,
+		issuer:    "https://securetoken.google.com/" + config.ProjectID,
+		keys:      newKeySet(config.JWKSURL, config.HTTPClient, config.Now),
+		now:       config.Now,
+	}, nil
+}
+
+// header is the JOSE header of a token
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// claims are the registered and Firebase-specific claims we check
+type claims struct {
+	Issuer        string   `json:"iss"`
+	Audience      audience `json:"aud"`
+	Subject       string   `json:"sub"`
+	IssuedAt      float64  `json:"iat"`
+	ExpiresAt     float64  `json:"exp"`
+	AuthTime      float64  `json:"auth_time"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+}
+
+// audience accepts the "aud" claim as either a string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, entry := range a {
+		if entry == value {
+			return true
+		}
+	}
+	return false
+}
+
+// invalid wraps a reason as an ErrInvalidToken
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidToken, fmt.Sprintf(format, args...))
+}
+
+// Verify checks the token's RS256 signature, issuer, audience and lifetime
+// and returns the identity it carries
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, invalid("token must have three segments")
+	}
+
+	var head header
+	if err := decodeSegment(parts[0], &head); err != nil {
+		return nil, invalid("malformed header")
+	}
+	if head.Algorithm != "RS256" {
+		return nil, invalid("unexpected signing algorithm %q", head.Algorithm)
+	}
+	if head.KeyID == "" {
+		return nil, invalid("missing key ID")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed signature")
+	}
+
+	key, err := v.keys.key(ctx, head.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, invalid("signature does not match")
+	}
+
+	var body claims
+	if err := decodeSegment(parts[1], &body); err != nil {
+		return nil, invalid("malformed claims")
+	}
+
+	now := v.now()
+	switch {
+	case body.Issuer != v.issuer:
+		return nil, invalid("unexpected issuer %q", body.Issuer)
+	case !body.Audience.contains(v.projectID):
+		return nil, invalid("token was not issued for this project")
+	case body.Subject == "":
+		return nil, invalid("missing subject")
+	case len(body.Subject) > 128:
+		return nil, invalid("subject is too long")
+	case body.ExpiresAt == 0 || now.After(unixTime(body.ExpiresAt).Add(clockSkew)):
+		return nil, invalid("token has expired")
+	case unixTime(body.IssuedAt).After(now.Add(clockSkew)):
+		return nil, invalid("token was issued in the future")
+	case body.AuthTime != 0 && unixTime(body.AuthTime).After(now.Add(clockSkew)):
+		return nil, invalid("authentication time is in the future")
+	}
+
+	return &Token{
+		UID:           body.Subject,
+		Email:         body.Email,
+		EmailVerified: body.EmailVerified,
+		IssuedAt:      unixTime(body.IssuedAt),
+		ExpiresAt:     unixTime(body.ExpiresAt),
+	}, nil
+}
+
+// decodeSegment decodes a base64url JSON token segment
+func decodeSegment(segment string, dest any) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, dest)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(int64(seconds), 0)
+}
@@ -0,0 +1,239 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"tutor-backend/auth"
+	"tutor-backend/auth/authtest"
+)
+
+const projectID = "tutor-match-test"
+
+// clock is a settable time source for the verifier
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newIssuer(t *testing.T) *authtest.Issuer {
+	t.Helper()
+	issuer, err := authtest.NewIssuer(projectID)
+	if err != nil {
+		t.Fatalf("NewIssuer: %v", err)
+	}
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func newVerifier(t *testing.T, issuer *authtest.Issuer, now func() time.Time) *auth.Verifier {
+	t.Helper()
+	config := issuer.Config()
+	config.Now = now
+	verifier, err := auth.NewVerifier(config)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	return verifier
+}
+
+func sign(t *testing.T, issuer *authtest.Issuer, claims map[string]any) string {
+	t.Helper()
+	token, err := issuer.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return token
+}
+
+// encode builds a token from a header, claims and a raw signature
+func encode(t *testing.T, header, claims map[string]any, signature []byte) string {
+	t.Helper()
+	segments := make([]string, 0, 3)
+	for _, part := range []map[string]any{header, claims} {
+		data, err := json.Marshal(part)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		segments = append(segments, base64.RawURLEncoding.EncodeToString(data))
+	}
+	return strings.Join(segments, ".") + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// keyID returns the key ID in the header of a token
+func keyID(t *testing.T, token string) string {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	var header struct {
+		KeyID string `json:"kid"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	return header.KeyID
+}
+
+func TestVerifyAcceptsValidToken(t *testing.T) {
+	issuer := newIssuer(t)
+	verifier := newVerifier(t, issuer, nil)
+
+	token, err := issuer.Token("user-1", "ada@example.com")
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+
+	verified, err := verifier.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if verified.UID != "user-1" || verified.Email != "ada@example.com" || !verified.EmailVerified {
+		t.Errorf("Verify = %+v, want uid user-1, email ada@example.com, verified", verified)
+	}
+}
+
+func TestVerifyRejectsInvalidClaims(t *testing.T) {
+	issuer := newIssuer(t)
+	verifier := newVerifier(t, issuer, nil)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		change func(claims map[string]any)
+	}{
+		{"expired", func(claims map[string]any) {
+			claims["iat"] = now.Add(-2 * time.Hour).Unix()
+			claims["exp"] = now.Add(-time.Hour).Unix()
+		}},
+		{"missing expiry", func(claims map[string]any) { delete(claims, "exp") }},
+		{"issued in the future", func(claims map[string]any) { claims["iat"] = now.Add(time.Hour).Unix() }},
+		{"authenticated in the future", func(claims map[string]any) { claims["auth_time"] = now.Add(time.Hour).Unix() }},
+		{"wrong audience", func(claims map[string]any) { claims["aud"] = "another-project" }},
+		{"wrong issuer", func(claims map[string]any) { claims["iss"] = "https://securetoken.google.com/another-project" }},
+		{"empty subject", func(claims map[string]any) { claims["sub"] = "" }},
+		{"long subject", func(claims map[string]any) { claims["sub"] = strings.Repeat("a", 129) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := issuer.Claims("user-1", "ada@example.com")
+			test.change(claims)
+
+			_, err := verifier.Verify(context.Background(), sign(t, issuer, claims))
+			if !errors.Is(err, auth.ErrInvalidToken) {
+				t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsOtherAlgorithms(t *testing.T) {
+	issuer := newIssuer(t)
+	verifier := newVerifier(t, issuer, nil)
+
+	valid := sign(t, issuer, issuer.Claims("user-1", "ada@example.com"))
+	kid := keyID(t, valid)
+	claims := issuer.Claims("user-1", "ada@example.com")
+
+	header := map[string]any{"alg": "HS256", "kid": kid, "typ": "JWT"}
+	unsigned := encode(t, header, claims, nil)
+	mac := hmac.New(sha256.New, []byte("shared secret"))
+	mac.Write([]byte(unsigned[:strings.LastIndex(unsigned, ".")]))
+	hs256 := encode(t, header, claims, mac.Sum(nil))
+
+	tests := map[string]string{
+		"alg none":  encode(t, map[string]any{"alg": "none", "kid": kid, "typ": "JWT"}, claims, nil),
+		"HS256":     hs256,
+		"malformed": "not-a-token",
+		"tampered claims": func() string {
+			forged := issuer.Claims("admin", "admin@example.com")
+			payload, _ := json.Marshal(forged)
+			parts := strings.Split(valid, ".")
+			return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+		}(),
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), token)
+			if !errors.Is(err, auth.ErrInvalidToken) {
+				t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTokensFromAnotherKey(t *testing.T) {
+	issuer := newIssuer(t)
+	verifier := newVerifier(t, issuer, nil)
+
+	// Same project and key ID, different private key
+	forger := newIssuer(t)
+	token := sign(t, forger, issuer.Claims("user-1", "ada@example.com"))
+
+	if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Verify error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifyAfterKeyRotation(t *testing.T) {
+	issuer := newIssuer(t)
+	clock := &clock{now: time.Now()}
+	verifier := newVerifier(t, issuer, clock.Now)
+
+	before, err := issuer.Token("user-1", "ada@example.com")
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if _, err := verifier.Verify(context.Background(), before); err != nil {
+		t.Fatalf("Verify before rotation: %v", err)
+	}
+
+	if err := issuer.RotateKey(); err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	after, err := issuer.Token("user-1", "ada@example.com")
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if keyID(t, after) == keyID(t, before) {
+		t.Fatalf("rotation kept key ID %q", keyID(t, before))
+	}
+
+	// Refetches for unknown key IDs are throttled, so the new kid is
+	// rejected until the throttle has passed
+	if _, err := verifier.Verify(context.Background(), after); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Verify new key right after rotation error = %v, want ErrInvalidToken", err)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err := verifier.Verify(context.Background(), after); err != nil {
+		t.Errorf("Verify new key once refetched: %v", err)
+	}
+
+	// The refetched key set no longer publishes the old key
+	if _, err := verifier.Verify(context.Background(), before); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Verify old key after rotation error = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Package calendar writes iCalendar (RFC 5545) feeds
+package calendar
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event statuses
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// productID identifies this application as the feed's producer
+const productID = "-//Tutor Match//Sessions//EN"
+
+// Event is one VEVENT of a feed
+type Event struct {
+	// UID must stay the same for the event across feed refreshes
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Calendar is a VCALENDAR whose events are shown in one timezone
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Events   []Event
+}
+
+// Encode renders the calendar as iCalendar text. Event times are written in
+// the calendar's timezone, which is described by a VTIMEZONE covering every
+// event; UTC calendars need none. now is the DTSTAMP of every event.
+func (cal Calendar) Encode(now time.Time) []byte {
+	loc := cal.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", productID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		w.line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+	if loc != time.UTC {
+		w.line("X-WR-TIMEZONE", loc.String())
+		from, to := now, now
+		for _, event := range cal.Events {
+			if event.Start.Before(from) {
+				from = event.Start
+			}
+			if event.End.After(to) {
+				to = event.End
+			}
+		}
+		writeTimezone(w, loc, from, to)
+	}
+
+	for _, event := range cal.Events {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", escapeText(event.UID))
+		w.line("DTSTAMP", formatUTC(now))
+		w.timeLine("DTSTART", event.Start, loc)
+		w.timeLine("DTEND", event.End, loc)
+		w.line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			w.line("DESCRIPTION", escapeText(event.Description))
+		}
+		if event.Status != "" {
+			w.line("STATUS", event.Status)
+		}
+		if !event.Created.IsZero() {
+			w.line("CREATED", formatUTC(event.Created))
+		}
+		if !event.LastModified.IsZero() {
+			w.line("LAST-MODIFIED", formatUTC(event.LastModified))
+		}
+		w.line("END", "VEVENT")
+	}
+
+	w.line("END", "VCALENDAR")
+	return []byte(w.b.String())
+}
+
+// writer builds iCalendar content lines
+type writer struct {
+	b strings.Builder
+}
+
+// maxLineOctets is the longest a content line may be before it is folded
+const maxLineOctets = 75
+
+// line writes "name:value", folding it onto continuation lines so no line
+// exceeds 75 octets without splitting a UTF-8 character
+func (w *writer) line(name, value string) {
+	content := name + ":" + value
+	length := 0
+	for _, r := range content {
+		size := utf8.RuneLen(r)
+		if length+size > maxLineOctets {
+			w.b.WriteString("\r\n ")
+			length = 1
+		}
+		w.b.WriteRune(r)
+		length += size
+	}
+	w.b.WriteString("\r\n")
+}
+
+// timeLine writes a date-time property in loc, with a TZID unless loc is UTC
+func (w *writer) timeLine(name string, t time.Time, loc *time.Location) {
+	if loc == time.UTC {
+		w.line(name, formatUTC(t))
+		return
+	}
+	w.line(name+";TZID="+loc.String(), formatLocal(t.In(loc)))
+}
+
+// formatUTC formats a time as an iCalendar UTC date-time
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// formatLocal formats a time's wall clock as an iCalendar local date-time
+func formatLocal(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+// escapeText escapes an iCalendar TEXT value
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
@@ -0,0 +1,157 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Math", "Math"},
+		{"Math, Physics; Chemistry", `Math\, Physics\; Chemistry`},
+		{`C:\path`, `C:\\path`},
+		{"first\r\nsecond\nthird", `first\nsecond\nthird`},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.value); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWriterFoldsLongLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		lines int
+	}{
+		{"short", "Math", 1},
+		{"exactly 75 octets", strings.Repeat("a", 75-len("SUMMARY:")), 1},
+		{"one octet over", strings.Repeat("a", 76-len("SUMMARY:")), 2},
+		{"multi-byte characters", strings.Repeat("é", 100), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &writer{}
+			w.line("SUMMARY", tt.value)
+			text := w.b.String()
+			if !strings.HasSuffix(text, "\r\n") {
+				t.Fatalf("line %q does not end in CRLF", text)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("folded into %d lines, want %d: %q", len(lines), tt.lines, lines)
+			}
+			unfolded := lines[0]
+			for i, line := range lines {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d is %d octets", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a character: %q", i, line)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(line, " ") {
+						t.Errorf("continuation line %d does not start with a space", i)
+					}
+					unfolded += line[1:]
+				}
+			}
+			if unfolded != "SUMMARY:"+tt.value {
+				t.Errorf("unfolded = %q", unfolded)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// New York moves to summer time on 8 March 2026
+	cal := Calendar{
+		Name:     "Ada's sessions",
+		Location: newYork,
+		Events: []Event{
+			{
+				UID:         "session-1@tutor",
+				Summary:     "Math, with Grace; week 1",
+				Description: strings.Repeat("Bring the worksheet. ", 6),
+				Start:       time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC),
+				End:         time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+				Status:      StatusConfirmed,
+			},
+			{
+				UID:     "session-2@tutor",
+				Summary: "Math, with Grace; week 2",
+				Start:   time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC),
+				End:     time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC),
+				Status:  StatusTentative,
+			},
+		},
+	}
+
+	encoded := cal.Encode(now)
+	text := string(encoded)
+	for _, want := range []string{
+		"X-WR-CALNAME:Ada's sessions\r\n",
+		"X-WR-TIMEZONE:America/New_York\r\n",
+		"BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\nBEGIN:STANDARD\r\n",
+		"BEGIN:DAYLIGHT\r\nDTSTART:20260308T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\n",
+		"DTSTAMP:20260301T120000Z\r\n",
+		"DTSTART;TZID=America/New_York:20260302T090000\r\n",
+		"DTSTART;TZID=America/New_York:20260309T090000\r\n",
+		`SUMMARY:Math\, with Grace\; week 1` + "\r\n",
+		"STATUS:TENTATIVE\r\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("feed does not contain %q", want)
+		}
+	}
+	for i, line := range bytes.Split(encoded, []byte("\r\n")) {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d is %d octets: %q", i, len(line), line)
+		}
+	}
+
+	// The feed reads back as the same events at the same instants
+	events, err := Parse(bytes.NewReader(encoded), time.UTC)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(events) != len(cal.Events) {
+		t.Fatalf("read back %d events, want %d", len(events), len(cal.Events))
+	}
+	for i, event := range events {
+		want := cal.Events[i]
+		if event.UID != want.UID || event.Summary != want.Summary || event.Status != want.Status ||
+			!event.Start.Equal(want.Start) || !event.End.Equal(want.End) || event.Location.String() != "America/New_York" {
+			t.Errorf("event %d read back as %+v, want %+v", i, event, want)
+		}
+	}
+}
+
+func TestEncodeUTCNeedsNoTimezone(t *testing.T) {
+	cal := Calendar{Events: []Event{{
+		UID:   "session-1@tutor",
+		Start: time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+	}}}
+	text := string(cal.Encode(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+	if strings.Contains(text, "VTIMEZONE") || strings.Contains(text, "TZID") {
+		t.Errorf("UTC feed describes a timezone:\n%s", text)
+	}
+	if !strings.Contains(text, "DTSTART:20260302T140000Z\r\n") {
+		t.Errorf("UTC feed does not give the start in UTC:\n%s", text)
+	}
+}
@@ -0,0 +1,313 @@
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportedEvent is a VEVENT read from an uploaded calendar
+type ImportedEvent struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	// AllDay is set for events given as dates rather than date-times
+	AllDay bool
+	// Location is the timezone the start was given in, which the
+	// event's recurrences keep their wall-clock time in
+	Location *time.Location
+	RRule    string
+	ExDates  []time.Time
+	// RecurrenceID marks an event that overrides the occurrence of the
+	// recurring event with the same UID that would have started then
+	RecurrenceID *time.Time
+	Status       string
+	// Transparent events do not block time, e.g. reminders
+	Transparent bool
+}
+
+// contentLine is an unfolded property line
+type contentLine struct {
+	number int
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the events of an iCalendar file. Times without a timezone, and
+// times in timezones that are not in Go's database, are read in defaultLoc.
+// Components nested in events, such as alarms, are ignored.
+func Parse(r io.Reader, defaultLoc *time.Location) ([]ImportedEvent, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || lines[0].name != "BEGIN" || !strings.EqualFold(lines[0].value, "VCALENDAR") {
+		return nil, errors.New("not an iCalendar file: it must start with BEGIN:VCALENDAR")
+	}
+
+	events := []ImportedEvent{}
+	var event *eventBuilder
+	var components []string
+	for _, line := range lines {
+		switch line.name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(line.value))
+			if len(components) == 2 && components[1] == "VEVENT" {
+				event = &eventBuilder{event: ImportedEvent{Location: defaultLoc}}
+			}
+			continue
+		case "END":
+			if len(components) == 0 || !strings.EqualFold(components[len(components)-1], line.value) {
+				return nil, fmt.Errorf("line %d: END:%s does not match an open component", line.number, line.value)
+			}
+			if len(components) == 2 && event != nil {
+				if event.hasStart {
+					events = append(events, event.finish())
+				}
+				event = nil
+			}
+			components = components[:len(components)-1]
+			continue
+		}
+		if event == nil || len(components) != 2 {
+			continue
+		}
+
+		if err := event.set(line, defaultLoc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+	}
+	if len(components) != 0 {
+		return nil, fmt.Errorf("the calendar ends before END:%s", components[len(components)-1])
+	}
+
+	return events, nil
+}
+
+// eventBuilder collects the properties of a VEVENT being parsed
+type eventBuilder struct {
+	event    ImportedEvent
+	hasStart bool
+	duration *time.Duration
+}
+
+// set reads a property line into the event
+func (b *eventBuilder) set(line contentLine, defaultLoc *time.Location) error {
+	event := &b.event
+	switch line.name {
+	case "UID":
+		event.UID = line.value
+	case "SUMMARY":
+		event.Summary = unescapeText(line.value)
+	case "STATUS":
+		event.Status = strings.ToUpper(line.value)
+	case "TRANSP":
+		event.Transparent = strings.EqualFold(line.value, "TRANSPARENT")
+	case "RRULE":
+		event.RRule = line.value
+	case "DTSTART":
+		start, loc, allDay, err := parseDateTime(line.value, line.params, defaultLoc)
+		if err != nil {
+			return fmt.Errorf("invalid DTSTART: %w", err)
+		}
+		event.Start, event.Location, event.AllDay = start, loc, allDay
+		b.hasStart = true
+	case "DTEND":
+		end, _, _, err := parseDateTime(line.value, line.params, defaultLoc)
+		if err != nil {
+			return fmt.Errorf("invalid DTEND: %w", err)
+		}
+		event.End = end
+	case "DURATION":
+		length, err := parseDuration(line.value)
+		if err != nil {
+			return fmt.Errorf("invalid DURATION: %w", err)
+		}
+		b.duration = &length
+	case "RECURRENCE-ID":
+		id, _, _, err := parseDateTime(line.value, line.params, defaultLoc)
+		if err != nil {
+			return fmt.Errorf("invalid RECURRENCE-ID: %w", err)
+		}
+		event.RecurrenceID = &id
+	case "EXDATE":
+		for _, value := range strings.Split(line.value, ",") {
+			exdate, _, _, err := parseDateTime(value, line.params, defaultLoc)
+			if err != nil {
+				return fmt.Errorf("invalid EXDATE: %w", err)
+			}
+			event.ExDates = append(event.ExDates, exdate)
+		}
+	}
+	return nil
+}
+
+// finish returns the event with its end filled in from its duration, or the
+// RFC 5545 default of one day for all-day events and no time at all otherwise
+func (b *eventBuilder) finish() ImportedEvent {
+	event := b.event
+	if event.End.IsZero() {
+		switch {
+		case b.duration != nil:
+			event.End = event.Start.Add(*b.duration)
+		case event.AllDay:
+			event.End = event.Start.AddDate(0, 0, 1)
+		default:
+			event.End = event.Start
+		}
+	}
+	return event
+}
+
+// unfold reads content lines, joining folded continuation lines
+func unfold(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var raw []string
+	var numbers []int
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if number == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		switch {
+		case text == "":
+		case (text[0] == ' ' || text[0] == '\t') && len(raw) > 0:
+			raw[len(raw)-1] += text[1:]
+		default:
+			raw = append(raw, text)
+			numbers = append(numbers, number)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	lines := make([]contentLine, 0, len(raw))
+	for i, text := range raw {
+		line, err := parseContentLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", numbers[i], err)
+		}
+		line.number = numbers[i]
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// parseContentLine splits "NAME;PARAM=value:VALUE", allowing quoted
+// parameter values that contain ';' or ':'
+func parseContentLine(text string) (contentLine, error) {
+	var parts []string
+	quoted := false
+	start := 0
+	for i, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == ';':
+			parts = append(parts, text[start:i])
+			start = i + 1
+		case r == ':':
+			parts = append(parts, text[start:i])
+			line := contentLine{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: text[i+1:]}
+			for _, param := range parts[1:] {
+				name, value, _ := strings.Cut(param, "=")
+				line.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+			}
+			return line, nil
+		}
+	}
+	return contentLine{}, fmt.Errorf("invalid content line %q", text)
+}
+
+// parseDateTime reads a DATE or DATE-TIME value, returning the timezone it
+// was given in and whether it was a date
+func parseDateTime(value string, params map[string]string, defaultLoc *time.Location) (time.Time, *time.Location, bool, error) {
+	value = strings.TrimSpace(value)
+	loc := defaultLoc
+	if tzid := strings.TrimPrefix(params["TZID"], "/"); tzid != "" {
+		if named, err := time.LoadLocation(tzid); err == nil {
+			loc = named
+		}
+	}
+
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len("20060102") {
+		date, err := time.ParseInLocation("20060102", value, loc)
+		return date, loc, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, time.UTC, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, loc, false, err
+}
+
+// parseDuration reads a DURATION value such as "PT1H30M", "P1D" or "-P2W"
+func parseDuration(value string) (time.Duration, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(text, "-"):
+		sign, text = -1, text[1:]
+	case strings.HasPrefix(text, "+"):
+		text = text[1:]
+	}
+	if !strings.HasPrefix(text, "P") || len(text) < 3 {
+		return 0, fmt.Errorf("%q is not a duration", value)
+	}
+
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+	var total time.Duration
+	inTime := false
+	number := ""
+	for i := 1; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == 'T':
+			inTime = true
+		case ch >= '0' && ch <= '9':
+			number += string(ch)
+		default:
+			unit, ok := units[ch]
+			// Hours, minutes and seconds only come after T, and weeks and
+			// days only before it; durations have no months
+			dateUnit := ch == 'W' || ch == 'D'
+			if !ok || number == "" || dateUnit == inTime {
+				return 0, fmt.Errorf("%q is not a duration", value)
+			}
+			n, _ := strconv.Atoi(number)
+			total += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("%q is not a duration", value)
+	}
+	return sign * total, nil
+}
+
+// unescapeText reverses escapeText
+func unescapeText(value string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(value)
+}
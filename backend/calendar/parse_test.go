@@ -0,0 +1,221 @@
+package calendar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ics wraps property lines in a calendar with one event
+func ics(lines ...string) string {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0", "BEGIN:VEVENT"}, lines...)
+	return strings.Join(append(all, "END:VEVENT", "END:VCALENDAR"), "\r\n") + "\r\n"
+}
+
+func TestParse(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want ImportedEvent
+	}{
+		{
+			name: "UTC times",
+			text: ics("UID:utc", "DTSTART:20260302T150000Z", "DTEND:20260302T160000Z"),
+			want: ImportedEvent{
+				UID:      "utc",
+				Start:    time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+				End:      time.Date(2026, 3, 2, 16, 0, 0, 0, time.UTC),
+				Location: time.UTC,
+			},
+		},
+		{
+			name: "TZID on summer time",
+			text: ics("UID:tzid", "DTSTART;TZID=America/New_York:20260310T090000", "DURATION:PT1H30M"),
+			want: ImportedEvent{
+				UID:      "tzid",
+				Start:    time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC),
+				End:      time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC),
+				Location: newYork,
+			},
+		},
+		{
+			name: "floating time in the default timezone",
+			text: ics("UID:floating", "DTSTART:20260302T090000", "DTEND;TZID=\"/Unknown/Zone\":20260302T100000"),
+			want: ImportedEvent{
+				UID:      "floating",
+				Start:    time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+				End:      time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+				Location: berlin,
+			},
+		},
+		{
+			name: "all-day event lasts a day",
+			text: ics("UID:all-day", "DTSTART;VALUE=DATE:20260302", "TRANSP:TRANSPARENT"),
+			want: ImportedEvent{
+				UID:         "all-day",
+				Start:       time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC),
+				End:         time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC),
+				AllDay:      true,
+				Location:    berlin,
+				Transparent: true,
+			},
+		},
+		{
+			name: "recurring with exceptions",
+			text: ics(
+				"UID:weekly",
+				"DTSTART;TZID=America/New_York:20260302T090000",
+				"DTEND;TZID=America/New_York:20260302T100000",
+				"RRULE:FREQ=WEEKLY;UNTIL=20260330T130000Z",
+				"EXDATE;TZID=America/New_York:20260309T090000,20260316T090000",
+				"STATUS:confirmed",
+			),
+			want: ImportedEvent{
+				UID:      "weekly",
+				Start:    time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC),
+				End:      time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+				Location: newYork,
+				RRule:    "FREQ=WEEKLY;UNTIL=20260330T130000Z",
+				ExDates: []time.Time{
+					time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC),
+					time.Date(2026, 3, 16, 13, 0, 0, 0, time.UTC),
+				},
+				Status: StatusConfirmed,
+			},
+		},
+		{
+			name: "override of one occurrence",
+			text: ics(
+				"UID:weekly",
+				"RECURRENCE-ID:20260309T130000Z",
+				"DTSTART:20260309T150000Z",
+				"DTEND:20260309T160000Z",
+			),
+			want: ImportedEvent{
+				UID:          "weekly",
+				Start:        time.Date(2026, 3, 9, 15, 0, 0, 0, time.UTC),
+				End:          time.Date(2026, 3, 9, 16, 0, 0, 0, time.UTC),
+				Location:     time.UTC,
+				RecurrenceID: func() *time.Time { id := time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC); return &id }(),
+			},
+		},
+		{
+			name: "folded and escaped text",
+			text: ics(
+				"UID:folded",
+				"SUMMARY:Office hours\\, room 4\\; bring notes\\nand a pen: ",
+				" \\\\ no laptops",
+				"DTSTART:20260302T150000Z",
+				"BEGIN:VALARM",
+				"DTSTART:20260302T140000Z",
+				"END:VALARM",
+			),
+			want: ImportedEvent{
+				UID:      "folded",
+				Summary:  "Office hours, room 4; bring notes\nand a pen: \\ no laptops",
+				Start:    time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+				End:      time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+				Location: time.UTC,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := Parse(strings.NewReader(tt.text), berlin)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("got %d events, want 1", len(events))
+			}
+			got := events[0]
+			if got.UID != tt.want.UID || got.Summary != tt.want.Summary || got.AllDay != tt.want.AllDay ||
+				got.RRule != tt.want.RRule || got.Status != tt.want.Status || got.Transparent != tt.want.Transparent {
+				t.Errorf("event = %+v, want %+v", got, tt.want)
+			}
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+				t.Errorf("event runs %v-%v, want %v-%v", got.Start, got.End, tt.want.Start, tt.want.End)
+			}
+			if got.Location.String() != tt.want.Location.String() {
+				t.Errorf("location = %v, want %v", got.Location, tt.want.Location)
+			}
+			if len(got.ExDates) != len(tt.want.ExDates) {
+				t.Fatalf("exdates = %v, want %v", got.ExDates, tt.want.ExDates)
+			}
+			for i := range got.ExDates {
+				if !got.ExDates[i].Equal(tt.want.ExDates[i]) {
+					t.Errorf("exdate %d = %v, want %v", i, got.ExDates[i], tt.want.ExDates[i])
+				}
+			}
+			if (got.RecurrenceID == nil) != (tt.want.RecurrenceID == nil) ||
+				(got.RecurrenceID != nil && !got.RecurrenceID.Equal(*tt.want.RecurrenceID)) {
+				t.Errorf("recurrence ID = %v, want %v", got.RecurrenceID, tt.want.RecurrenceID)
+			}
+		})
+	}
+}
+
+func TestParseRejectsMalformedCalendars(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"not a calendar", "BEGIN:VEVENT\r\nEND:VEVENT\r\n", "not an iCalendar file"},
+		{"unclosed component", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n", "ends before END:VEVENT"},
+		{"mismatched end", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n", "line 3: END:VCALENDAR"},
+		{"line without a value", ics("UID"), "invalid content line"},
+		{"bad start", ics("DTSTART:tomorrow"), "invalid DTSTART"},
+		{"bad duration", ics("DTSTART:20260302T150000Z", "DURATION:P1M"), "invalid DURATION"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.text), time.UTC)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Parse error = %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"PT1H30M", 90 * time.Minute},
+		{"P1D", 24 * time.Hour},
+		{"-P2W", -14 * 24 * time.Hour},
+		{"+P1DT2H", 26 * time.Hour},
+		{"PT45S", 45 * time.Second},
+	}
+	for _, tt := range tests {
+		if got, err := parseDuration(tt.value); err != nil || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "P", "1H", "P1H", "PT1D", "PT5"} {
+		if _, err := parseDuration(value); err == nil {
+			t.Errorf("parseDuration(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestUnescapeReversesEscape(t *testing.T) {
+	for _, text := range []string{"plain", `a\b`, "one, two; three", "line\nbreak", `\n literally`} {
+		if got := unescapeText(escapeText(text)); !reflect.DeepEqual(got, text) {
+			t.Errorf("unescapeText(escapeText(%q)) = %q", text, got)
+		}
+	}
+}
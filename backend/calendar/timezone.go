@@ -0,0 +1,104 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// transition is a change of a timezone's UTC offset
+type transition struct {
+	at         time.Time
+	offsetFrom int
+	offsetTo   int
+	name       string
+	dst        bool
+}
+
+// transitions returns the offset changes of loc between from and to. Go does
+// not expose its zone rules, so offsets are sampled daily and each change is
+// narrowed down to the second.
+func transitions(loc *time.Location, from, to time.Time) []transition {
+	found := []transition{}
+	previous := from
+	_, offset := previous.In(loc).Zone()
+	for previous.Before(to) {
+		next := previous.Add(24 * time.Hour)
+		if _, nextOffset := next.In(loc).Zone(); nextOffset != offset {
+			before, after := previous, next
+			for after.Sub(before) > time.Second {
+				middle := before.Add(after.Sub(before) / 2)
+				if _, middleOffset := middle.In(loc).Zone(); middleOffset == offset {
+					before = middle
+				} else {
+					after = middle
+				}
+			}
+
+			local := after.In(loc)
+			name, newOffset := local.Zone()
+			found = append(found, transition{
+				at:         after,
+				offsetFrom: offset,
+				offsetTo:   newOffset,
+				name:       name,
+				dst:        local.IsDST(),
+			})
+			offset = newOffset
+		}
+		previous = next
+	}
+	return found
+}
+
+// writeTimezone writes a VTIMEZONE for loc that is valid from from to to: the
+// observance in effect at from followed by each offset change up to to
+func writeTimezone(w *writer, loc *time.Location, from, to time.Time) {
+	w.line("BEGIN", "VTIMEZONE")
+	w.line("TZID", loc.String())
+
+	start := from.In(loc)
+	name, offset := start.Zone()
+	writeObservance(w, transition{
+		at:         time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc),
+		offsetFrom: offset,
+		offsetTo:   offset,
+		name:       name,
+		dst:        start.IsDST(),
+	})
+	for _, change := range transitions(loc, from, to) {
+		writeObservance(w, change)
+	}
+
+	w.line("END", "VTIMEZONE")
+}
+
+// writeObservance writes a STANDARD or DAYLIGHT component starting at the
+// transition. Its DTSTART is the local time under the offset it replaces.
+func writeObservance(w *writer, change transition) {
+	component := "STANDARD"
+	if change.dst {
+		component = "DAYLIGHT"
+	}
+
+	w.line("BEGIN", component)
+	w.line("DTSTART", change.at.UTC().Add(time.Duration(change.offsetFrom)*time.Second).Format("20060102T150405"))
+	w.line("TZOFFSETFROM", formatOffset(change.offsetFrom))
+	w.line("TZOFFSETTO", formatOffset(change.offsetTo))
+	if change.name != "" {
+		w.line("TZNAME", escapeText(change.name))
+	}
+	w.line("END", component)
+}
+
+// formatOffset formats seconds east of UTC as an iCalendar UTC offset, e.g. "-0500"
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	offset := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		offset += fmt.Sprintf("%02d", seconds%60)
+	}
+	return offset
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// migrationFS returns a filesystem of migration files with the given contents
+func migrationFS(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, contents := range files {
+		fsys["migrations/"+name] = &fstest.MapFile{Data: []byte(contents)}
+	}
+	return fsys
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := migrationFS(map[string]string{
+		"0010_add_rates.up.sql":         "ALTER TABLE tutors ADD rate INT;",
+		"0010_add_rates.down.sql":       "ALTER TABLE tutors DROP rate;",
+		"0002_create_clients.up.sql":    "CREATE TABLE clients ();",
+		"0002_create_clients.down.sql":  "DROP TABLE clients;",
+		"0001_create_tutors.up.sql":     "CREATE TABLE tutors ();",
+		"0003_backfill_subjects.up.sql": "UPDATE tutors SET subjects = '{}';",
+	})
+
+	migrations, err := loadMigrations(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+
+	want := []struct {
+		version int
+		name    string
+		up      string
+		down    string
+	}{
+		{1, "create_tutors", "CREATE TABLE tutors ();", ""},
+		{2, "create_clients", "CREATE TABLE clients ();", "DROP TABLE clients;"},
+		{3, "backfill_subjects", "UPDATE tutors SET subjects = '{}';", ""},
+		{10, "add_rates", "ALTER TABLE tutors ADD rate INT;", "ALTER TABLE tutors DROP rate;"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d: %+v", len(migrations), len(want), migrations)
+	}
+	for i, migration := range migrations {
+		w := want[i]
+		if migration.Version != w.version || migration.Name != w.name || migration.Up != w.up || migration.Down != w.down {
+			t.Errorf("migration %d = %+v, want %+v", i, migration, w)
+		}
+		sum := sha256.Sum256([]byte(w.up))
+		if migration.Checksum != hex.EncodeToString(sum[:]) {
+			t.Errorf("migration %d checksum = %s, want the SHA-256 of its up file", i, migration.Checksum)
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "unexpected file name",
+			files: map[string]string{"0001_create_tutors.sql": "CREATE TABLE tutors ();"},
+			want:  `unexpected migration file name "0001_create_tutors.sql"`,
+		},
+		{
+			name: "version with two names",
+			files: map[string]string{
+				"0001_create_tutors.up.sql":  "CREATE TABLE tutors ();",
+				"0001_create_clients.up.sql": "CREATE TABLE clients ();",
+			},
+			want: "migration 1 has two names",
+		},
+		{
+			name: "version with two up files",
+			files: map[string]string{
+				"0001_create_tutors.up.sql": "CREATE TABLE tutors ();",
+				"1_create_tutors.up.sql":    "CREATE TABLE tutors (id INT);",
+			},
+			want: "migration 1 has two up files",
+		},
+		{
+			name:  "down file without an up file",
+			files: map[string]string{"0004_drop_rates.down.sql": "ALTER TABLE tutors ADD rate INT;"},
+			want:  "migration 0004_drop_rates has no up file",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := loadMigrations(migrationFS(tt.files), "migrations")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: loadMigrations error = %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %d has version %d, want versions numbered without gaps", i, migration.Version)
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %04d_%s has no down file", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestCheckApplied(t *testing.T) {
+	migrations, err := loadMigrations(migrationFS(map[string]string{
+		"0001_create_tutors.up.sql":  "CREATE TABLE tutors ();",
+		"0002_create_clients.up.sql": "CREATE TABLE clients ();",
+	}), "migrations")
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		applied map[int]appliedMigration
+		want    string
+	}{
+		{"nothing applied", map[int]appliedMigration{}, ""},
+		{"unchanged", map[int]appliedMigration{1: {name: "create_tutors", checksum: migrations[0].Checksum}}, ""},
+		{"unknown to this build", map[int]appliedMigration{3: {name: "add_rates", checksum: "abc"}}, ""},
+		{
+			name:    "modified after it was applied",
+			applied: map[int]appliedMigration{2: {name: "create_clients", checksum: migrations[0].Checksum}},
+			want:    "migration 0002_create_clients was modified after it was applied",
+		},
+	}
+
+	for _, tt := range tests {
+		err := checkApplied(migrations, tt.applied)
+		if tt.want == "" && err != nil {
+			t.Errorf("%s: checkApplied = %v, want no error", tt.name, err)
+		}
+		if tt.want != "" && (err == nil || err.Error() != tt.want) {
+			t.Errorf("%s: checkApplied = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
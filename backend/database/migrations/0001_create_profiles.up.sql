@@ -0,0 +1,40 @@
+-- Tutors and clients. Written with IF NOT EXISTS so databases created by the
+-- old ad-hoc migrations adopt this history without changes.
+CREATE TABLE IF NOT EXISTS tutors (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	email VARCHAR(255),
+	subjects TEXT[] NOT NULL,
+	pay DECIMAL(10,2) NOT NULL,
+	rating DECIMAL(3,2) DEFAULT 5.0,
+	bio TEXT NOT NULL,
+	language VARCHAR(255),
+	location VARCHAR(255),
+	availability TEXT,
+	experience VARCHAR(255),
+	education VARCHAR(255),
+	certification VARCHAR(255),
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS clients (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	email VARCHAR(255),
+	subjects TEXT[] NOT NULL,
+	budget DECIMAL(10,2) NOT NULL,
+	description TEXT,
+	language VARCHAR(255),
+	location VARCHAR(255),
+	availability TEXT,
+	education VARCHAR(255),
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Early tutors tables had no email column and a short availability column
+ALTER TABLE tutors ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+ALTER TABLE tutors ALTER COLUMN availability TYPE TEXT;
+ALTER TABLE clients ALTER COLUMN availability TYPE TEXT;
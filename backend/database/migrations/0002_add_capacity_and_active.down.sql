@@ -0,0 +1,2 @@
+ALTER TABLE tutors DROP COLUMN IF EXISTS max_clients;
+ALTER TABLE clients DROP COLUMN IF EXISTS active;
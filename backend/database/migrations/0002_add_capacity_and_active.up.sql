@@ -0,0 +1,5 @@
+-- Track whether clients are still looking for a tutor
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS active BOOLEAN DEFAULT TRUE;
+
+-- Limit how many clients a tutor can be paired with at once
+ALTER TABLE tutors ADD COLUMN IF NOT EXISTS max_clients INTEGER NOT NULL DEFAULT 3;
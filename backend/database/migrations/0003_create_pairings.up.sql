@@ -0,0 +1,9 @@
+-- Clients paired with a tutor
+CREATE TABLE IF NOT EXISTS pairings (
+	id SERIAL PRIMARY KEY,
+	tutor_id INTEGER NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+	client_id INTEGER NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	status VARCHAR(20) NOT NULL DEFAULT 'active',
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	ended_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE clients DROP COLUMN IF EXISTS timezone;
+ALTER TABLE tutors DROP COLUMN IF EXISTS timezone;
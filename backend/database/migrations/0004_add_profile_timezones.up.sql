@@ -0,0 +1,3 @@
+-- The IANA timezone each profile's availability is expressed in
+ALTER TABLE tutors ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
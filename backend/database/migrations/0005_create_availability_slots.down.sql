@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS minute_of_week(TIMESTAMPTZ, TEXT);
+DROP TABLE IF EXISTS availability_slots;
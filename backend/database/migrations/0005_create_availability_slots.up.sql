@@ -0,0 +1,32 @@
+-- Slots used to be stored as day_of_week/start_minute with no timezone. They are
+-- derived from the availability columns, so drop the old table and let the
+-- startup backfill rebuild it in minutes-of-week.
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'availability_slots' AND column_name = 'day_of_week'
+	) THEN
+		DROP TABLE availability_slots;
+	END IF;
+END $$;
+
+-- Parsed weekly availability as minutes-of-week (0 = Sunday 00:00) in the owner's timezone
+CREATE TABLE IF NOT EXISTS availability_slots (
+	id SERIAL PRIMARY KEY,
+	owner_type VARCHAR(10) NOT NULL,
+	owner_id INTEGER NOT NULL,
+	timezone VARCHAR(64) NOT NULL,
+	start_minute_of_week INTEGER NOT NULL CHECK (start_minute_of_week BETWEEN 0 AND 10079),
+	end_minute_of_week INTEGER NOT NULL CHECK (end_minute_of_week > start_minute_of_week AND end_minute_of_week <= 10080),
+	UNIQUE (owner_type, owner_id, start_minute_of_week)
+);
+
+CREATE INDEX IF NOT EXISTS idx_availability_slots_owner ON availability_slots (owner_type, owner_id);
+
+-- minute_of_week converts an instant into minutes-of-week in a timezone
+CREATE OR REPLACE FUNCTION minute_of_week(ts TIMESTAMPTZ, tz TEXT) RETURNS INTEGER AS $$
+	SELECT (EXTRACT(DOW FROM ts AT TIME ZONE tz) * 1440
+		+ EXTRACT(HOUR FROM ts AT TIME ZONE tz) * 60
+		+ EXTRACT(MINUTE FROM ts AT TIME ZONE tz))::INTEGER
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,2 @@
+ALTER TABLE clients DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE tutors DROP COLUMN IF EXISTS search_vector;
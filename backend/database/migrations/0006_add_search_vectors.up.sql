@@ -0,0 +1,14 @@
+-- Full-text search columns maintained by Postgres from the profile text
+ALTER TABLE tutors ADD COLUMN IF NOT EXISTS search_vector tsvector
+GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', COALESCE(bio, '')), 'A') ||
+	setweight(to_tsvector('english', COALESCE(experience, '')), 'B') ||
+	setweight(to_tsvector('english', COALESCE(certification, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tutors_search_vector ON tutors USING GIN (search_vector);
+
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS search_vector tsvector
+GENERATED ALWAYS AS (to_tsvector('english', COALESCE(description, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_clients_search_vector ON clients USING GIN (search_vector);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS subject_aliases;
+DROP TABLE IF EXISTS subjects;
@@ -0,0 +1,13 @@
+-- Subject taxonomy: canonical subjects form a tree, aliases map other spellings onto them
+CREATE TABLE IF NOT EXISTS subjects (
+	id SERIAL PRIMARY KEY,
+	slug VARCHAR(255) NOT NULL UNIQUE,
+	name VARCHAR(255) NOT NULL UNIQUE,
+	parent_id INTEGER REFERENCES subjects(id) ON DELETE SET NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS subject_aliases (
+	alias VARCHAR(255) PRIMARY KEY,
+	subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE
+);
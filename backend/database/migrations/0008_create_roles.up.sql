@@ -0,0 +1,54 @@
+-- Roles and permissions; users are keyed by their (lower-cased) sign-in email
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	email VARCHAR(255) NOT NULL UNIQUE,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL UNIQUE,
+	description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL UNIQUE,
+	description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+	role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+	PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+	granted_by VARCHAR(255) NOT NULL DEFAULT '',
+	granted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, role_id)
+);
+
+INSERT INTO roles (name, description) VALUES
+	('admin', 'Full access, including managing roles'),
+	('coordinator', 'Manages profiles and pairings'),
+	('tutor', 'Tutor with a profile'),
+	('client', 'Client with a profile')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+	('stats:read', 'View admin statistics'),
+	('tutors:write', 'Edit and delete any tutor'),
+	('clients:write', 'Edit and delete any client'),
+	('assignments:write', 'Plan and commit tutor assignments'),
+	('roles:write', 'Grant and revoke roles')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+   OR (r.name = 'coordinator' AND p.name IN ('stats:read', 'tutors:write', 'clients:write', 'assignments:write'))
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,25 @@
+-- Audit trail of mutating admin actions
+CREATE TABLE IF NOT EXISTS audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	actor VARCHAR(255) NOT NULL,
+	action VARCHAR(64) NOT NULL,
+	entity_type VARCHAR(64) NOT NULL,
+	entity_id VARCHAR(255) NOT NULL DEFAULT '',
+	before JSONB,
+	after JSONB,
+	diff JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (lower(actor));
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+INSERT INTO permissions (name, description) VALUES
+	('audit:read', 'View the admin audit log')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = 'audit:read'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,18 @@
+-- Tutoring sessions booked between a client and a tutor
+CREATE TABLE IF NOT EXISTS sessions (
+	id SERIAL PRIMARY KEY,
+	tutor_id INTEGER NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+	client_id INTEGER NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	subject VARCHAR(255) NOT NULL,
+	starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'requested',
+	cancelled_by VARCHAR(255),
+	cancel_reason TEXT,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_tutor ON sessions (tutor_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_client ON sessions (client_id, starts_at);
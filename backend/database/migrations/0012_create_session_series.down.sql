@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sessions_series_occurrence;
+ALTER TABLE sessions DROP COLUMN IF EXISTS occurrence_start;
+ALTER TABLE sessions DROP COLUMN IF EXISTS series_id;
+DROP TABLE IF EXISTS session_series;
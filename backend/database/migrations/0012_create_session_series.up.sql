@@ -0,0 +1,20 @@
+-- Weekly recurring bookings; their occurrences are stored as sessions
+CREATE TABLE IF NOT EXISTS session_series (
+	id SERIAL PRIMARY KEY,
+	tutor_id INTEGER NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+	client_id INTEGER NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	subject VARCHAR(255) NOT NULL,
+	rrule TEXT NOT NULL,
+	timezone VARCHAR(64) NOT NULL,
+	starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	CHECK (ends_at > starts_at)
+);
+
+-- Occurrences remember their series and the start the rule gave them
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS series_id INTEGER REFERENCES session_series(id) ON DELETE CASCADE;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS occurrence_start TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_series_occurrence ON sessions (series_id, occurrence_start);
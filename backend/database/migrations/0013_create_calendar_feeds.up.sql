@@ -0,0 +1,6 @@
+-- Secret-token iCalendar feeds; only a SHA-256 hash of each token is stored
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+	email VARCHAR(255) PRIMARY KEY,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
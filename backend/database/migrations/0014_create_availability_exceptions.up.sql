@@ -0,0 +1,13 @@
+-- Absolute spans when a profile is busy despite its weekly availability, e.g.
+-- events imported from the owner's own calendar
+CREATE TABLE IF NOT EXISTS availability_exceptions (
+	id SERIAL PRIMARY KEY,
+	owner_type VARCHAR(10) NOT NULL,
+	owner_id INTEGER NOT NULL,
+	starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	ends_at TIMESTAMP WITH TIME ZONE NOT NULL CHECK (ends_at > starts_at),
+	source VARCHAR(20) NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_availability_exceptions_owner ON availability_exceptions (owner_type, owner_id, starts_at);
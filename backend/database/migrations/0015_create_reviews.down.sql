@@ -0,0 +1,3 @@
+ALTER TABLE tutors ALTER COLUMN rating SET DEFAULT 5.0;
+ALTER TABLE tutors DROP COLUMN IF EXISTS review_count;
+DROP TABLE IF EXISTS reviews;
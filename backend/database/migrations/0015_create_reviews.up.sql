@@ -0,0 +1,18 @@
+-- Client reviews of completed sessions, at most one per session
+CREATE TABLE IF NOT EXISTS reviews (
+	id SERIAL PRIMARY KEY,
+	session_id INTEGER NOT NULL UNIQUE REFERENCES sessions(id) ON DELETE CASCADE,
+	tutor_id INTEGER NOT NULL REFERENCES tutors(id) ON DELETE CASCADE,
+	client_id INTEGER NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+	comment TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_tutor ON reviews (tutor_id, created_at DESC, id DESC);
+
+-- Ratings are now derived from reviews; self-declared ratings are dropped and
+-- tutors without reviews are unrated
+ALTER TABLE tutors ADD COLUMN IF NOT EXISTS review_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tutors ALTER COLUMN rating SET DEFAULT 0;
+UPDATE tutors SET rating = 0 WHERE review_count = 0;
@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'reviews:write';
+DROP INDEX IF EXISTS idx_reviews_status;
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE reviews DROP COLUMN IF EXISTS moderated_by;
+ALTER TABLE reviews DROP COLUMN IF EXISTS redacted;
+ALTER TABLE reviews DROP COLUMN IF EXISTS hold_reason;
+ALTER TABLE reviews DROP COLUMN IF EXISTS status;
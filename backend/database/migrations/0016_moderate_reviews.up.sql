@@ -0,0 +1,18 @@
+-- Review moderation: reviews tripping the keyword filter wait as pending, and
+-- only published reviews count toward ratings
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published';
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS hold_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS redacted BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_by VARCHAR(255);
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_status ON reviews (status, created_at DESC, id DESC);
+
+INSERT INTO permissions (name, description) VALUES
+	('reviews:write', 'Approve, reject and redact reviews')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name IN ('admin', 'coordinator') AND p.name = 'reviews:write'
+ON CONFLICT DO NOTHING;
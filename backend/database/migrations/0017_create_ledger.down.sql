@@ -0,0 +1,8 @@
+DELETE FROM permissions WHERE name = 'billing:write';
+DROP TRIGGER IF EXISTS journal_lines_balanced ON journal_lines;
+DROP FUNCTION IF EXISTS check_journal_entry_balance();
+DROP TABLE IF EXISTS journal_lines;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS invoice_lines;
+DROP TABLE IF EXISTS invoices;
+ALTER TABLE sessions DROP COLUMN IF EXISTS hourly_rate;
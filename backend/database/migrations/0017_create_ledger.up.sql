@@ -0,0 +1,82 @@
+-- Double-entry ledger. Amounts are integer minor units. Ledger rows keep plain
+-- client, tutor and session IDs rather than foreign keys, so purging a
+-- profile never rewrites financial history.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS hourly_rate BIGINT NOT NULL DEFAULT 0;
+
+-- Sessions booked before rates were recorded are invoiced at the tutor's current pay
+UPDATE sessions s SET hourly_rate = ROUND(t.pay * 100)
+FROM tutors t
+WHERE t.id = s.tutor_id AND s.hourly_rate = 0;
+
+CREATE TABLE IF NOT EXISTS invoices (
+	id SERIAL PRIMARY KEY,
+	client_id INTEGER NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'open',
+	currency CHAR(3) NOT NULL,
+	total BIGINT NOT NULL CHECK (total >= 0),
+	paid BIGINT NOT NULL DEFAULT 0,
+	issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	paid_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_client ON invoices (client_id, id DESC);
+
+-- Each session is invoiced at most once
+CREATE TABLE IF NOT EXISTS invoice_lines (
+	id SERIAL PRIMARY KEY,
+	invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+	session_id INTEGER NOT NULL UNIQUE,
+	tutor_id INTEGER NOT NULL,
+	description TEXT NOT NULL,
+	minutes INTEGER NOT NULL,
+	hourly_rate BIGINT NOT NULL,
+	amount BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_lines_invoice ON invoice_lines (invoice_id);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+	id SERIAL PRIMARY KEY,
+	kind VARCHAR(20) NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	client_id INTEGER,
+	invoice_id INTEGER REFERENCES invoices(id),
+	created_by VARCHAR(255),
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Positive amounts are debits, negative amounts credits
+CREATE TABLE IF NOT EXISTS journal_lines (
+	id SERIAL PRIMARY KEY,
+	entry_id INTEGER NOT NULL REFERENCES journal_entries(id),
+	account VARCHAR(64) NOT NULL,
+	amount BIGINT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_lines_account ON journal_lines (account);
+CREATE INDEX IF NOT EXISTS idx_journal_lines_entry ON journal_lines (entry_id);
+
+-- Every entry's lines must add up to zero when its transaction commits
+CREATE OR REPLACE FUNCTION check_journal_entry_balance() RETURNS TRIGGER AS $$
+BEGIN
+	IF (SELECT SUM(amount) FROM journal_lines WHERE entry_id = NEW.entry_id) <> 0 THEN
+		RAISE EXCEPTION 'journal entry % does not balance', NEW.entry_id;
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS journal_lines_balanced ON journal_lines;
+CREATE CONSTRAINT TRIGGER journal_lines_balanced
+	AFTER INSERT OR UPDATE ON journal_lines
+	DEFERRABLE INITIALLY DEFERRED
+	FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balance();
+
+INSERT INTO permissions (name, description) VALUES
+	('billing:write', 'Generate invoices and record payments, credits and refunds')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = 'billing:write'
+ON CONFLICT DO NOTHING;
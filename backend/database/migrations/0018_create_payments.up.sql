@@ -0,0 +1,24 @@
+-- Checkouts started with the payment provider, and the webhook events
+-- already applied, so redelivered events are skipped
+CREATE TABLE IF NOT EXISTS payment_intents (
+	id SERIAL PRIMARY KEY,
+	provider_id VARCHAR(255) NOT NULL UNIQUE,
+	invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+	client_id INTEGER NOT NULL,
+	amount BIGINT NOT NULL CHECK (amount > 0),
+	refunded BIGINT NOT NULL DEFAULT 0,
+	currency CHAR(3) NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	checkout_url TEXT,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_intents_invoice ON payment_intents (invoice_id);
+
+CREATE TABLE IF NOT EXISTS payment_events (
+	event_id VARCHAR(255) PRIMARY KEY,
+	outcome VARCHAR(20) NOT NULL,
+	provider_id VARCHAR(255) NOT NULL,
+	received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
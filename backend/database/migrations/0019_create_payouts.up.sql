@@ -0,0 +1,32 @@
+-- Monthly payout statements of tutor earnings, net of the platform's
+-- commission, and the paid sessions each covers. A session is paid out once.
+CREATE TABLE IF NOT EXISTS payout_statements (
+	id SERIAL PRIMARY KEY,
+	tutor_id INTEGER NOT NULL,
+	period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+	period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+	currency CHAR(3) NOT NULL,
+	gross BIGINT NOT NULL,
+	commission_rate BIGINT NOT NULL,
+	commission BIGINT NOT NULL,
+	net BIGINT NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	paid_by VARCHAR(255),
+	reference TEXT,
+	paid_at TIMESTAMP WITH TIME ZONE,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_statements_tutor ON payout_statements (tutor_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS payout_lines (
+	id SERIAL PRIMARY KEY,
+	statement_id INTEGER NOT NULL REFERENCES payout_statements(id) ON DELETE CASCADE,
+	session_id INTEGER NOT NULL UNIQUE,
+	invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+	description TEXT NOT NULL,
+	minutes INTEGER NOT NULL,
+	amount BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_payout_lines_statement ON payout_lines (statement_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_pairings_open_client;
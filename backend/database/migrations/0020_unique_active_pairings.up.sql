@@ -0,0 +1,2 @@
+-- A client can have only one pairing that has not ended
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pairings_open_client ON pairings (client_id) WHERE ended_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payout_lines_session;
+
+ALTER TABLE payout_lines ADD CONSTRAINT payout_lines_session_id_key UNIQUE (session_id);
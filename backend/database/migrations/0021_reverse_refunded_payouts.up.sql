@@ -0,0 +1,6 @@
+-- A session paid out on one statement is reversed on a later one when its
+-- invoice is refunded, and paid out again once it is paid, so payout lines
+-- are no longer unique per session.
+ALTER TABLE payout_lines DROP CONSTRAINT IF EXISTS payout_lines_session_id_key;
+
+CREATE INDEX IF NOT EXISTS idx_payout_lines_session ON payout_lines (session_id);
@@ -0,0 +1 @@
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_paid_check;
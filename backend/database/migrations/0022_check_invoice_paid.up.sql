@@ -0,0 +1,3 @@
+-- Settlement locks the invoice row and rejects refunds beyond what was paid,
+-- so the paid amount can never go negative.
+ALTER TABLE invoices ADD CONSTRAINT invoices_paid_check CHECK (paid >= 0);
@@ -0,0 +1 @@
+ALTER TABLE payment_intents DROP COLUMN IF EXISTS unapplied;
@@ -0,0 +1,3 @@
+-- Payments that arrive after the invoice no longer needs them are held as
+-- client credit; unapplied records how much of the payment that was.
+ALTER TABLE payment_intents ADD COLUMN IF NOT EXISTS unapplied BIGINT NOT NULL DEFAULT 0;
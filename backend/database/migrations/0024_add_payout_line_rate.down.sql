@@ -0,0 +1 @@
+ALTER TABLE payout_lines DROP COLUMN IF EXISTS commission_rate;
@@ -0,0 +1,8 @@
+-- Reversals give back commission at the rate the reversed session was paid
+-- out at, so each line records the rate its commission was taken at.
+ALTER TABLE payout_lines ADD COLUMN IF NOT EXISTS commission_rate BIGINT NOT NULL DEFAULT 0;
+
+UPDATE payout_lines l
+SET commission_rate = s.commission_rate
+FROM payout_statements s
+WHERE s.id = l.statement_id;
@@ -0,0 +1,2 @@
+-- Pairings ended with their deleted profiles are not reopened
+SELECT 1;
@@ -0,0 +1,9 @@
+-- Deleting a tutor or client now ends their open pairings; end the ones
+-- left open by profiles deleted before then.
+UPDATE pairings p
+SET status = 'ended', ended_at = CURRENT_TIMESTAMP
+WHERE p.ended_at IS NULL
+  AND (
+	EXISTS (SELECT 1 FROM tutors t WHERE t.id = p.tutor_id AND t.deleted_at IS NOT NULL)
+	OR EXISTS (SELECT 1 FROM clients c WHERE c.id = p.client_id AND c.deleted_at IS NOT NULL)
+  );
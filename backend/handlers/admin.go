@@ -10,7 +10,7 @@ import (
 )
 
 // UpdateTutor handles PUT /api/admin/tutors/:id
-func UpdateTutor(c *gin.Context) {
+func (h *Handler) UpdateTutor(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
@@ -34,7 +34,37 @@ func UpdateTutor(c *gin.Context) {
 
 	updatedTutor.ID = id
 
-	if err := models.UpdateTutor(&updatedTutor); err != nil {
+	before, err := h.tutors.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	availability, err := normalizeAvailability(updatedTutor.Availability, updatedTutor.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid availability",
+			"status":  "error",
+		})
+		return
+	}
+	updatedTutor.Availability = availability
+
+	updatedTutor.CreatedAt = before.CreatedAt
+	if err := h.tutors.Update(&updatedTutor, h.auditChange(c, models.AuditActionUpdate, "tutor", auditID(id), before)); err != nil {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Tutor not found",
@@ -59,7 +89,7 @@ func UpdateTutor(c *gin.Context) {
 }
 
 // DeleteTutor handles DELETE /api/admin/tutors/:id
-func DeleteTutor(c *gin.Context) {
+func (h *Handler) DeleteTutor(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
@@ -71,7 +101,25 @@ func DeleteTutor(c *gin.Context) {
 		return
 	}
 
-	if err := models.DeleteTutor(id); err != nil {
+	before, err := h.tutors.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	if err := h.tutors.Delete(id, h.auditChange(c, models.AuditActionDelete, "tutor", auditID(id), before)); err != nil {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Tutor not found",
@@ -95,7 +143,7 @@ func DeleteTutor(c *gin.Context) {
 }
 
 // UpdateClient handles PUT /api/admin/clients/:id
-func UpdateClient(c *gin.Context) {
+func (h *Handler) UpdateClient(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
@@ -119,7 +167,37 @@ func UpdateClient(c *gin.Context) {
 
 	updatedClient.ID = id
 
-	if err := models.UpdateClient(&updatedClient); err != nil {
+	before, err := h.clients.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Client not found",
+				"message": "No client found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve client",
+			"status":  "error",
+		})
+		return
+	}
+
+	availability, err := normalizeAvailability(updatedClient.Availability, updatedClient.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid availability",
+			"status":  "error",
+		})
+		return
+	}
+	updatedClient.Availability = availability
+
+	updatedClient.CreatedAt = before.CreatedAt
+	if err := h.clients.Update(&updatedClient, h.auditChange(c, models.AuditActionUpdate, "client", auditID(id), before)); err != nil {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Client not found",
@@ -144,7 +222,7 @@ func UpdateClient(c *gin.Context) {
 }
 
 // DeleteClient handles DELETE /api/admin/clients/:id
-func DeleteClient(c *gin.Context) {
+func (h *Handler) DeleteClient(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
@@ -156,7 +234,25 @@ func DeleteClient(c *gin.Context) {
 		return
 	}
 
-	if err := models.DeleteClient(id); err != nil {
+	before, err := h.clients.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Client not found",
+				"message": "No client found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve client",
+			"status":  "error",
+		})
+		return
+	}
+
+	if err := h.clients.Delete(id, h.auditChange(c, models.AuditActionDelete, "client", auditID(id), before)); err != nil {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Client not found",
@@ -180,8 +276,8 @@ func DeleteClient(c *gin.Context) {
 }
 
 // GetAdminStats handles GET /api/admin/stats
-func GetAdminStats(c *gin.Context) {
-	tutors, err := models.GetTutors()
+func (h *Handler) GetAdminStats(c *gin.Context) {
+	tutors, err := h.tutors.List()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
@@ -191,7 +287,7 @@ func GetAdminStats(c *gin.Context) {
 		return
 	}
 
-	clients, err := models.GetClients()
+	clients, err := h.clients.List()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
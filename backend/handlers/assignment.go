@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"tutor-backend/matching"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssignmentPlanRequest configures a batch assignment run
+type AssignmentPlanRequest struct {
+	MinScore *float64 `json:"min_score"`
+}
+
+// AssignmentCommitRequest is a reviewed plan an admin wants to apply
+type AssignmentCommitRequest struct {
+	Assignments []struct {
+		ClientID int `json:"client_id" binding:"required"`
+		TutorID  int `json:"tutor_id" binding:"required"`
+	} `json:"assignments" binding:"required"`
+}
+
+// assignmentPool holds the clients and tutors still open for pairing
+type assignmentPool struct {
+	clients  map[int]models.Client
+	tutors   map[int]models.Tutor
+	capacity map[int]int
+}
+
+// loadAssignmentPool returns active, unpaired clients and tutors with free seats
+func (h *Handler) loadAssignmentPool() (*assignmentPool, error) {
+	clients, err := h.clients.List()
+	if err != nil {
+		return nil, err
+	}
+
+	tutors, err := h.tutors.List()
+	if err != nil {
+		return nil, err
+	}
+
+	paired, err := models.GetPairedClientIDs(h.pairings)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := models.GetActivePairingCounts(h.pairings)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &assignmentPool{
+		clients:  make(map[int]models.Client),
+		tutors:   make(map[int]models.Tutor),
+		capacity: make(map[int]int),
+	}
+
+	for _, client := range clients {
+		if client.IsActive() && !paired[client.ID] {
+			pool.clients[client.ID] = client
+		}
+	}
+
+	for _, tutor := range tutors {
+		open := tutor.Capacity() - counts[tutor.ID]
+		if open > 0 {
+			pool.tutors[tutor.ID] = tutor
+			pool.capacity[tutor.ID] = open
+		}
+	}
+
+	return pool, nil
+}
+
+// PlanAssignments handles POST /api/admin/assignments/plan
+func (h *Handler) PlanAssignments(c *gin.Context) {
+	var request AssignmentPlanRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   err.Error(),
+				"message": "Invalid assignment options",
+				"status":  "error",
+			})
+			return
+		}
+	}
+
+	minScore := matching.DefaultMinScore
+	if request.MinScore != nil {
+		minScore = *request.MinScore
+	}
+
+	pool, err := h.loadAssignmentPool()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to load clients and tutors",
+			"status":  "error",
+		})
+		return
+	}
+
+	clients := make([]models.Client, 0, len(pool.clients))
+	for _, client := range pool.clients {
+		clients = append(clients, client)
+	}
+	tutors := make([]models.Tutor, 0, len(pool.tutors))
+	for _, tutor := range pool.tutors {
+		tutors = append(tutors, tutor)
+	}
+
+	// Keep plans reproducible between runs over the same data
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+	sort.Slice(tutors, func(i, j int) bool { return tutors[i].ID < tutors[j].ID })
+
+	matcher, err := h.newMatcher(tutors)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to prepare matching",
+			"status":  "error",
+		})
+		return
+	}
+
+	plan := matcher.Assign(clients, tutors, pool.capacity, minScore)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    plan,
+		"message": "Assignment plan computed successfully",
+		"status":  "success",
+	})
+}
+
+// CommitAssignments handles POST /api/admin/assignments/commit
+// The plan is re-validated against current pairings, in the same transaction
+// that saves it, so concurrent commits cannot over-assign.
+func (h *Handler) CommitAssignments(c *gin.Context) {
+	var request AssignmentCommitRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid assignment plan",
+			"status":  "error",
+		})
+		return
+	}
+
+	pairings := make([]models.Pairing, 0, len(request.Assignments))
+	for _, assignment := range request.Assignments {
+		pairings = append(pairings, models.Pairing{
+			TutorID:  assignment.TutorID,
+			ClientID: assignment.ClientID,
+		})
+	}
+
+	audit := h.changeAudit(c, func(any) []models.AuditChange {
+		changes := []models.AuditChange{}
+		for _, pairing := range pairings {
+			changes = append(changes, models.AuditChange{Action: models.AuditActionCreate, EntityType: "pairing", EntityID: auditID(pairing.ID), After: pairing})
+		}
+		return changes
+	})
+	if err := h.pairings.Create(pairings, audit); err != nil {
+		var conflict *models.PairingConflictError
+		if errors.As(err, &conflict) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   conflict.Error(),
+				"message": "Assignment plan is out of date",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to save pairings",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    pairings,
+		"message": "Assignments committed successfully",
+		"status":  "success",
+	})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"tutor-backend/middleware"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// changeAudit returns the audit of a change by the current admin, which the
+// repository saves together with the change so that neither is kept without
+// the other. describe turns what the repository saved into entries.
+func (h *Handler) changeAudit(c *gin.Context, describe func(saved any) []models.AuditChange) *models.ChangeAudit {
+	return &models.ChangeAudit{Actor: c.GetString(middleware.AdminEmailKey), Changes: describe, Log: h.audit}
+}
+
+// auditChange returns the audit of a change to one entity, whose new state is
+// what the repository saved, or nothing when it was deleted
+func (h *Handler) auditChange(c *gin.Context, action, entityType, entityID string, before any) *models.ChangeAudit {
+	return h.changeAudit(c, func(saved any) []models.AuditChange {
+		return []models.AuditChange{{Action: action, EntityType: entityType, EntityID: entityID, Before: before, After: saved}}
+	})
+}
+
+// parseTimeQuery accepts an RFC 3339 timestamp or a YYYY-MM-DD date. A date
+// used as the end of a range covers the whole day.
+func parseTimeQuery(value string, endOfRange bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("dates must be YYYY-MM-DD or RFC 3339 timestamps")
+	}
+	if endOfRange {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+	return &parsed, nil
+}
+
+// GetAuditLog handles GET /api/admin/audit
+// Filters: actor, entity_type, entity_id, from, to (inclusive dates), cursor, limit.
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	filter := models.AuditFilter{
+		Actor:      c.Query("actor"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		Cursor:     c.Query("cursor"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c.Query("from"), false); err == nil {
+		filter.To, err = parseTimeQuery(c.Query("to"), true)
+	}
+	if err == nil {
+		filter.Limit, err = parseLimit(c.Query("limit"))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid audit query",
+			"status":  "error",
+		})
+		return
+	}
+
+	page, err := h.audit.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve audit log",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    page.Entries,
+		"meta":    gin.H{"next_cursor": page.NextCursor},
+		"message": "Audit log retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// auditID formats a numeric entity ID for the audit log
+func auditID(id int) string {
+	return strconv.Itoa(id)
+}
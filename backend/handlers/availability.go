@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// parseAvailabilityQuery reads the "day", "time" and optional "tz" query
+// parameters (e.g. day=Tue&time=3:00 PM&tz=America/Chicago) and returns that
+// moment in the current week, or in the week of the optional "week_of" date
+func parseAvailabilityQuery(c *gin.Context) (time.Time, error) {
+	dayParam := c.Query("day")
+	timeParam := c.Query("time")
+	if dayParam == "" || timeParam == "" {
+		return time.Time{}, fmt.Errorf("day and time query parameters are required")
+	}
+
+	day, err := models.ParseDay(dayParam)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	minute, err := models.ParseClock(timeParam)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := models.LoadTimezone(c.Query("tz"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	reference, err := parseWeekOf(c.Query("week_of"), loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return models.WeekInstant(loc, reference, day, minute), nil
+}
+
+// parseWeekOf parses an optional YYYY-MM-DD date, defaulting to now
+func parseWeekOf(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+
+	reference, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("week_of must be a date in YYYY-MM-DD format")
+	}
+	return reference, nil
+}
+
+// normalizeAvailability validates a submitted availability and timezone and
+// returns the canonical stored form of the availability
+func normalizeAvailability(raw, timezone string) (string, error) {
+	availability, err := models.ParseAvailability(raw, timezone)
+	if err != nil {
+		return "", err
+	}
+	return availability.String(), nil
+}
+
+// availabilityView is an availability rendered for API responses
+type availabilityView struct {
+	models.Availability
+	SlotIDs []string `json:"slot_ids"`
+}
+
+// renderAvailability converts a profile's availability into the caller's
+// timezone, given by the "tz" query parameter
+func renderAvailability(c *gin.Context, raw, timezone string) (*availabilityView, error) {
+	availability, err := models.ParseAvailability(raw, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if tz := c.Query("tz"); tz != "" {
+		loc, err := models.LoadTimezone(tz)
+		if err != nil {
+			return nil, err
+		}
+
+		reference, err := parseWeekOf(c.Query("week_of"), loc)
+		if err != nil {
+			return nil, err
+		}
+		availability = availability.In(loc, reference)
+	} else {
+		availability.Timezone = availability.Location().String()
+	}
+
+	return &availabilityView{Availability: availability, SlotIDs: availability.SlotIDs()}, nil
+}
+
+// GetAvailableTutors handles GET /api/tutors/available?day=Tue&time=3:00 PM
+func (h *Handler) GetAvailableTutors(c *gin.Context) {
+	at, err := parseAvailabilityQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid availability query",
+			"status":  "error",
+		})
+		return
+	}
+
+	tutors, err := h.tutors.AvailableAt(at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutors",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    tutors,
+		"message": "Available tutors retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetAvailableClients handles GET /api/clients/available?day=Tue&time=3:00 PM
+func (h *Handler) GetAvailableClients(c *gin.Context) {
+	at, err := parseAvailabilityQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid availability query",
+			"status":  "error",
+		})
+		return
+	}
+
+	clients, err := h.clients.AvailableAt(at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve clients",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    clients,
+		"message": "Available clients retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetTutorAvailability handles GET /api/tutors/:id/availability?tz=America/New_York
+// Only the tutor themself and staff with assignments:write may see it.
+func (h *Handler) GetTutorAvailability(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tutor ID",
+			"message": "Tutor ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	tutor, err := h.tutors.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	if !h.ownerOrPermitted(c, tutor.Email, models.PermissionAssignmentsWrite) {
+		return
+	}
+
+	view, err := renderAvailability(c, tutor.Availability, tutor.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to render availability",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    view,
+		"message": "Availability retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetClientAvailability handles GET /api/clients/:id/availability?tz=America/New_York
+// Only the client themself and staff with assignments:write may see it.
+func (h *Handler) GetClientAvailability(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid client ID",
+			"message": "Client ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	client, err := h.clients.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Client not found",
+				"message": "No client found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve client",
+			"status":  "error",
+		})
+		return
+	}
+
+	if !h.ownerOrPermitted(c, client.Email, models.PermissionAssignmentsWrite) {
+		return
+	}
+
+	view, err := renderAvailability(c, client.Availability, client.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to render availability",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    view,
+		"message": "Availability retrieved successfully",
+		"status":  "success",
+	})
+}
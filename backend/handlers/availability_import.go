@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"tutor-backend/calendar"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxCalendarUpload caps the size of uploaded .ics files
+const maxCalendarUpload = 2 << 20
+
+// myTutorProfile returns the signed-in user's tutor profile. It responds
+// itself when it returns false.
+func (h *Handler) myTutorProfile(c *gin.Context) (*models.Tutor, bool) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return nil, false
+	}
+
+	tutor, err := h.tutors.GetByEmail(email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor profile for this account",
+				"status":  "error",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return nil, false
+	}
+	return tutor, true
+}
+
+// readCalendarUpload returns the uploaded calendar, sent either as the "file"
+// field of a multipart form or as the raw request body
+func readCalendarUpload(c *gin.Context) (io.ReadCloser, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCalendarUpload)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		return header.Open()
+	}
+	return c.Request.Body, nil
+}
+
+// ImportMyAvailability handles POST /api/me/tutor/availability/import
+// Reads the busy events of an uploaded .ics file, expanding recurring ones
+// up to models.ImportHorizon ahead, and stores them as availability
+// exceptions in place of those from the previous import. Free and cancelled
+// events are ignored; times without a timezone are read in the tutor's.
+func (h *Handler) ImportMyAvailability(c *gin.Context) {
+	tutor, ok := h.myTutorProfile(c)
+	if !ok {
+		return
+	}
+
+	loc, err := models.LoadTimezone(tutor.Timezone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to load the tutor's timezone",
+			"status":  "error",
+		})
+		return
+	}
+
+	file, err := readCalendarUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid calendar upload",
+			"status":  "error",
+		})
+		return
+	}
+	defer file.Close()
+
+	events, err := calendar.Parse(file, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid calendar file",
+			"status":  "error",
+		})
+		return
+	}
+
+	now := time.Now()
+	blocks, skipped := models.BusyBlocks(events, now, now.Add(models.ImportHorizon))
+	exceptions, err := h.tutors.ReplaceAvailabilityExceptions(tutor.ID, models.ExceptionSourceICS, blocks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to save busy times",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"events":     len(events),
+			"exceptions": exceptions,
+			"skipped":    skipped,
+		},
+		"message": "Busy times imported successfully",
+		"status":  "success",
+	})
+}
+
+// ClearMyAvailabilityImport handles DELETE /api/me/tutor/availability/import
+func (h *Handler) ClearMyAvailabilityImport(c *gin.Context) {
+	tutor, ok := h.myTutorProfile(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.tutors.ReplaceAvailabilityExceptions(tutor.ID, models.ExceptionSourceICS, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to clear busy times",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Imported busy times cleared successfully",
+		"status":  "success",
+	})
+}
+
+// GetMyAvailabilityExceptions handles GET /api/me/tutor/availability/exceptions
+// Lists the signed-in tutor's upcoming busy times, earliest first.
+func (h *Handler) GetMyAvailabilityExceptions(c *gin.Context) {
+	tutor, ok := h.myTutorProfile(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	exceptions, err := h.tutors.AvailabilityExceptions([]int{tutor.ID}, now, now.Add(models.ImportHorizon))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve busy times",
+			"status":  "error",
+		})
+		return
+	}
+
+	list := exceptions[tutor.ID]
+	if list == nil {
+		list = []models.AvailabilityException{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":    list,
+		"message": "Busy times retrieved successfully",
+		"status":  "success",
+	})
+}
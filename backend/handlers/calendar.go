@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"tutor-backend/calendar"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// calendarUIDDomain qualifies event UIDs so they are unique across producers
+const calendarUIDDomain = "tutor-match"
+
+// GetMyCalendarFeed handles GET /api/me/calendar
+// Reports whether the signed-in user has a feed. The token itself is only
+// shown when the feed is created.
+func (h *Handler) GetMyCalendarFeed(c *gin.Context) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.feeds.GetByEmail(email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Calendar feed not found",
+				"message": "No calendar feed has been created for this account",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve calendar feed",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    feed,
+		"message": "Calendar feed retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// CreateMyCalendarFeed handles POST /api/me/calendar
+// Creates the signed-in user's feed, or replaces its token so the old URL
+// stops working.
+func (h *Handler) CreateMyCalendarFeed(c *gin.Context) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+
+	token, err := models.NewCalendarToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to create calendar feed",
+			"status":  "error",
+		})
+		return
+	}
+
+	feed := models.CalendarFeed{Email: email, TokenHash: models.HashCalendarToken(token)}
+	if err := h.feeds.Save(&feed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to create calendar feed",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": gin.H{
+			"token":      token,
+			"path":       "/api/calendar/" + token + ".ics",
+			"created_at": feed.CreatedAt,
+		},
+		"message": "Calendar feed created successfully",
+		"status":  "success",
+	})
+}
+
+// DeleteMyCalendarFeed handles DELETE /api/me/calendar
+func (h *Handler) DeleteMyCalendarFeed(c *gin.Context) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+
+	if err := h.feeds.Delete(email); err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Calendar feed not found",
+				"message": "No calendar feed has been created for this account",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to delete calendar feed",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Calendar feed deleted successfully",
+		"status":  "success",
+	})
+}
+
+// GetCalendarFeed handles GET /api/calendar/:token.ics
+// Serves the feed owner's upcoming sessions as an iCalendar file. The token
+// is the only credential, since calendar apps cannot sign in.
+func (h *Handler) GetCalendarFeed(c *gin.Context) {
+	var feed *models.CalendarFeed
+	err := pgx.ErrNoRows
+	if token, found := strings.CutSuffix(c.Param("token"), ".ics"); found {
+		feed, err = h.feeds.GetByTokenHash(models.HashCalendarToken(token))
+	}
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Calendar feed not found",
+			"message": "The calendar link is invalid or has been replaced",
+			"status":  "error",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve calendar feed",
+			"status":  "error",
+		})
+		return
+	}
+
+	cal, err := h.buildCalendar(feed.Email, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to build calendar feed",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", cal.Encode(time.Now()))
+}
+
+// buildCalendar collects the user's upcoming confirmed sessions, as tutor
+// and as client, including any still in progress. They are shown in the
+// user's tutor profile's timezone if they have one and their client
+// profile's otherwise.
+func (h *Handler) buildCalendar(email string, now time.Time) (calendar.Calendar, error) {
+	tutor, client, err := h.profilesForEmail(email)
+	if err != nil {
+		return calendar.Calendar{}, err
+	}
+
+	cal := calendar.Calendar{Name: "Tutoring sessions"}
+	filter := models.SessionFilter{
+		Statuses: []string{models.SessionStatusConfirmed},
+		From:     &now,
+	}
+
+	timezone := ""
+	if client != nil {
+		filter.ClientID = client.ID
+		timezone = client.Timezone
+	}
+	if tutor != nil {
+		filter.TutorID = tutor.ID
+		timezone = tutor.Timezone
+	}
+	if cal.Location, err = models.LoadTimezone(timezone); err != nil {
+		return calendar.Calendar{}, err
+	}
+	if tutor == nil && client == nil {
+		return cal, nil
+	}
+
+	sessions, err := h.sessions.List(filter)
+	if err != nil {
+		return calendar.Calendar{}, err
+	}
+
+	names := map[string]string{}
+	for _, session := range sessions {
+		var withRole string
+		var withID int
+		if participantRole(tutor, client, session.TutorID, session.ClientID) == models.RoleTutor {
+			withRole, withID = models.RoleClient, session.ClientID
+		} else {
+			withRole, withID = models.RoleTutor, session.TutorID
+		}
+
+		key := fmt.Sprintf("%s-%d", withRole, withID)
+		if _, ok := names[key]; !ok {
+			if names[key], err = h.profileName(withRole, withID); err != nil {
+				return calendar.Calendar{}, err
+			}
+		}
+
+		summary := session.Subject
+		if names[key] != "" {
+			summary += " with " + names[key]
+		}
+		cal.Events = append(cal.Events, calendar.Event{
+			UID:          fmt.Sprintf("session-%d@%s", session.ID, calendarUIDDomain),
+			Summary:      summary,
+			Start:        session.StartsAt,
+			End:          session.EndsAt,
+			Status:       calendar.StatusConfirmed,
+			Created:      session.CreatedAt,
+			LastModified: session.UpdatedAt,
+		})
+	}
+
+	return cal, nil
+}
+
+// profileName returns the name of the tutor or client with the ID, or "" if
+// the profile has been deleted
+func (h *Handler) profileName(role string, id int) (string, error) {
+	var name string
+	var err error
+	if role == models.RoleTutor {
+		var tutor *models.Tutor
+		if tutor, err = h.tutors.GetByID(id); err == nil {
+			name = tutor.Name
+		}
+	} else {
+		var client *models.Client
+		if client, err = h.clients.GetByID(id); err == nil {
+			name = client.Name
+		}
+	}
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	return name, err
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+	"tutor-backend/calendar"
+	"tutor-backend/models"
+)
+
+func TestCalendarFeedListsUpcomingConfirmedSessions(t *testing.T) {
+	h, store := newTestHandler(nil)
+	tutor := &models.Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}, Timezone: "UTC"}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+	client := &models.Client{Name: "Grace", Email: "grace@example.com", Subjects: []string{"Math"}, Timezone: "UTC"}
+	if err := store.clients.Create(client); err != nil {
+		t.Fatalf("Create client: %v", err)
+	}
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	sessions := []struct {
+		subject string
+		start   time.Time
+		status  string
+	}{
+		{"Past", now.Add(-48 * time.Hour), models.SessionStatusConfirmed},
+		{"Ongoing", now.Add(-30 * time.Minute), models.SessionStatusConfirmed},
+		{"Upcoming", now.Add(24 * time.Hour), models.SessionStatusConfirmed},
+		{"Requested", now.Add(48 * time.Hour), models.SessionStatusRequested},
+		{"Cancelled", now.Add(72 * time.Hour), models.SessionStatusCancelled},
+	}
+	for _, s := range sessions {
+		session := &models.Session{
+			TutorID:  tutor.ID,
+			ClientID: client.ID,
+			Subject:  s.subject,
+			StartsAt: s.start,
+			EndsAt:   s.start.Add(time.Hour),
+			Status:   s.status,
+		}
+		if err := store.sessions.Create(session); err != nil {
+			t.Fatalf("Create session %s: %v", s.subject, err)
+		}
+	}
+
+	cal, err := h.buildCalendar("grace@example.com", now)
+	if err != nil {
+		t.Fatalf("buildCalendar: %v", err)
+	}
+
+	var got []string
+	for _, event := range cal.Events {
+		got = append(got, event.Summary)
+		if event.Status != calendar.StatusConfirmed {
+			t.Errorf("%s status = %s, want %s", event.Summary, event.Status, calendar.StatusConfirmed)
+		}
+	}
+	want := []string{"Ongoing with Ada", "Upcoming with Ada"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
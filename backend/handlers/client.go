@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"tutor-backend/models"
 
 	"github.com/gin-gonic/gin"
@@ -10,8 +11,14 @@ import (
 )
 
 // GetClients handles GET /api/clients
-func GetClients(c *gin.Context) {
-	clients, err := models.GetClients()
+// With q= the clients are full-text searched by description, most relevant first.
+func (h *Handler) GetClients(c *gin.Context) {
+	if query := strings.TrimSpace(c.Query("q")); query != "" {
+		h.searchClients(c, query)
+		return
+	}
+
+	clients, err := h.clients.List()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
@@ -28,24 +35,49 @@ func GetClients(c *gin.Context) {
 	})
 }
 
-// GetClientByEmail handles GET /api/clients/by-email/:email
-func GetClientByEmail(c *gin.Context) {
-	email := c.Param("email")
-	if email == "" {
+// searchClients responds with the clients matching a full-text query
+func (h *Handler) searchClients(c *gin.Context, query string) {
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Email parameter is required",
-			"message": "Invalid request",
+			"error":   err.Error(),
+			"message": "Invalid limit",
 			"status":  "error",
 		})
 		return
 	}
 
-	client, err := models.GetClientByEmail(email)
+	clients, err := h.clients.Search(query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to search clients",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    clients,
+		"message": "Clients retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetMyClient handles GET /api/me/client
+// Returns the signed-in user's client profile, or null data if they have none.
+func (h *Handler) GetMyClient(c *gin.Context) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+
+	client, err := h.clients.GetByEmail(email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusOK, gin.H{
 				"data":    nil,
-				"message": "No client found with this email",
+				"message": "No client profile for this account",
 				"status":  "success",
 			})
 			return
@@ -66,7 +98,7 @@ func GetClientByEmail(c *gin.Context) {
 }
 
 // CreateClient handles POST /api/clients
-func CreateClient(c *gin.Context) {
+func (h *Handler) CreateClient(c *gin.Context) {
 	var newClient models.Client
 	
 	if err := c.ShouldBindJSON(&newClient); err != nil {
@@ -78,9 +110,39 @@ func CreateClient(c *gin.Context) {
 		return
 	}
 	
+	availability, err := normalizeAvailability(newClient.Availability, newClient.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid availability",
+			"status":  "error",
+		})
+		return
+	}
+	newClient.Availability = availability
+
+	// The profile belongs to the signed-in account, whatever email was submitted
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+	newClient.Email = email
+
+	// Grant the role first so a failed grant leaves no profile behind;
+	// granting again on a retry is a no-op
+	if err := h.roles.Grant(email, models.RoleClient, "signup", nil); err != nil {
+		log.Printf("Failed to grant client role to %s: %v", email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to create client",
+			"status":  "error",
+		})
+		return
+	}
+
 	// Save client to database
-	if err := models.CreateClient(&newClient); err != nil {
-		fmt.Printf("Error creating client: %v\n", err)
+	if err := h.clients.Create(&newClient); err != nil {
+		log.Printf("Failed to create client: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
 			"message": "Failed to create client",
@@ -89,8 +151,6 @@ func CreateClient(c *gin.Context) {
 		return
 	}
 	
-	fmt.Printf("New Client Created: %+v\n", newClient)
-	
 	c.JSON(http.StatusCreated, gin.H{
 		"data":    newClient,
 		"message": "Client profile created successfully",
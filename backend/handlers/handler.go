@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"tutor-backend/models"
+	"tutor-backend/payments"
+)
+
+// Handler serves the API routes through whichever repositories and payment
+// provider it was built with. Without a provider, online payments are
+// unavailable.
+type Handler struct {
+	tutors   models.TutorRepository
+	clients  models.ClientRepository
+	sessions models.SessionRepository
+	feeds    models.CalendarFeedRepository
+	reviews  models.ReviewRepository
+	ledger   models.LedgerRepository
+	intents  models.PaymentRepository
+	payouts  models.PayoutRepository
+	roles    models.RoleRepository
+	pairings models.PairingRepository
+	audit    models.AuditRepository
+	subjects models.SubjectRepository
+	provider payments.Provider
+}
+
+// NewHandler returns a handler backed by the given repositories and payment
+// provider, which may be nil
+func NewHandler(
+	tutors models.TutorRepository,
+	clients models.ClientRepository,
+	sessions models.SessionRepository,
+	feeds models.CalendarFeedRepository,
+	reviews models.ReviewRepository,
+	ledger models.LedgerRepository,
+	intents models.PaymentRepository,
+	payouts models.PayoutRepository,
+	roles models.RoleRepository,
+	pairings models.PairingRepository,
+	audit models.AuditRepository,
+	subjects models.SubjectRepository,
+	provider payments.Provider,
+) *Handler {
+	return &Handler{
+		tutors:   tutors,
+		clients:  clients,
+		sessions: sessions,
+		feeds:    feeds,
+		reviews:  reviews,
+		ledger:   ledger,
+		intents:  intents,
+		payouts:  payouts,
+		roles:    roles,
+		pairings: pairings,
+		audit:    audit,
+		subjects: subjects,
+		provider: provider,
+	}
+}
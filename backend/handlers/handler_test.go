@@ -0,0 +1,432 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tutor-backend/middleware"
+	"tutor-backend/models"
+	"tutor-backend/payments"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testStore holds the in-memory repositories behind a test handler
+type testStore struct {
+	tutors   *models.MemoryTutorRepository
+	clients  *models.MemoryClientRepository
+	sessions *models.MemorySessionRepository
+	reviews  *models.MemoryReviewRepository
+	ledger   *models.MemoryLedgerRepository
+	intents  *models.MemoryPaymentRepository
+	payouts  *models.MemoryPayoutRepository
+	roles    *models.MemoryRoleRepository
+	pairings *models.MemoryPairingRepository
+	audit    *models.MemoryAuditRepository
+}
+
+// newTestHandler returns a handler over empty in-memory repositories
+func newTestHandler(provider payments.Provider) (*Handler, *testStore) {
+	gin.SetMode(gin.TestMode)
+	subjects := models.NewMemorySubjectRepository()
+	store := &testStore{
+		tutors:  models.NewMemoryTutorRepository(subjects),
+		clients: models.NewMemoryClientRepository(subjects),
+		ledger:  models.NewMemoryLedgerRepository(),
+		roles:   models.NewMemoryRoleRepository(),
+		audit:   models.NewMemoryAuditRepository(),
+	}
+	store.sessions = models.NewMemorySessionRepository(store.ledger)
+	store.intents = models.NewMemoryPaymentRepository(store.ledger)
+	store.payouts = models.NewMemoryPayoutRepository(store.ledger)
+	store.pairings = models.NewMemoryPairingRepository(store.tutors, store.clients)
+	store.reviews = models.NewMemoryReviewRepository(store.tutors)
+
+	h := NewHandler(
+		store.tutors,
+		store.clients,
+		store.sessions,
+		models.NewMemoryCalendarFeedRepository(),
+		store.reviews,
+		store.ledger,
+		store.intents,
+		store.payouts,
+		store.roles,
+		store.pairings,
+		store.audit,
+		subjects,
+		provider,
+	)
+	return h, store
+}
+
+// signedIn stands in for middleware.Authenticate with a verified email
+func signedIn(email string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middleware.UIDKey, "uid-"+email)
+		c.Set(middleware.EmailKey, email)
+		c.Set(middleware.EmailVerifiedKey, true)
+	}
+}
+
+// signedInUnverified stands in for middleware.Authenticate with an email
+// Firebase has not verified
+func signedInUnverified(email string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middleware.UIDKey, "uid-"+email)
+		c.Set(middleware.EmailKey, email)
+		c.Set(middleware.EmailVerifiedKey, false)
+	}
+}
+
+// serve sends a request with an optional JSON body and decodes the response envelope
+func serve(t *testing.T, router http.Handler, method, path string, body any) (int, map[string]any) {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+	}
+	request := httptest.NewRequest(method, path, &payload)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	var envelope map[string]any
+	if err := json.Unmarshal(response.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("%s %s: decoding %q: %v", method, path, response.Body, err)
+	}
+	return response.Code, envelope
+}
+
+func TestCreateTutorWithoutPostgres(t *testing.T) {
+	h, store := newTestHandler(nil)
+	router := gin.New()
+	router.GET("/api/tutors", h.GetTutors)
+	router.POST("/api/tutors", signedIn("ada@example.com"), h.CreateTutor)
+	router.GET("/api/me/tutor", signedIn("ada@example.com"), h.GetMyTutor)
+
+	status, body := serve(t, router, http.MethodPost, "/api/tutors", gin.H{
+		"name":     "Ada",
+		"email":    "someone-else@example.com",
+		"subjects": []string{"Math"},
+		"pay":      40,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("POST /api/tutors status = %d, want 201: %v", status, body)
+	}
+	created := body["data"].(map[string]any)
+	if created["email"] != "ada@example.com" {
+		t.Errorf("created email = %v, want the signed-in email", created["email"])
+	}
+
+	status, body = serve(t, router, http.MethodGet, "/api/tutors", nil)
+	if status != http.StatusOK {
+		t.Fatalf("GET /api/tutors status = %d: %v", status, body)
+	}
+	if tutors := body["data"].([]any); len(tutors) != 1 || tutors[0].(map[string]any)["name"] != "Ada" {
+		t.Errorf("GET /api/tutors data = %v, want the created tutor", tutors)
+	}
+
+	status, body = serve(t, router, http.MethodGet, "/api/me/tutor", nil)
+	if status != http.StatusOK || body["data"] == nil || body["data"].(map[string]any)["id"] != created["id"] {
+		t.Errorf("GET /api/me/tutor = %d %v, want the created tutor", status, body)
+	}
+
+	access, err := store.roles.Access("ada@example.com")
+	if err != nil {
+		t.Fatalf("Access: %v", err)
+	}
+	if len(access.Roles) != 1 || access.Roles[0] != models.RoleTutor {
+		t.Errorf("roles = %v, want the tutor role granted at signup", access.Roles)
+	}
+}
+
+func TestUnverifiedEmailCannotClaimProfile(t *testing.T) {
+	h, store := newTestHandler(nil)
+	tutor := &models.Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/api/me/tutor", signedInUnverified("ada@example.com"), h.GetMyTutor)
+	router.POST("/api/clients", signedInUnverified("ada@example.com"), h.CreateClient)
+
+	if status, body := serve(t, router, http.MethodGet, "/api/me/tutor", nil); status != http.StatusForbidden {
+		t.Errorf("GET /api/me/tutor status = %d, want 403: %v", status, body)
+	}
+	status, body := serve(t, router, http.MethodPost, "/api/clients", gin.H{
+		"name":     "Mallory",
+		"subjects": []string{"Math"},
+		"budget":   35,
+	})
+	if status != http.StatusForbidden {
+		t.Errorf("POST /api/clients status = %d, want 403: %v", status, body)
+	}
+}
+
+func TestProfileRoutesLimitedToOwner(t *testing.T) {
+	h, store := newTestHandler(nil)
+	tutor := &models.Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+	client := &models.Client{Name: "Grace", Email: "grace@example.com", Subjects: []string{"Math"}}
+	if err := store.clients.Create(client); err != nil {
+		t.Fatalf("Create client: %v", err)
+	}
+
+	router := gin.New()
+	outsider := signedIn("mallory@example.com")
+	router.GET("/api/clients/:id/matches", outsider, h.GetClientMatches)
+	router.GET("/api/clients/:id/availability", outsider, h.GetClientAvailability)
+	router.GET("/api/tutors/:id/availability", outsider, h.GetTutorAvailability)
+
+	for _, path := range []string{
+		fmt.Sprintf("/api/clients/%d/matches", client.ID),
+		fmt.Sprintf("/api/clients/%d/availability", client.ID),
+		fmt.Sprintf("/api/tutors/%d/availability", tutor.ID),
+	} {
+		if status, body := serve(t, router, http.MethodGet, path, nil); status != http.StatusForbidden {
+			t.Errorf("GET %s status = %d, want 403: %v", path, status, body)
+		}
+	}
+}
+
+func TestCreateClientWithoutPostgres(t *testing.T) {
+	h, store := newTestHandler(nil)
+	router := gin.New()
+	router.POST("/api/clients", signedIn("grace@example.com"), h.CreateClient)
+
+	status, body := serve(t, router, http.MethodPost, "/api/clients", gin.H{
+		"name":     "Grace",
+		"subjects": []string{"Physics"},
+		"budget":   35,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("POST /api/clients status = %d, want 201: %v", status, body)
+	}
+
+	client, err := store.clients.GetByEmail("grace@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	if client.Name != "Grace" || client.Budget != 35 {
+		t.Errorf("stored client = %+v, want Grace with budget 35", client)
+	}
+}
+
+func TestCommitAssignmentsWithoutPostgres(t *testing.T) {
+	h, store := newTestHandler(nil)
+	tutor := &models.Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}, MaxClients: 1}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+	var clients []*models.Client
+	for _, name := range []string{"Grace", "Edsger"} {
+		client := &models.Client{Name: name, Email: strings.ToLower(name) + "@example.com", Subjects: []string{"Math"}}
+		if err := store.clients.Create(client); err != nil {
+			t.Fatalf("Create client: %v", err)
+		}
+		clients = append(clients, client)
+	}
+
+	router := gin.New()
+	router.POST("/api/admin/assignments/commit", h.CommitAssignments)
+	commit := func(client *models.Client) (int, map[string]any) {
+		return serve(t, router, http.MethodPost, "/api/admin/assignments/commit", gin.H{
+			"assignments": []gin.H{{"client_id": client.ID, "tutor_id": tutor.ID}},
+		})
+	}
+
+	if status, body := commit(clients[0]); status != http.StatusCreated {
+		t.Fatalf("first commit status = %d, want 201: %v", status, body)
+	}
+	if status, body := commit(clients[0]); status != http.StatusConflict {
+		t.Errorf("commit of a paired client status = %d, want 409: %v", status, body)
+	}
+	if status, body := commit(clients[1]); status != http.StatusConflict {
+		t.Errorf("commit past the tutor's capacity status = %d, want 409: %v", status, body)
+	}
+
+	paired, err := models.GetPairedClientIDs(store.pairings)
+	if err != nil {
+		t.Fatalf("GetPairedClientIDs: %v", err)
+	}
+	if len(paired) != 1 || !paired[clients[0].ID] {
+		t.Errorf("paired clients = %v, want only %d", paired, clients[0].ID)
+	}
+
+	page, err := store.audit.List(models.AuditFilter{EntityType: "pairing"})
+	if err != nil {
+		t.Fatalf("List audit: %v", err)
+	}
+	if len(page.Entries) != 1 {
+		t.Errorf("pairing audit entries = %d, want 1", len(page.Entries))
+	}
+}
+
+func TestDeletingTutorFreesPairedClient(t *testing.T) {
+	h, store := newTestHandler(nil)
+	var tutors []*models.Tutor
+	for _, name := range []string{"Ada", "Alan"} {
+		tutor := &models.Tutor{Name: name, Email: strings.ToLower(name) + "@example.com", Subjects: []string{"Math"}, MaxClients: 1}
+		if err := store.tutors.Create(tutor); err != nil {
+			t.Fatalf("Create tutor: %v", err)
+		}
+		tutors = append(tutors, tutor)
+	}
+	client := &models.Client{Name: "Grace", Email: "grace@example.com", Subjects: []string{"Math"}}
+	if err := store.clients.Create(client); err != nil {
+		t.Fatalf("Create client: %v", err)
+	}
+	if err := store.pairings.Create([]models.Pairing{{TutorID: tutors[0].ID, ClientID: client.ID}}, nil); err != nil {
+		t.Fatalf("Create pairing: %v", err)
+	}
+
+	router := gin.New()
+	router.DELETE("/api/admin/tutors/:id", h.DeleteTutor)
+	router.POST("/api/admin/assignments/plan", h.PlanAssignments)
+	router.POST("/api/admin/assignments/commit", h.CommitAssignments)
+
+	path := fmt.Sprintf("/api/admin/tutors/%d", tutors[0].ID)
+	if status, body := serve(t, router, http.MethodDelete, path, nil); status != http.StatusOK {
+		t.Fatalf("DELETE %s status = %d: %v", path, status, body)
+	}
+
+	status, body := serve(t, router, http.MethodPost, "/api/admin/assignments/plan", gin.H{"min_score": 0})
+	if status != http.StatusOK {
+		t.Fatalf("plan status = %d: %v", status, body)
+	}
+	assignments := body["data"].(map[string]any)["assignments"].([]any)
+	if len(assignments) != 1 {
+		t.Fatalf("plan = %v, want the client proposed again", body["data"])
+	}
+	proposed := assignments[0].(map[string]any)
+	if int(proposed["client_id"].(float64)) != client.ID || int(proposed["tutor_id"].(float64)) != tutors[1].ID {
+		t.Errorf("proposed %v, want client %d with tutor %d", proposed, client.ID, tutors[1].ID)
+	}
+
+	status, body = serve(t, router, http.MethodPost, "/api/admin/assignments/commit", gin.H{"assignments": assignments})
+	if status != http.StatusCreated {
+		t.Errorf("commit of the planned pairing status = %d, want 201: %v", status, body)
+	}
+}
+
+func TestRolesWithoutPostgres(t *testing.T) {
+	h, store := newTestHandler(nil)
+	if err := models.BootstrapAdmins(store.roles, []string{" Root@Example.com", ""}); err != nil {
+		t.Fatalf("BootstrapAdmins: %v", err)
+	}
+
+	router := gin.New()
+	admin := signedIn("root@example.com")
+	router.GET("/api/me/access", signedIn("grace@example.com"), h.GetMyAccess)
+	router.PUT("/api/admin/users/:email/roles/:role", admin, middleware.RequirePermission(store.roles, models.PermissionRolesWrite), h.GrantRole)
+	router.DELETE("/api/admin/users/:email/roles/:role", admin, middleware.RequirePermission(store.roles, models.PermissionRolesWrite), h.RevokeRole)
+
+	status, body := serve(t, router, http.MethodPut, "/api/admin/users/grace@example.com/roles/coordinator", nil)
+	if status != http.StatusOK {
+		t.Fatalf("grant status = %d, want 200: %v", status, body)
+	}
+
+	status, body = serve(t, router, http.MethodGet, "/api/me/access", nil)
+	permissions, _ := body["data"].(map[string]any)["permissions"].([]any)
+	if status != http.StatusOK || len(permissions) == 0 {
+		t.Errorf("GET /api/me/access = %d %v, want coordinator permissions", status, body)
+	}
+
+	if status, body := serve(t, router, http.MethodPut, "/api/admin/users/grace@example.com/roles/owner", nil); status != http.StatusNotFound {
+		t.Errorf("grant of an unknown role status = %d, want 404: %v", status, body)
+	}
+	if status, body := serve(t, router, http.MethodDelete, "/api/admin/users/root@example.com/roles/admin", nil); status != http.StatusConflict {
+		t.Errorf("revoke of the last admin status = %d, want 409: %v", status, body)
+	}
+	if status, body := serve(t, router, http.MethodDelete, "/api/admin/users/grace@example.com/roles/coordinator", nil); status != http.StatusOK {
+		t.Errorf("revoke status = %d, want 200: %v", status, body)
+	}
+
+	page, err := store.audit.List(models.AuditFilter{Actor: "root@example.com"})
+	if err != nil {
+		t.Fatalf("List audit: %v", err)
+	}
+	if len(page.Entries) != 2 {
+		t.Errorf("audit entries by the admin = %d, want 2", len(page.Entries))
+	}
+}
+
+// failingAudit is an audit log that cannot be written to
+type failingAudit struct {
+	*models.MemoryAuditRepository
+}
+
+func (failingAudit) Record(*models.AuditEntry) error {
+	return errors.New("audit log unavailable")
+}
+
+func TestAdminChangeFailsWhenAuditFails(t *testing.T) {
+	h, store := newTestHandler(nil)
+	h.audit = failingAudit{store.audit}
+	tutor := &models.Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+	client := &models.Client{Name: "Grace", Email: "grace@example.com", Subjects: []string{"Math"}}
+	if err := store.clients.Create(client); err != nil {
+		t.Fatalf("Create client: %v", err)
+	}
+
+	router := gin.New()
+	router.DELETE("/api/admin/tutors/:id", h.DeleteTutor)
+	router.PUT("/api/admin/clients/:id", h.UpdateClient)
+	router.PUT("/api/admin/users/:email/roles/:role", h.GrantRole)
+
+	tests := []struct {
+		method, path string
+		body         any
+		// kept checks that the change was rolled back
+		kept func() bool
+	}{
+		{
+			http.MethodDelete, fmt.Sprintf("/api/admin/tutors/%d", tutor.ID), nil,
+			func() bool {
+				_, err := store.tutors.GetByID(tutor.ID)
+				return err == nil
+			},
+		},
+		{
+			http.MethodPut, fmt.Sprintf("/api/admin/clients/%d", client.ID),
+			gin.H{"name": "Hopper", "email": client.Email, "subjects": []string{"Math"}},
+			func() bool {
+				stored, err := store.clients.GetByID(client.ID)
+				return err == nil && stored.Name == "Grace"
+			},
+		},
+		{
+			http.MethodPut, "/api/admin/users/grace@example.com/roles/admin", nil,
+			func() bool {
+				access, err := store.roles.Access(client.Email)
+				return err == nil && !access.HasPermission(models.PermissionRolesWrite)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if status, body := serve(t, router, tt.method, tt.path, tt.body); status != http.StatusInternalServerError {
+			t.Errorf("%s %s status = %d, want 500: %v", tt.method, tt.path, status, body)
+		}
+		if !tt.kept() {
+			t.Errorf("%s %s was saved without its audit entry", tt.method, tt.path)
+		}
+	}
+}
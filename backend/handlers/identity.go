@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"tutor-backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentEmail returns the verified caller's email, set by middleware.Authenticate.
+// It responds with 403 and returns false when the account has no email
+// address or Firebase has not verified it, since anyone can create an
+// unverified account claiming someone else's address.
+func currentEmail(c *gin.Context) (string, bool) {
+	email := c.GetString(middleware.EmailKey)
+	if email == "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Account has no email address",
+			"message": "An email address is required to manage profiles",
+			"status":  "error",
+		})
+		return "", false
+	}
+	if !c.GetBool(middleware.EmailVerifiedKey) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Email address is not verified",
+			"message": "Verify your email address before managing profiles",
+			"status":  "error",
+		})
+		return "", false
+	}
+	return email, true
+}
+
+// ownerOrPermitted reports whether the caller is the profile owner with
+// ownerEmail or has roles that grant the permission. It responds itself when
+// it returns false.
+func (h *Handler) ownerOrPermitted(c *gin.Context, ownerEmail, permission string) bool {
+	email, ok := currentEmail(c)
+	if !ok {
+		return false
+	}
+	if strings.EqualFold(email, ownerEmail) {
+		return true
+	}
+
+	access, err := h.roles.Access(email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to check permissions",
+			"status":  "error",
+		})
+		return false
+	}
+	if access.HasPermission(permission) {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "Access denied",
+		"message": "Only the profile owner or staff with " + permission + " can view this",
+		"status":  "error",
+	})
+	return false
+}
@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tutor-backend/middleware"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// LedgerRequest records money or credit for a client, in minor units
+type LedgerRequest struct {
+	Amount      int64  `json:"amount" binding:"required"`
+	Description string `json:"description"`
+	InvoiceID   *int   `json:"invoice_id"`
+}
+
+// GenerateInvoices handles POST /api/admin/invoices/generate
+// Invoices every completed session not on an invoice yet, one invoice per
+// client. Runs hourly in the background too.
+func (h *Handler) GenerateInvoices(c *gin.Context) {
+	audit := h.changeAudit(c, func(saved any) []models.AuditChange {
+		changes := []models.AuditChange{}
+		for _, invoice := range saved.([]models.Invoice) {
+			changes = append(changes, models.AuditChange{Action: models.AuditActionCreate, EntityType: "invoice", EntityID: auditID(invoice.ID), After: invoice})
+		}
+		return changes
+	})
+	invoices, err := models.InvoiceCompletedSessions(h.sessions, h.ledger, time.Now(), audit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to generate invoices",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    invoices,
+		"meta":    gin.H{"total": len(invoices)},
+		"message": "Invoices generated successfully",
+		"status":  "success",
+	})
+}
+
+// GetAdminInvoices handles GET /api/admin/invoices
+// Lists invoices newest first. Filters: client_id, status, cursor, limit.
+func (h *Handler) GetAdminInvoices(c *gin.Context) {
+	filter := models.InvoiceFilter{Status: c.Query("status"), Cursor: c.Query("cursor")}
+
+	var err error
+	if value := c.Query("client_id"); value != "" {
+		if filter.ClientID, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("client_id must be a number")
+		}
+	}
+	if err == nil {
+		filter.Limit, err = parseLimit(c.Query("limit"))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid invoice filter",
+			"status":  "error",
+		})
+		return
+	}
+
+	h.respondInvoices(c, filter)
+}
+
+// respondInvoices responds with one page of the invoices matching the filter
+func (h *Handler) respondInvoices(c *gin.Context, filter models.InvoiceFilter) {
+	page, err := h.ledger.ListInvoices(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve invoices",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": page.Invoices,
+		"meta": gin.H{
+			"total":       page.Total,
+			"next_cursor": page.NextCursor,
+		},
+		"message": "Invoices retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// RecordClientPayment handles POST /api/admin/clients/:id/payments
+// Records money received from the client, against one of their invoices if
+// invoice_id is given. Payments against an invoice cannot exceed what is due.
+func (h *Handler) RecordClientPayment(c *gin.Context) {
+	h.postClientEntry(c, "Payment recorded successfully", func(clientID int, request LedgerRequest) models.JournalEntry {
+		return models.PaymentEntry(clientID, request.Amount, request.InvoiceID, request.Description)
+	})
+}
+
+// GrantClientCredit handles POST /api/admin/clients/:id/credits
+// Lowers what the client owes without money changing hands.
+func (h *Handler) GrantClientCredit(c *gin.Context) {
+	h.postClientEntry(c, "Credit granted successfully", func(clientID int, request LedgerRequest) models.JournalEntry {
+		return models.CreditEntry(clientID, request.Amount, request.Description)
+	})
+}
+
+// RefundClient handles POST /api/admin/clients/:id/refunds
+// Records money returned to the client, of a payment against one of their
+// invoices if invoice_id is given. Refunds against an invoice cannot exceed
+// what was paid on it.
+func (h *Handler) RefundClient(c *gin.Context) {
+	h.postClientEntry(c, "Refund recorded successfully", func(clientID int, request LedgerRequest) models.JournalEntry {
+		return models.RefundEntry(clientID, request.Amount, request.InvoiceID, request.Description)
+	})
+}
+
+// postClientEntry posts the journal entry built from the request for the
+// client with the ID param, records it in the audit log and responds with the
+// entry and the client's new balance
+func (h *Handler) postClientEntry(c *gin.Context, message string, build func(int, LedgerRequest) models.JournalEntry) {
+	clientID, ok := h.ledgerClientID(c)
+	if !ok {
+		return
+	}
+
+	var request LedgerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid ledger entry",
+			"status":  "error",
+		})
+		return
+	}
+	if request.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   models.ErrInvalidAmount.Error(),
+			"message": "Invalid ledger entry",
+			"status":  "error",
+		})
+		return
+	}
+
+	entry := build(clientID, request)
+	entry.Description = strings.TrimSpace(entry.Description)
+	entry.CreatedBy = c.GetString(middleware.AdminEmailKey)
+	audit := h.changeAudit(c, func(any) []models.AuditChange {
+		return []models.AuditChange{{Action: models.AuditActionCreate, EntityType: "journal_entry", EntityID: auditID(entry.ID), After: entry}}
+	})
+	if err := h.ledger.Post(&entry, audit); err != nil {
+		if err == models.ErrOverpayment || err == models.ErrRefundExceedsPaid {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   err.Error(),
+				"message": "The amount does not fit the invoice",
+				"status":  "error",
+			})
+			return
+		}
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Invoice not found",
+				"message": "The client has no invoice with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to post ledger entry",
+			"status":  "error",
+		})
+		return
+	}
+
+	totals, err := h.ledger.AccountTotals(models.ClientAccount(clientID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve balance",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": gin.H{
+			"entry":   entry,
+			"balance": models.NewClientBalance(clientID, totals),
+		},
+		"message": message,
+		"status":  "success",
+	})
+}
+
+// ledgerClientID returns the ID param of an existing client. It responds
+// itself when it returns false.
+func (h *Handler) ledgerClientID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid client ID",
+			"message": "Client ID must be a number",
+			"status":  "error",
+		})
+		return 0, false
+	}
+
+	if _, err := h.clients.GetByID(id); err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Client not found",
+				"message": "No client found with the given ID",
+				"status":  "error",
+			})
+			return 0, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve client",
+			"status":  "error",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// GetClientBalance handles GET /api/admin/clients/:id/balance
+func (h *Handler) GetClientBalance(c *gin.Context) {
+	clientID, ok := h.ledgerClientID(c)
+	if !ok {
+		return
+	}
+	h.respondClientBalance(c, clientID)
+}
+
+// respondClientBalance responds with a client's balance
+func (h *Handler) respondClientBalance(c *gin.Context, clientID int) {
+	totals, err := h.ledger.AccountTotals(models.ClientAccount(clientID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve balance",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    models.NewClientBalance(clientID, totals),
+		"message": "Balance retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetTutorEarnings handles GET /api/admin/tutors/:id/earnings
+func (h *Handler) GetTutorEarnings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tutor ID",
+			"message": "Tutor ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	if _, err := h.tutors.GetByID(id); err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	h.respondTutorEarnings(c, id)
+}
+
+// respondTutorEarnings responds with a tutor's earnings
+func (h *Handler) respondTutorEarnings(c *gin.Context, tutorID int) {
+	totals, err := h.ledger.AccountTotals(models.TutorAccount(tutorID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve earnings",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    models.NewTutorEarnings(tutorID, totals),
+		"message": "Earnings retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// myClientProfile returns the signed-in user's client profile. It responds
+// itself when it returns false.
+func (h *Handler) myClientProfile(c *gin.Context) (*models.Client, bool) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return nil, false
+	}
+
+	client, err := h.clients.GetByEmail(email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Client not found",
+				"message": "No client profile for this account",
+				"status":  "error",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve client",
+			"status":  "error",
+		})
+		return nil, false
+	}
+	return client, true
+}
+
+// GetMyBalance handles GET /api/me/balance
+// Returns what the signed-in client owes.
+func (h *Handler) GetMyBalance(c *gin.Context) {
+	client, ok := h.myClientProfile(c)
+	if !ok {
+		return
+	}
+	h.respondClientBalance(c, client.ID)
+}
+
+// GetMyEarnings handles GET /api/me/earnings
+// Returns what the signed-in tutor has earned.
+func (h *Handler) GetMyEarnings(c *gin.Context) {
+	tutor, ok := h.myTutorProfile(c)
+	if !ok {
+		return
+	}
+	h.respondTutorEarnings(c, tutor.ID)
+}
+
+// GetMyInvoices handles GET /api/me/invoices
+// Lists the signed-in client's invoices newest first, paginated by cursor and limit.
+func (h *Handler) GetMyInvoices(c *gin.Context) {
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid limit",
+			"status":  "error",
+		})
+		return
+	}
+
+	client, ok := h.myClientProfile(c)
+	if !ok {
+		return
+	}
+
+	h.respondInvoices(c, models.InvoiceFilter{
+		ClientID: client.ID,
+		Status:   c.Query("status"),
+		Cursor:   c.Query("cursor"),
+		Limit:    limit,
+	})
+}
+
+// GetMyInvoice handles GET /api/me/invoices/:id
+func (h *Handler) GetMyInvoice(c *gin.Context) {
+	invoice, ok := h.myInvoice(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    invoice,
+		"message": "Invoice retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// myInvoice returns the invoice with the ID param if it belongs to the
+// signed-in client. It responds itself when it returns false.
+func (h *Handler) myInvoice(c *gin.Context) (*models.Invoice, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid invoice ID",
+			"message": "Invoice ID must be a number",
+			"status":  "error",
+		})
+		return nil, false
+	}
+
+	client, ok := h.myClientProfile(c)
+	if !ok {
+		return nil, false
+	}
+
+	invoice, err := h.ledger.GetInvoice(id)
+	if err == nil && invoice.ClientID != client.ID {
+		// Other clients' invoices are reported missing rather than forbidden
+		err = pgx.ErrNoRows
+	}
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Invoice not found",
+				"message": "No invoice found with the given ID",
+				"status":  "error",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve invoice",
+			"status":  "error",
+		})
+		return nil, false
+	}
+	return invoice, true
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// invoicedSession books a completed hour at $40 between a new tutor and
+// client and invoices it, returning the client and the invoice
+func invoicedSession(t *testing.T, store *testStore) (*models.Tutor, *models.Client, models.Invoice) {
+	t.Helper()
+	tutor := &models.Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}, Pay: 40}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+	client := &models.Client{Name: "Grace", Email: "grace@example.com", Subjects: []string{"Math"}}
+	if err := store.clients.Create(client); err != nil {
+		t.Fatalf("Create client: %v", err)
+	}
+
+	start := time.Now().Add(-3 * time.Hour)
+	session := &models.Session{
+		TutorID:    tutor.ID,
+		ClientID:   client.ID,
+		Subject:    "Math",
+		StartsAt:   start,
+		EndsAt:     start.Add(time.Hour),
+		Status:     models.SessionStatusConfirmed,
+		HourlyRate: 4000,
+	}
+	if err := store.sessions.Create(session); err != nil {
+		t.Fatalf("Create session: %v", err)
+	}
+
+	invoices, err := models.InvoiceCompletedSessions(store.sessions, store.ledger, time.Now(), nil)
+	if err != nil {
+		t.Fatalf("InvoiceCompletedSessions: %v", err)
+	}
+	if len(invoices) != 1 || invoices[0].Total != 4000 {
+		t.Fatalf("invoices = %+v, want one for 4000", invoices)
+	}
+	return tutor, client, invoices[0]
+}
+
+func TestInvoicePaymentsAndRefundsStayWithinTheInvoice(t *testing.T) {
+	h, store := newTestHandler(nil)
+	_, client, invoice := invoicedSession(t, store)
+
+	router := gin.New()
+	router.POST("/api/admin/clients/:id/payments", h.RecordClientPayment)
+	router.POST("/api/admin/clients/:id/refunds", h.RefundClient)
+	payments := fmt.Sprintf("/api/admin/clients/%d/payments", client.ID)
+	refunds := fmt.Sprintf("/api/admin/clients/%d/refunds", client.ID)
+
+	steps := []struct {
+		path   string
+		amount int64
+		status int
+		paid   int64
+	}{
+		{payments, 4001, http.StatusConflict, 0},
+		{payments, 2500, http.StatusCreated, 2500},
+		{payments, 1501, http.StatusConflict, 2500},
+		{payments, 1500, http.StatusCreated, 4000},
+		{refunds, 4001, http.StatusConflict, 4000},
+		{refunds, 1000, http.StatusCreated, 3000},
+		{refunds, 3001, http.StatusConflict, 3000},
+	}
+	for _, step := range steps {
+		status, body := serve(t, router, http.MethodPost, step.path, gin.H{"amount": step.amount, "invoice_id": invoice.ID})
+		if status != step.status {
+			t.Errorf("POST %s %d status = %d, want %d: %v", step.path, step.amount, status, step.status, body)
+		}
+
+		stored, err := store.ledger.GetInvoice(invoice.ID)
+		if err != nil {
+			t.Fatalf("GetInvoice: %v", err)
+		}
+		if stored.Paid != step.paid {
+			t.Errorf("after POST %s %d paid = %d, want %d", step.path, step.amount, stored.Paid, step.paid)
+		}
+	}
+
+	stored, err := store.ledger.GetInvoice(invoice.ID)
+	if err != nil {
+		t.Fatalf("GetInvoice: %v", err)
+	}
+	if stored.Status != models.InvoiceStatusOpen {
+		t.Errorf("status after a partial refund = %q, want open", stored.Status)
+	}
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"tutor-backend/matching"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// matchingBusyWindow bounds the busy times loaded for matching. It spans more
+// than the current week in every timezone.
+const matchingBusyWindow = 8 * 24 * time.Hour
+
+// newMatcher returns a matcher that expands subjects through the taxonomy and
+// takes the tutors' busy times out of their availability
+func (h *Handler) newMatcher(tutors []models.Tutor) (*matching.Matcher, error) {
+	taxonomy, err := h.subjects.Taxonomy()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(tutors))
+	for _, tutor := range tutors {
+		ids = append(ids, tutor.ID)
+	}
+	now := time.Now()
+	exceptions, err := h.tutors.AvailabilityExceptions(ids, now.Add(-matchingBusyWindow), now.Add(matchingBusyWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := matching.NewMatcher()
+	matcher.Subjects = taxonomy
+	matcher.Reference = now
+	matcher.Busy = map[int][]models.Interval{}
+	for id, list := range exceptions {
+		for _, exception := range list {
+			matcher.Busy[id] = append(matcher.Busy[id], models.Interval{Start: exception.StartsAt, End: exception.EndsAt})
+		}
+	}
+	return matcher, nil
+}
+
+// GetClientMatches handles GET /api/clients/:id/matches
+// Only the client themself and staff with assignments:write may see the matches.
+func (h *Handler) GetClientMatches(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid client ID",
+			"message": "Client ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid limit",
+			"status":  "error",
+		})
+		return
+	}
+
+	client, err := h.clients.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Client not found",
+				"message": "No client found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve client",
+			"status":  "error",
+		})
+		return
+	}
+
+	if !h.ownerOrPermitted(c, client.Email, models.PermissionAssignmentsWrite) {
+		return
+	}
+
+	tutors, err := h.tutors.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutors",
+			"status":  "error",
+		})
+		return
+	}
+
+	matcher, err := h.newMatcher(tutors)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to prepare matching",
+			"status":  "error",
+		})
+		return
+	}
+
+	matches := matcher.RankTutors(*client, tutors)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    matches,
+		"message": "Matches retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetTutorMatches handles GET /api/tutors/:id/matches
+// Paired and inactive clients are excluded unless include_paired/include_inactive is set.
+// Only the tutor themself and staff with assignments:write may see the matches.
+func (h *Handler) GetTutorMatches(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tutor ID",
+			"message": "Tutor ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid limit",
+			"status":  "error",
+		})
+		return
+	}
+
+	includePaired := c.Query("include_paired") == "true"
+	includeInactive := c.Query("include_inactive") == "true"
+
+	tutor, err := h.tutors.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	if !h.ownerOrPermitted(c, tutor.Email, models.PermissionAssignmentsWrite) {
+		return
+	}
+
+	clients, err := h.clients.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve clients",
+			"status":  "error",
+		})
+		return
+	}
+
+	paired := map[int]bool{}
+	if !includePaired {
+		paired, err = models.GetPairedClientIDs(h.pairings)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to retrieve pairings",
+				"status":  "error",
+			})
+			return
+		}
+	}
+
+	candidates := make([]models.Client, 0, len(clients))
+	for _, client := range clients {
+		if paired[client.ID] {
+			continue
+		}
+		if !includeInactive && !client.IsActive() {
+			continue
+		}
+		candidates = append(candidates, client)
+	}
+
+	matcher, err := h.newMatcher([]models.Tutor{*tutor})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to prepare matching",
+			"status":  "error",
+		})
+		return
+	}
+
+	matches := matcher.RankClients(*tutor, candidates)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    matches,
+		"message": "Matches retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// parseLimit parses an optional positive "limit" query parameter (0 means no limit)
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+
+	return limit, nil
+}
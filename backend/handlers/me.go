@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Fields owners may send back unchanged but never modify themselves
+var (
+	tutorProtectedFields  = []string{"id", "email", "rating", "review_count"}
+	clientProtectedFields = []string{"id", "email"}
+)
+
+// readProfileUpdate decodes a self-service update into target. For PUT the
+// target starts empty so every editable field is replaced; for PATCH it starts
+// as a copy of the stored profile so only the fields sent change. Protected
+// fields are accepted only when they match the stored profile, which rejects
+// attempts to edit another user's record or to set one's own rating.
+// It returns the HTTP status to respond with when the update is rejected.
+func readProfileUpdate(c *gin.Context, current, target any, protected []string) (int, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return http.StatusBadRequest, fmt.Errorf("request body must be a JSON object")
+	}
+
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	var stored map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &stored); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	for _, name := range protected {
+		sent, ok := fields[name]
+		if !ok {
+			continue
+		}
+
+		var sentValue, storedValue any
+		if err := json.Unmarshal(sent, &sentValue); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("invalid value for %s", name)
+		}
+		json.Unmarshal(stored[name], &storedValue)
+		if !reflect.DeepEqual(sentValue, storedValue) {
+			return http.StatusForbidden, fmt.Errorf("%s cannot be changed", name)
+		}
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return http.StatusBadRequest, err
+	}
+	return 0, nil
+}
+
+// UpdateMyTutor handles PUT and PATCH /api/me/tutor
+func (h *Handler) UpdateMyTutor(c *gin.Context) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+
+	current, err := h.tutors.GetByEmail(email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor profile for this account",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	var updatedTutor models.Tutor
+	if c.Request.Method == http.MethodPatch {
+		updatedTutor = *current
+	}
+
+	if status, err := readProfileUpdate(c, current, &updatedTutor, tutorProtectedFields); err != nil {
+		c.JSON(status, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid tutor update",
+			"status":  "error",
+		})
+		return
+	}
+
+	// Identity and rating always come from the stored profile
+	updatedTutor.ID = current.ID
+	updatedTutor.Email = current.Email
+	updatedTutor.Rating = current.Rating
+	updatedTutor.ReviewCount = current.ReviewCount
+	updatedTutor.CreatedAt = current.CreatedAt
+
+	availability, err := normalizeAvailability(updatedTutor.Availability, updatedTutor.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid availability",
+			"status":  "error",
+		})
+		return
+	}
+	updatedTutor.Availability = availability
+
+	if err := h.tutors.Update(&updatedTutor, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to update tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updatedTutor,
+		"message": "Tutor profile updated successfully",
+		"status":  "success",
+	})
+}
+
+// UpdateMyClient handles PUT and PATCH /api/me/client
+func (h *Handler) UpdateMyClient(c *gin.Context) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+
+	current, err := h.clients.GetByEmail(email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Client not found",
+				"message": "No client profile for this account",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve client",
+			"status":  "error",
+		})
+		return
+	}
+
+	var updatedClient models.Client
+	if c.Request.Method == http.MethodPatch {
+		updatedClient = *current
+	}
+
+	if status, err := readProfileUpdate(c, current, &updatedClient, clientProtectedFields); err != nil {
+		c.JSON(status, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid client update",
+			"status":  "error",
+		})
+		return
+	}
+
+	// Identity always comes from the stored profile
+	updatedClient.ID = current.ID
+	updatedClient.Email = current.Email
+	updatedClient.CreatedAt = current.CreatedAt
+
+	availability, err := normalizeAvailability(updatedClient.Availability, updatedClient.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid availability",
+			"status":  "error",
+		})
+		return
+	}
+	updatedClient.Availability = availability
+
+	if err := h.clients.Update(&updatedClient, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to update client",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    updatedClient,
+		"message": "Client profile updated successfully",
+		"status":  "success",
+	})
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"tutor-backend/models"
+	"tutor-backend/payments"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxWebhookPayload bounds the size of a payment webhook
+const maxWebhookPayload = 64 << 10
+
+// paymentOutcomes maps the webhook events that settle intents to their outcome
+var paymentOutcomes = map[string]string{
+	payments.EventIntentSucceeded: models.PaymentSucceeded,
+	payments.EventIntentFailed:    models.PaymentFailed,
+	payments.EventChargeRefunded:  models.PaymentRefunded,
+}
+
+// paymentsConfigured responds with 503 and returns false when no payment
+// provider is configured
+func (h *Handler) paymentsConfigured(c *gin.Context) bool {
+	if h.provider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Payments are not configured",
+			"message": "Online payments are unavailable",
+			"status":  "error",
+		})
+		return false
+	}
+	return true
+}
+
+// CreateMyCheckout handles POST /api/me/invoices/:id/checkout
+// Starts a checkout with the payment provider for what is still due on one of
+// the signed-in client's open invoices. The invoice is settled when the
+// provider's webhook reports the payment.
+func (h *Handler) CreateMyCheckout(c *gin.Context) {
+	if !h.paymentsConfigured(c) {
+		return
+	}
+
+	invoice, ok := h.myInvoice(c)
+	if !ok {
+		return
+	}
+
+	due := invoice.Total - invoice.Paid
+	if invoice.Status != models.InvoiceStatusOpen || due <= 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Invoice is not open",
+			"message": "Nothing is due on this invoice",
+			"status":  "error",
+		})
+		return
+	}
+
+	// Retrying while the same amount is due returns the same checkout
+	intent, err := h.provider.CreateIntent(c.Request.Context(), payments.IntentRequest{
+		Amount:         due,
+		Currency:       invoice.Currency,
+		InvoiceID:      invoice.ID,
+		ClientID:       invoice.ClientID,
+		IdempotencyKey: fmt.Sprintf("invoice-%d-paid-%d", invoice.ID, invoice.Paid),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to start checkout",
+			"status":  "error",
+		})
+		return
+	}
+
+	payment := models.PaymentIntent{
+		ProviderID:   intent.ID,
+		InvoiceID:    invoice.ID,
+		ClientID:     invoice.ClientID,
+		Amount:       intent.Amount,
+		Currency:     invoice.Currency,
+		Status:       models.PaymentStatusPending,
+		CheckoutURL:  intent.CheckoutURL,
+		ClientSecret: intent.ClientSecret,
+	}
+	if err := h.intents.CreateIntent(&payment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to save checkout",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    payment,
+		"message": "Checkout started successfully",
+		"status":  "success",
+	})
+}
+
+// GetMyInvoicePayments handles GET /api/me/invoices/:id/payments
+// Lists the checkouts started for one of the signed-in client's invoices.
+func (h *Handler) GetMyInvoicePayments(c *gin.Context) {
+	invoice, ok := h.myInvoice(c)
+	if !ok {
+		return
+	}
+
+	intents, err := h.intents.ListIntents(invoice.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve payments",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    intents,
+		"message": "Payments retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// PaymentWebhook handles POST /api/payments/webhook
+// Verifies the provider's signature and applies the event to its checkout and
+// invoice. Each event ID is applied once, so redeliveries are acknowledged
+// without effect. Events for unknown checkouts get 404 so the provider
+// retries them, and events whose amount or currency differ from their
+// checkout's are rejected. Payments no longer due on the invoice are held
+// as credit for the client.
+func (h *Handler) PaymentWebhook(c *gin.Context) {
+	if !h.paymentsConfigured(c) {
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookPayload))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to read webhook",
+			"status":  "error",
+		})
+		return
+	}
+
+	event, err := h.provider.ParseWebhook(payload, c.GetHeader(payments.SignatureHeader))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid webhook",
+			"status":  "error",
+		})
+		return
+	}
+
+	outcome, ok := paymentOutcomes[event.Type]
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{
+			"data":    gin.H{"event_id": event.ID},
+			"message": "Event ignored",
+			"status":  "success",
+		})
+		return
+	}
+
+	applied, err := h.intents.ApplyEvent(models.PaymentEvent{
+		ID:         event.ID,
+		Outcome:    outcome,
+		ProviderID: event.IntentID(),
+		Amount:     event.Data.Object.Amount,
+		Currency:   event.Data.Object.Currency,
+		Refunded:   event.Data.Object.AmountRefunded,
+	})
+	if err != nil {
+		if err == models.ErrEventMismatch {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   err.Error(),
+				"message": "Event does not match its checkout",
+				"status":  "error",
+			})
+			return
+		}
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Payment not found",
+				"message": "No checkout found for the event's payment intent",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to apply payment event",
+			"status":  "error",
+		})
+		return
+	}
+
+	message := "Event applied"
+	if !applied {
+		message = "Event already processed"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":    gin.H{"event_id": event.ID},
+		"message": message,
+		"status":  "success",
+	})
+}
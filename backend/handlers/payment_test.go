@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"tutor-backend/models"
+	"tutor-backend/payments"
+	"tutor-backend/payments/paymentstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// paymentFlow is a handler wired to a fake provider whose webhooks reach it
+type paymentFlow struct {
+	provider *paymentstest.Provider
+	store    *testStore
+	router   *gin.Engine
+	client   *models.Client
+	invoice  models.Invoice
+}
+
+func newPaymentFlow(t *testing.T) *paymentFlow {
+	t.Helper()
+	provider := paymentstest.NewProvider("")
+	t.Cleanup(provider.Close)
+	client, err := payments.NewClient(provider.Config())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	h, store := newTestHandler(client)
+	flow := &paymentFlow{provider: provider, store: store, router: gin.New()}
+	_, flow.client, flow.invoice = invoicedSession(t, store)
+
+	flow.router.POST("/api/me/invoices/:id/checkout", signedIn(flow.client.Email), h.CreateMyCheckout)
+	flow.router.POST("/api/payments/webhook", h.PaymentWebhook)
+	server := httptest.NewServer(flow.router)
+	t.Cleanup(server.Close)
+	provider.WebhookURL = server.URL + "/api/payments/webhook"
+	return flow
+}
+
+// checkout starts a checkout for what is due on the invoice and returns the
+// provider's intent ID
+func (f *paymentFlow) checkout(t *testing.T) string {
+	t.Helper()
+	status, body := serve(t, f.router, http.MethodPost, fmt.Sprintf("/api/me/invoices/%d/checkout", f.invoice.ID), nil)
+	if status != http.StatusCreated {
+		t.Fatalf("checkout status = %d, want 201: %v", status, body)
+	}
+	return body["data"].(map[string]any)["provider_id"].(string)
+}
+
+// webhook posts a payload with a signature header and returns the status
+func (f *paymentFlow) webhook(t *testing.T, payload []byte, signature string) int {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/api/payments/webhook", bytes.NewReader(payload))
+	request.Header.Set(payments.SignatureHeader, signature)
+	response := httptest.NewRecorder()
+	f.router.ServeHTTP(response, request)
+	return response.Code
+}
+
+// expect checks the invoice's status and paid amount and the client's balance
+func (f *paymentFlow) expect(t *testing.T, step, status string, paid, balance int64) {
+	t.Helper()
+	invoice, err := f.store.ledger.GetInvoice(f.invoice.ID)
+	if err != nil {
+		t.Fatalf("GetInvoice: %v", err)
+	}
+	totals, err := f.store.ledger.AccountTotals(models.ClientAccount(f.client.ID))
+	if err != nil {
+		t.Fatalf("AccountTotals: %v", err)
+	}
+	got := models.NewClientBalance(f.client.ID, totals)
+	if invoice.Status != status || invoice.Paid != paid || got.Balance != balance {
+		t.Errorf("%s: invoice %s paid %d, balance %d; want %s paid %d, balance %d",
+			step, invoice.Status, invoice.Paid, got.Balance, status, paid, balance)
+	}
+}
+
+// succeededEvent returns the payload of a succeeded event for an intent
+func succeededEvent(t *testing.T, id string, intent *payments.Intent, amount int64) []byte {
+	t.Helper()
+	event := payments.Event{ID: id, Type: payments.EventIntentSucceeded, Created: time.Now().Unix()}
+	event.Data.Object = payments.EventObject{
+		ID:       intent.ID,
+		Object:   "payment_intent",
+		Amount:   amount,
+		Currency: intent.Currency,
+		Status:   paymentstest.StatusSucceeded,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return payload
+}
+
+func TestCheckoutFlowAgainstFakeProvider(t *testing.T) {
+	flow := newPaymentFlow(t)
+	flow.expect(t, "invoiced", models.InvoiceStatusOpen, 0, 4000)
+
+	intentID := flow.checkout(t)
+
+	// Forged, stale and mismatched webhooks change nothing
+	payload := succeededEvent(t, "evt_forged", flow.provider.Intent(intentID), 4000)
+	secret := flow.provider.WebhookSecret
+	if status := flow.webhook(t, payload, payments.Sign(payload, "whsec_forged", time.Now())); status != http.StatusUnauthorized {
+		t.Errorf("forged signature status = %d, want 401", status)
+	}
+	if status := flow.webhook(t, payload, payments.Sign(payload, secret, time.Now().Add(-6*time.Minute))); status != http.StatusUnauthorized {
+		t.Errorf("stale signature status = %d, want 401", status)
+	}
+	mismatched := succeededEvent(t, "evt_mismatched", flow.provider.Intent(intentID), 400)
+	if status := flow.webhook(t, mismatched, payments.Sign(mismatched, secret, time.Now())); status != http.StatusBadRequest {
+		t.Errorf("mismatched amount status = %d, want 400", status)
+	}
+	flow.expect(t, "rejected webhooks", models.InvoiceStatusOpen, 0, 4000)
+
+	eventID, err := flow.provider.Succeed(intentID)
+	if err != nil {
+		t.Fatalf("Succeed: %v", err)
+	}
+	flow.expect(t, "paid", models.InvoiceStatusPaid, 4000, 0)
+
+	if err := flow.provider.Resend(eventID); err != nil {
+		t.Fatalf("Resend: %v", err)
+	}
+	flow.expect(t, "redelivered", models.InvoiceStatusPaid, 4000, 0)
+
+	refundID, err := flow.provider.Refund(intentID, 1500)
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	flow.expect(t, "partly refunded", models.InvoiceStatusOpen, 2500, 1500)
+
+	if err := flow.provider.Resend(refundID); err != nil {
+		t.Fatalf("Resend refund: %v", err)
+	}
+	flow.expect(t, "refund redelivered", models.InvoiceStatusOpen, 2500, 1500)
+
+	intents, err := flow.store.intents.ListIntents(flow.invoice.ID)
+	if err != nil {
+		t.Fatalf("ListIntents: %v", err)
+	}
+	if len(intents) != 1 || intents[0].Status != models.PaymentStatusRefunded || intents[0].Refunded != 1500 {
+		t.Errorf("intents = %+v, want one refunded by 1500", intents)
+	}
+}
+
+func TestPaymentAfterInvoiceIsSettledIsHeldAsCredit(t *testing.T) {
+	flow := newPaymentFlow(t)
+	first := flow.checkout(t)
+
+	// The invoice is paid another way while the checkout is open
+	invoiceID := flow.invoice.ID
+	manual := models.PaymentEntry(flow.client.ID, 4000, &invoiceID, "Bank transfer")
+	if err := flow.store.ledger.Post(&manual, nil); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	flow.expect(t, "paid by transfer", models.InvoiceStatusPaid, 4000, 0)
+
+	if _, err := flow.provider.Succeed(first); err != nil {
+		t.Fatalf("Succeed: %v", err)
+	}
+	flow.expect(t, "late card payment", models.InvoiceStatusPaid, 4000, -4000)
+
+	intents, err := flow.store.intents.ListIntents(flow.invoice.ID)
+	if err != nil {
+		t.Fatalf("ListIntents: %v", err)
+	}
+	if len(intents) != 1 || intents[0].Unapplied != 4000 {
+		t.Errorf("intents = %+v, want the payment unapplied", intents)
+	}
+
+	// Refunding the card payment returns the credit, not the transfer
+	if _, err := flow.provider.Refund(first, 4000); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	flow.expect(t, "credit refunded", models.InvoiceStatusPaid, 4000, 0)
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tutor-backend/middleware"
+	"tutor-backend/models"
+	"tutor-backend/statement"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// PayoutPaidRequest records how a statement was paid
+type PayoutPaidRequest struct {
+	Reference string `json:"reference"`
+}
+
+// GeneratePayouts handles POST /api/admin/payouts/generate
+// Builds statements for a month, given as period=YYYY-MM and defaulting to
+// the previous month, at the commission in PAYOUT_COMMISSION_PERCENT. Runs
+// daily in the background for the previous month too.
+func (h *Handler) GeneratePayouts(c *gin.Context) {
+	period := models.MonthPeriod(models.MonthPeriod(time.Now()).Start.AddDate(0, -1, 0))
+	if value := c.Query("period"); value != "" {
+		var err error
+		if period, err = models.ParseMonthPeriod(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   err.Error(),
+				"message": "Invalid payout period",
+				"status":  "error",
+			})
+			return
+		}
+	}
+	if period.End.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "period has not ended",
+			"message": "Invalid payout period",
+			"status":  "error",
+		})
+		return
+	}
+
+	audit := h.changeAudit(c, func(saved any) []models.AuditChange {
+		changes := []models.AuditChange{}
+		for _, statement := range saved.([]models.PayoutStatement) {
+			changes = append(changes, models.AuditChange{Action: models.AuditActionCreate, EntityType: "payout_statement", EntityID: auditID(statement.ID), After: statement})
+		}
+		return changes
+	})
+	statements, err := h.payouts.CreateStatements(period, models.CommissionRate(), audit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to generate payout statements",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    statements,
+		"meta":    gin.H{"total": len(statements)},
+		"message": "Payout statements generated successfully",
+		"status":  "success",
+	})
+}
+
+// GetAdminPayouts handles GET /api/admin/payouts
+// Lists payout statements newest first. Filters: tutor_id, status, cursor, limit.
+func (h *Handler) GetAdminPayouts(c *gin.Context) {
+	filter := models.PayoutFilter{Status: c.Query("status"), Cursor: c.Query("cursor")}
+
+	var err error
+	if value := c.Query("tutor_id"); value != "" {
+		if filter.TutorID, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("tutor_id must be a number")
+		}
+	}
+	if err == nil {
+		filter.Limit, err = parseLimit(c.Query("limit"))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid payout filter",
+			"status":  "error",
+		})
+		return
+	}
+
+	h.respondPayouts(c, filter)
+}
+
+// respondPayouts responds with one page of the statements matching the filter
+func (h *Handler) respondPayouts(c *gin.Context, filter models.PayoutFilter) {
+	page, err := h.payouts.ListStatements(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve payout statements",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": page.Statements,
+		"meta": gin.H{
+			"total":       page.Total,
+			"next_cursor": page.NextCursor,
+		},
+		"message": "Payout statements retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// payoutStatement returns the statement with the ID param. It responds itself
+// when it returns false.
+func (h *Handler) payoutStatement(c *gin.Context) (*models.PayoutStatement, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid statement ID",
+			"message": "Statement ID must be a number",
+			"status":  "error",
+		})
+		return nil, false
+	}
+
+	statement, err := h.payouts.GetStatement(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Statement not found",
+				"message": "No payout statement found with the given ID",
+				"status":  "error",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve payout statement",
+			"status":  "error",
+		})
+		return nil, false
+	}
+	return statement, true
+}
+
+// MarkPayoutPaid handles POST /api/admin/payouts/:id/paid
+// Records that the statement's net amount was paid to the tutor, with an
+// optional reference such as a bank transfer ID.
+func (h *Handler) MarkPayoutPaid(c *gin.Context) {
+	var request PayoutPaidRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   err.Error(),
+				"message": "Invalid payout",
+				"status":  "error",
+			})
+			return
+		}
+	}
+
+	before, ok := h.payoutStatement(c)
+	if !ok {
+		return
+	}
+
+	statement := *before
+	statement.PaidBy = c.GetString(middleware.AdminEmailKey)
+	statement.Reference = strings.TrimSpace(request.Reference)
+	if err := h.payouts.MarkPaid(&statement, h.auditChange(c, models.AuditActionUpdate, "payout_statement", auditID(statement.ID), before)); err != nil {
+		if err == models.ErrStatementPaid {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   err.Error(),
+				"message": "Statements can be paid once",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to mark payout statement paid",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    statement,
+		"message": "Payout statement marked paid",
+		"status":  "success",
+	})
+}
+
+// DownloadPayout handles GET /api/admin/payouts/:id/download
+// Serves the statement as format=csv (the default) or format=pdf.
+func (h *Handler) DownloadPayout(c *gin.Context) {
+	statement, ok := h.payoutStatement(c)
+	if !ok {
+		return
+	}
+	h.sendStatement(c, statement)
+}
+
+// GetMyPayouts handles GET /api/me/payouts
+// Lists the signed-in tutor's payout statements newest first. Filters:
+// status, cursor, limit.
+func (h *Handler) GetMyPayouts(c *gin.Context) {
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid limit",
+			"status":  "error",
+		})
+		return
+	}
+
+	tutor, ok := h.myTutorProfile(c)
+	if !ok {
+		return
+	}
+
+	h.respondPayouts(c, models.PayoutFilter{
+		TutorID: tutor.ID,
+		Status:  c.Query("status"),
+		Cursor:  c.Query("cursor"),
+		Limit:   limit,
+	})
+}
+
+// DownloadMyPayout handles GET /api/me/payouts/:id/download
+// Serves one of the signed-in tutor's statements as format=csv (the default)
+// or format=pdf.
+func (h *Handler) DownloadMyPayout(c *gin.Context) {
+	tutor, ok := h.myTutorProfile(c)
+	if !ok {
+		return
+	}
+
+	statement, ok := h.payoutStatement(c)
+	if !ok {
+		return
+	}
+	if statement.TutorID != tutor.ID {
+		// Other tutors' statements are reported missing rather than forbidden
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Statement not found",
+			"message": "No payout statement found with the given ID",
+			"status":  "error",
+		})
+		return
+	}
+
+	h.sendStatement(c, statement)
+}
+
+// sendStatement renders a statement in the requested format as an attachment
+func (h *Handler) sendStatement(c *gin.Context, payout *models.PayoutStatement) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   fmt.Sprintf("unknown format %q", format),
+			"message": "Statements are available as csv or pdf",
+			"status":  "error",
+		})
+		return
+	}
+
+	payee := fmt.Sprintf("Tutor %d", payout.TutorID)
+	if tutor, err := h.tutors.GetByID(payout.TutorID); err == nil {
+		payee = tutor.Name
+	}
+
+	document := &statement.Document{
+		Number:         payout.ID,
+		Payee:          payee,
+		Period:         payout.Period.Label(),
+		Currency:       payout.Currency,
+		Status:         payout.Status,
+		Issued:         payout.CreatedAt,
+		Gross:          payout.Gross,
+		CommissionRate: payout.CommissionRate,
+		Commission:     payout.Commission,
+		Net:            payout.Net,
+	}
+	for _, line := range payout.Lines {
+		document.Lines = append(document.Lines, statement.Line{
+			SessionID:   line.SessionID,
+			InvoiceID:   line.InvoiceID,
+			Description: line.Description,
+			Minutes:     line.Minutes,
+			Amount:      line.Amount,
+		})
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, document.Filename(format)))
+	if format == "pdf" {
+		c.Data(http.StatusOK, "application/pdf", statement.EncodePDF(document))
+		return
+	}
+
+	encoded, err := statement.EncodeCSV(document)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to render statement",
+			"status":  "error",
+		})
+		return
+	}
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", encoded)
+}
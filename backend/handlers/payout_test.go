@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+	"tutor-backend/models"
+)
+
+// settle posts a payment or refund against an invoice
+func settle(t *testing.T, store *testStore, entry models.JournalEntry) {
+	t.Helper()
+	if err := store.ledger.Post(&entry, nil); err != nil {
+		t.Fatalf("Post %s: %v", entry.Kind, err)
+	}
+}
+
+// generate creates this month's statements at a 15% commission
+func generate(t *testing.T, store *testStore) []models.PayoutStatement {
+	t.Helper()
+	statements, err := store.payouts.CreateStatements(models.MonthPeriod(time.Now()), 1500, nil)
+	if err != nil {
+		t.Fatalf("CreateStatements: %v", err)
+	}
+	return statements
+}
+
+func TestRefundAfterPayoutIsReversedOnTheNextStatement(t *testing.T) {
+	_, store := newTestHandler(nil)
+	tutor, client, first := invoicedSession(t, store)
+	settle(t, store, models.PaymentEntry(client.ID, first.Total, &first.ID, "Card"))
+
+	statements := generate(t, store)
+	if len(statements) != 1 || statements[0].Gross != 4000 || statements[0].Commission != 600 {
+		t.Fatalf("first statements = %+v, want one for 4000 less 600", statements)
+	}
+
+	// The first session is refunded after it was paid out
+	settle(t, store, models.RefundEntry(client.ID, first.Total, &first.ID, "Card refund"))
+	if statements := generate(t, store); len(statements) != 0 {
+		t.Fatalf("reversal alone made statements %+v, want it carried over", statements)
+	}
+
+	start := time.Now().Add(-6 * time.Hour)
+	session := &models.Session{
+		TutorID:    tutor.ID,
+		ClientID:   client.ID,
+		Subject:    "Math",
+		StartsAt:   start,
+		EndsAt:     start.Add(2 * time.Hour),
+		Status:     models.SessionStatusConfirmed,
+		HourlyRate: 4000,
+	}
+	if err := store.sessions.Create(session); err != nil {
+		t.Fatalf("Create session: %v", err)
+	}
+	invoices, err := models.InvoiceCompletedSessions(store.sessions, store.ledger, time.Now(), nil)
+	if err != nil || len(invoices) != 1 {
+		t.Fatalf("InvoiceCompletedSessions = %+v, %v; want one invoice", invoices, err)
+	}
+	second := invoices[0]
+	settle(t, store, models.PaymentEntry(client.ID, second.Total, &second.ID, "Card"))
+
+	statements = generate(t, store)
+	if len(statements) != 1 {
+		t.Fatalf("second statements = %+v, want one", statements)
+	}
+	statement := statements[0]
+	if statement.Gross != 4000 || statement.Commission != 600 || statement.Net != 3400 || len(statement.Lines) != 2 {
+		t.Errorf("second statement = %+v, want 8000 earned less a 4000 reversal", statement)
+	}
+	for _, line := range statement.Lines {
+		if line.InvoiceID == first.ID && (line.Amount != -4000 || line.Minutes != -60) {
+			t.Errorf("reversal line = %+v, want -4000 over -60 minutes", line)
+		}
+	}
+
+	totals, err := store.ledger.AccountTotals(models.AccountCommission)
+	if err != nil {
+		t.Fatalf("AccountTotals: %v", err)
+	}
+	if got := -totals[models.EntryCommission]; got != 1200 {
+		t.Errorf("commission earned = %d, want 1200 on the 8000 not refunded", got)
+	}
+
+	if statements := generate(t, store); len(statements) != 0 {
+		t.Errorf("third statements = %+v, want none", statements)
+	}
+}
+
+func TestReversalReturnsCommissionAtThePaidOutRate(t *testing.T) {
+	_, store := newTestHandler(nil)
+	tutor, client, first := invoicedSession(t, store)
+	settle(t, store, models.PaymentEntry(client.ID, first.Total, &first.ID, "Card"))
+	generate(t, store)
+	settle(t, store, models.RefundEntry(client.ID, first.Total, &first.ID, "Card refund"))
+
+	start := time.Now().Add(-6 * time.Hour)
+	session := &models.Session{
+		TutorID:    tutor.ID,
+		ClientID:   client.ID,
+		Subject:    "Math",
+		StartsAt:   start,
+		EndsAt:     start.Add(2 * time.Hour),
+		Status:     models.SessionStatusConfirmed,
+		HourlyRate: 4000,
+	}
+	if err := store.sessions.Create(session); err != nil {
+		t.Fatalf("Create session: %v", err)
+	}
+	invoices, err := models.InvoiceCompletedSessions(store.sessions, store.ledger, time.Now(), nil)
+	if err != nil || len(invoices) != 1 {
+		t.Fatalf("InvoiceCompletedSessions = %+v, %v; want one invoice", invoices, err)
+	}
+	settle(t, store, models.PaymentEntry(client.ID, invoices[0].Total, &invoices[0].ID, "Card"))
+
+	// The commission rises from 15% to 20% before the reversal
+	statements, err := store.payouts.CreateStatements(models.MonthPeriod(time.Now()), 2000, nil)
+	if err != nil || len(statements) != 1 {
+		t.Fatalf("CreateStatements = %+v, %v; want one statement", statements, err)
+	}
+	statement := statements[0]
+	if statement.Gross != 4000 || statement.Commission != 1000 || statement.Net != 3000 {
+		t.Errorf("statement = %+v, want 1600 on 8000 earned less 600 given back on the 4000 reversal", statement)
+	}
+	for _, line := range statement.Lines {
+		want := int64(2000)
+		if line.InvoiceID == first.ID {
+			want = 1500
+		}
+		if line.CommissionRate != want {
+			t.Errorf("line %+v has rate %d, want %d", line, line.CommissionRate, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReviewRequest is a client's review of a session
+type ReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required"`
+	Comment string `json:"comment"`
+}
+
+// ReviewSession handles POST /api/sessions/:id/review
+// Only the session's client can review it, once, after it has taken place.
+// Comments containing a blocked word are held for moderation; other reviews
+// are published and count toward the tutor's rating at once.
+func (h *Handler) ReviewSession(c *gin.Context) {
+	var request ReviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid review",
+			"status":  "error",
+		})
+		return
+	}
+
+	session, role, ok := h.sessionForCaller(c)
+	if !ok {
+		return
+	}
+	if role != models.RoleClient {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Only the client can review a session",
+			"message": "Tutors cannot review their own sessions",
+			"status":  "error",
+		})
+		return
+	}
+	if !session.Completed(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Session has not been completed",
+			"message": "Only confirmed sessions that have ended can be reviewed",
+			"status":  "error",
+		})
+		return
+	}
+
+	review := models.Review{
+		SessionID: session.ID,
+		TutorID:   session.TutorID,
+		ClientID:  session.ClientID,
+		Rating:    request.Rating,
+		Comment:   strings.TrimSpace(request.Comment),
+	}
+	if err := models.ValidateReview(&review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid review",
+			"status":  "error",
+		})
+		return
+	}
+
+	review.Screen(models.BlockedWords())
+
+	if err := h.reviews.Create(&review); err != nil {
+		if err == models.ErrAlreadyReviewed {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   err.Error(),
+				"message": "Each session can be reviewed once",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to save review",
+			"status":  "error",
+		})
+		return
+	}
+
+	message := "Review published successfully"
+	if review.Status == models.ReviewStatusPending {
+		message = "Review submitted and held for moderation"
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    review,
+		"message": message,
+		"status":  "success",
+	})
+}
+
+// GetTutorReviews handles GET /api/tutors/:id/reviews
+// Lists the tutor's published reviews newest first, paginated by cursor and limit.
+func (h *Handler) GetTutorReviews(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tutor ID",
+			"message": "Tutor ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	limit, err := parseLimit(c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid limit",
+			"status":  "error",
+		})
+		return
+	}
+
+	if _, err := h.tutors.GetByID(id); err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	page, err := h.reviews.List(models.ReviewFilter{
+		TutorID:  id,
+		Statuses: []string{models.ReviewStatusPublished},
+		Cursor:   c.Query("cursor"),
+		Limit:    limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve reviews",
+			"status":  "error",
+		})
+		return
+	}
+
+	reviews := make([]models.PublicReview, 0, len(page.Reviews))
+	for _, review := range page.Reviews {
+		reviews = append(reviews, review.Public())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": reviews,
+		"meta": gin.H{
+			"total":       page.Total,
+			"next_cursor": page.NextCursor,
+		},
+		"message": "Reviews retrieved successfully",
+		"status":  "success",
+	})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"tutor-backend/middleware"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetAdminReviews handles GET /api/admin/reviews
+// Lists reviews newest first. Filters: status (comma-separated, default
+// "pending"), tutor_id, cursor, limit.
+func (h *Handler) GetAdminReviews(c *gin.Context) {
+	filter := models.ReviewFilter{Cursor: c.Query("cursor")}
+
+	for _, status := range strings.Split(c.DefaultQuery("status", models.ReviewStatusPending), ",") {
+		status = strings.TrimSpace(status)
+		if !models.ValidReviewStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   fmt.Sprintf("unknown status %q", status),
+				"message": "Invalid review filter",
+				"status":  "error",
+			})
+			return
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	var err error
+	if value := c.Query("tutor_id"); value != "" {
+		if filter.TutorID, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("tutor_id must be a number")
+		}
+	}
+	if err == nil {
+		filter.Limit, err = parseLimit(c.Query("limit"))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid review filter",
+			"status":  "error",
+		})
+		return
+	}
+
+	page, err := h.reviews.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve reviews",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": page.Reviews,
+		"meta": gin.H{
+			"total":       page.Total,
+			"next_cursor": page.NextCursor,
+		},
+		"message": "Reviews retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// ApproveReview handles POST /api/admin/reviews/:id/approve
+// Publishes the review, so it is shown and counts toward the tutor's rating.
+func (h *Handler) ApproveReview(c *gin.Context) {
+	h.moderateReview(c, "Review approved successfully", func(review *models.Review) {
+		review.Status = models.ReviewStatusPublished
+	})
+}
+
+// RejectReview handles POST /api/admin/reviews/:id/reject
+// Hides the review and drops it from the tutor's rating.
+func (h *Handler) RejectReview(c *gin.Context) {
+	h.moderateReview(c, "Review rejected successfully", func(review *models.Review) {
+		review.Status = models.ReviewStatusRejected
+	})
+}
+
+// RedactReview handles POST /api/admin/reviews/:id/redact
+// Removes the review's comment and publishes its star rating alone, for
+// genuine reviews whose text cannot be shown.
+func (h *Handler) RedactReview(c *gin.Context) {
+	h.moderateReview(c, "Review redacted successfully", func(review *models.Review) {
+		review.Status = models.ReviewStatusPublished
+		review.Comment = ""
+		review.Redacted = true
+	})
+}
+
+// moderateReview applies a moderation decision to the review with the ID
+// param, records it in the audit log and responds with the updated review
+func (h *Handler) moderateReview(c *gin.Context, message string, decide func(*models.Review)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid review ID",
+			"message": "Review ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	before, err := h.reviews.GetByID(id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Review not found",
+				"message": "No review found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve review",
+			"status":  "error",
+		})
+		return
+	}
+
+	review := *before
+	decide(&review)
+	review.ModeratedBy = c.GetString(middleware.AdminEmailKey)
+	if err := h.reviews.Moderate(&review, h.auditChange(c, models.AuditActionUpdate, "review", auditID(id), before)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to moderate review",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    review,
+		"message": message,
+		"status":  "success",
+	})
+}
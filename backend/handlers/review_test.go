@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+	"tutor-backend/middleware"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHeldWords(t *testing.T) {
+	blocked := []string{"scam", "rip off", "idiot"}
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Patient and clear, highly recommend", []string{}},
+		{"Total SCAM!!", []string{"scam"}},
+		{"What a rip-off, an idiot.", []string{"rip off", "idiot"}},
+		{"Scampered through the syllabus", []string{}},
+		{"Never\nripped off anyone", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := models.HeldWords(tt.text, blocked); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("HeldWords(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestScreen(t *testing.T) {
+	blocked := []string{"scam", "idiot"}
+	tests := []struct {
+		comment string
+		status  string
+		reason  string
+	}{
+		{"Great tutor", models.ReviewStatusPublished, ""},
+		{"", models.ReviewStatusPublished, ""},
+		{"An idiot running a scam", models.ReviewStatusPending, "contains scam, idiot"},
+	}
+
+	for _, tt := range tests {
+		// A review screened again, e.g. after an edit, loses a stale hold
+		review := models.Review{Comment: tt.comment, Status: models.ReviewStatusPending, HoldReason: "contains scam"}
+		review.Screen(blocked)
+		if review.Status != tt.status || review.HoldReason != tt.reason {
+			t.Errorf("Screen(%q) = %q, %q; want %q, %q", tt.comment, review.Status, review.HoldReason, tt.status, tt.reason)
+		}
+	}
+}
+
+func TestBayesianRating(t *testing.T) {
+	tests := []struct {
+		count, total int
+		want         float64
+	}{
+		{0, 0, 0},
+		{1, 5, 4.17},
+		{1, 1, 3.5},
+		{5, 25, 4.5},
+		{95, 95 * 5, 4.95},
+	}
+
+	for _, tt := range tests {
+		if got := models.BayesianRating(tt.count, tt.total); got != tt.want {
+			t.Errorf("BayesianRating(%d, %d) = %v, want %v", tt.count, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestReviewModerationUpdatesRating(t *testing.T) {
+	t.Setenv("REVIEW_BLOCKED_WORDS", "scam")
+	h, store := newTestHandler(nil)
+	tutor := &models.Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}, Pay: 40}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+	client := &models.Client{Name: "Grace", Email: "grace@example.com", Subjects: []string{"Math"}}
+	if err := store.clients.Create(client); err != nil {
+		t.Fatalf("Create client: %v", err)
+	}
+	sessions := make([]*models.Session, 2)
+	for i := range sessions {
+		start := time.Now().Add(time.Duration(-3-2*i) * time.Hour)
+		sessions[i] = &models.Session{
+			TutorID:    tutor.ID,
+			ClientID:   client.ID,
+			Subject:    "Math",
+			StartsAt:   start,
+			EndsAt:     start.Add(time.Hour),
+			Status:     models.SessionStatusConfirmed,
+			HourlyRate: 4000,
+		}
+		if err := store.sessions.Create(sessions[i]); err != nil {
+			t.Fatalf("Create session: %v", err)
+		}
+	}
+
+	moderator := func(c *gin.Context) { c.Set(middleware.AdminEmailKey, "admin@example.com") }
+	router := gin.New()
+	router.POST("/api/sessions/:id/review", signedIn(client.Email), h.ReviewSession)
+	router.POST("/api/admin/reviews/:id/approve", moderator, h.ApproveReview)
+	router.POST("/api/admin/reviews/:id/reject", moderator, h.RejectReview)
+	router.POST("/api/admin/reviews/:id/redact", moderator, h.RedactReview)
+	router.GET("/api/tutors/:id/reviews", h.GetTutorReviews)
+
+	// rating checks the tutor's aggregate after each step
+	rating := func(step string, want float64, count int) {
+		t.Helper()
+		stored, err := store.tutors.GetByID(tutor.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if stored.Rating != want || stored.ReviewCount != count {
+			t.Errorf("after %s rating = %v over %d reviews, want %v over %d", step, stored.Rating, stored.ReviewCount, want, count)
+		}
+	}
+
+	path := fmt.Sprintf("/api/sessions/%d/review", sessions[0].ID)
+	status, body := serve(t, router, http.MethodPost, path, gin.H{"rating": 5, "comment": "Clear and patient"})
+	if status != http.StatusCreated || body["data"].(map[string]any)["status"] != models.ReviewStatusPublished {
+		t.Fatalf("POST %s = %d %v, want a published review", path, status, body)
+	}
+	rating("a published review", models.BayesianRating(1, 5), 1)
+
+	path = fmt.Sprintf("/api/sessions/%d/review", sessions[1].ID)
+	status, body = serve(t, router, http.MethodPost, path, gin.H{"rating": 1, "comment": "A scam"})
+	held := body["data"].(map[string]any)
+	if status != http.StatusCreated || held["status"] != models.ReviewStatusPending || held["hold_reason"] != "contains scam" {
+		t.Fatalf("POST %s = %d %v, want a review held for scam", path, status, body)
+	}
+	rating("a held review", models.BayesianRating(1, 5), 1)
+	heldID := int(held["id"].(float64))
+
+	steps := []struct {
+		action   string
+		status   string
+		comment  string
+		redacted bool
+		rating   float64
+		count    int
+	}{
+		{"approve", models.ReviewStatusPublished, "A scam", false, models.BayesianRating(2, 6), 2},
+		{"reject", models.ReviewStatusRejected, "A scam", false, models.BayesianRating(1, 5), 1},
+		{"redact", models.ReviewStatusPublished, "", true, models.BayesianRating(2, 6), 2},
+	}
+	for _, step := range steps {
+		path := fmt.Sprintf("/api/admin/reviews/%d/%s", heldID, step.action)
+		status, body := serve(t, router, http.MethodPost, path, nil)
+		if status != http.StatusOK {
+			t.Fatalf("POST %s status = %d: %v", path, status, body)
+		}
+		review, err := store.reviews.GetByID(heldID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if review.Status != step.status || review.Comment != step.comment || review.Redacted != step.redacted ||
+			review.ModeratedBy != "admin@example.com" || review.ModeratedAt == nil {
+			t.Errorf("after %s review = %+v", step.action, review)
+		}
+		rating(step.action, step.rating, step.count)
+	}
+
+	// The tutor's profile shows both reviews without who wrote them or how
+	// they were moderated
+	path = fmt.Sprintf("/api/tutors/%d/reviews", tutor.ID)
+	status, body = serve(t, router, http.MethodGet, path, nil)
+	published, _ := body["data"].([]any)
+	if status != http.StatusOK || len(published) != 2 {
+		t.Fatalf("GET %s = %d %v, want two reviews", path, status, body)
+	}
+	for _, review := range published {
+		for _, field := range []string{"client_id", "hold_reason", "moderated_by", "moderated_at"} {
+			if value, ok := review.(map[string]any)[field]; ok {
+				t.Errorf("GET %s shows %s %v", path, field, value)
+			}
+		}
+	}
+
+	path = "/api/admin/reviews/999/approve"
+	if status, body := serve(t, router, http.MethodPost, path, nil); status != http.StatusNotFound {
+		t.Errorf("POST %s status = %d, want 404: %v", path, status, body)
+	}
+	entries, err := store.audit.List(models.AuditFilter{EntityType: "review"})
+	if err != nil || len(entries.Entries) != len(steps) {
+		t.Errorf("audit entries = %+v, %v; want one per decision", entries, err)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"tutor-backend/middleware"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetRoles handles GET /api/admin/roles
+func (h *Handler) GetRoles(c *gin.Context) {
+	roles, err := h.roles.Roles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve roles",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    roles,
+		"message": "Roles retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetUsers handles GET /api/admin/users
+func (h *Handler) GetUsers(c *gin.Context) {
+	users, err := h.roles.Users()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve users",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    users,
+		"message": "Users retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GrantRole handles PUT /api/admin/users/:email/roles/:role
+func (h *Handler) GrantRole(c *gin.Context) {
+	email, role := c.Param("email"), c.Param("role")
+
+	audit := h.changeAudit(c, func(any) []models.AuditChange {
+		return []models.AuditChange{{Action: models.AuditActionCreate, EntityType: "user_role", EntityID: email, After: gin.H{"email": email, "role": role}}}
+	})
+	if err := h.roles.Grant(email, role, c.GetString(middleware.AdminEmailKey), audit); err != nil {
+		if errors.Is(err, models.ErrUnknownRole) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "No role found with the given name",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to grant role",
+			"status":  "error",
+		})
+		return
+	}
+
+	h.renderUserAccess(c, email, "Role granted successfully")
+}
+
+// RevokeRole handles DELETE /api/admin/users/:email/roles/:role
+func (h *Handler) RevokeRole(c *gin.Context) {
+	email, role := c.Param("email"), c.Param("role")
+
+	audit := h.auditChange(c, models.AuditActionDelete, "user_role", email, gin.H{"email": email, "role": role})
+	if err := h.roles.Revoke(email, role, audit); err != nil {
+		switch {
+		case errors.Is(err, models.ErrUnknownRole):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   err.Error(),
+				"message": "No role found with the given name",
+				"status":  "error",
+			})
+		case errors.Is(err, models.ErrLastAdmin):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   err.Error(),
+				"message": "Grant admin to someone else first",
+				"status":  "error",
+			})
+		case err == pgx.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Role not held",
+				"message": "The user does not have this role",
+				"status":  "error",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to revoke role",
+				"status":  "error",
+			})
+		}
+		return
+	}
+
+	h.renderUserAccess(c, email, "Role revoked successfully")
+}
+
+// GetMyAccess handles GET /api/me/access
+func (h *Handler) GetMyAccess(c *gin.Context) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+	h.renderUserAccess(c, email, "Access retrieved successfully")
+}
+
+// renderUserAccess responds with the roles and permissions held by an email
+func (h *Handler) renderUserAccess(c *gin.Context, email, message string) {
+	access, err := h.roles.Access(email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve roles",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    access,
+		"message": message,
+		"status":  "success",
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseTutorFilter reads the tutor search query parameters:
+//
+//	q              full-text search over bio, experience and certification
+//	subject        repeatable, or a comma-separated "subjects" list
+//	subject_match  "any" (default) or "all"
+//	min_pay, max_pay, min_rating
+//	language, location, education
+//	available_at   a grid slot such as "Tue-3:00 PM", read in "tz"
+//	sort           newest, oldest, pay_asc, pay_desc, rating, name or relevance
+//	cursor, limit  pagination
+func parseTutorFilter(c *gin.Context) (models.TutorFilter, error) {
+	filter := models.TutorFilter{
+		Query:     strings.TrimSpace(c.Query("q")),
+		Subjects:  querySubjects(c),
+		Language:  strings.TrimSpace(c.Query("language")),
+		Location:  strings.TrimSpace(c.Query("location")),
+		Education: strings.TrimSpace(c.Query("education")),
+		Sort:      c.Query("sort"),
+		Cursor:    c.Query("cursor"),
+	}
+
+	switch c.DefaultQuery("subject_match", "any") {
+	case "any":
+	case "all":
+		filter.MatchAll = true
+	default:
+		return filter, fmt.Errorf("subject_match must be \"any\" or \"all\"")
+	}
+
+	var err error
+	if filter.MinPay, err = queryFloat(c, "min_pay"); err != nil {
+		return filter, err
+	}
+	if filter.MaxPay, err = queryFloat(c, "max_pay"); err != nil {
+		return filter, err
+	}
+	if filter.MinRating, err = queryFloat(c, "min_rating"); err != nil {
+		return filter, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return filter, err
+	}
+
+	if filter.Limit, err = parseLimit(c.Query("limit")); err != nil {
+		return filter, err
+	}
+
+	if slot := c.Query("available_at"); slot != "" {
+		at, err := parseSlotQuery(slot, c.Query("tz"))
+		if err != nil {
+			return filter, err
+		}
+		filter.AvailableAt = &at
+	}
+
+	return filter, nil
+}
+
+// querySubjects collects subjects from repeated "subject" and comma-separated "subjects" parameters
+func querySubjects(c *gin.Context) []string {
+	subjects := c.QueryArray("subject")
+	if list := c.Query("subjects"); list != "" {
+		subjects = append(subjects, strings.Split(list, ",")...)
+	}
+	return subjects
+}
+
+// queryFloat parses an optional numeric query parameter
+func queryFloat(c *gin.Context, name string) (*float64, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", name)
+	}
+	return &parsed, nil
+}
+
+// parseSlotQuery resolves a grid slot ID in the caller's timezone to that moment this week
+func parseSlotQuery(slotID, timezone string) (time.Time, error) {
+	slot, err := models.ParseSlotID(slotID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return models.WeekInstant(loc, time.Now(), slot.Day(), slot.Start%models.MinutesPerDay), nil
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// searchFixture stores a small tutor directory and returns the router
+// serving it
+func searchFixture(t *testing.T) *gin.Engine {
+	t.Helper()
+	h, store := newTestHandler(nil)
+	tutors := []models.Tutor{
+		{Name: "Ada", Subjects: []string{"Math", "Physics"}, Pay: 30, Language: "English", Education: "Master's Degree", Bio: "Calculus and physics for exams"},
+		{Name: "alan", Subjects: []string{"Calculus"}, Pay: 60, Language: "English", Education: "PhD", Bio: "Proof-based mathematics"},
+		{Name: "Grace", Subjects: []string{"Chemistry"}, Pay: 45, Language: "Spanish", Education: "Bachelor's Degree", Bio: "Organic chemistry lab skills"},
+		{Name: "Hopper", Subjects: []string{"Physics", "Chemistry"}, Pay: 80, Language: "English", Education: "Master's Degree", Bio: "Physics labs and physics olympiad coaching"},
+		{Name: "Emmy", Subjects: []string{"Algebra", "Physics"}, Pay: 30, Language: "German", Education: "PhD", Bio: "Abstract algebra with a little physics"},
+		{Name: "Marie", Subjects: []string{"Biology"}, Pay: 110, Language: "French", Education: "PhD"},
+	}
+	for i := range tutors {
+		tutors[i].Email = tutors[i].Name + "@example.com"
+		if err := store.tutors.Create(&tutors[i]); err != nil {
+			t.Fatalf("Create tutor: %v", err)
+		}
+	}
+
+	router := gin.New()
+	router.GET("/api/tutors", h.GetTutors)
+	router.GET("/api/tutors/facets", h.GetTutorFacets)
+	return router
+}
+
+// tutorNames returns the names of the tutors in a search response, in order
+func tutorNames(body map[string]any) []string {
+	names := []string{}
+	for _, tutor := range body["data"].([]any) {
+		names = append(names, tutor.(map[string]any)["name"].(string))
+	}
+	return names
+}
+
+func TestSearchTutorsFilters(t *testing.T) {
+	router := searchFixture(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"subject with descendants", "subject=Mathematics", []string{"Ada", "Emmy", "alan"}},
+		{"any subject", "subject=Maths&subject=Physics", []string{"Ada", "Emmy", "Hopper", "alan"}},
+		{"all subjects", "subjects=Mathematics,Physics&subject_match=all", []string{"Ada", "Emmy"}},
+		{"pay range", "min_pay=40&max_pay=80", []string{"Grace", "Hopper", "alan"}},
+		{"language", "language=english", []string{"Ada", "Hopper", "alan"}},
+		{"education and subject", "education=phd&subject=Physics", []string{"Emmy"}},
+		{"full text", "q=physics", []string{"Ada", "Emmy", "Hopper"}},
+		{"full text and subject", "q=lab&subject=Chemistry", []string{"Grace", "Hopper"}},
+		{"nothing matches", "subject=Geometry", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := serve(t, router, http.MethodGet, "/api/tutors?"+tt.query, nil)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d: %v", status, body)
+			}
+			names := tutorNames(body)
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("tutors = %q, want %q", names, tt.want)
+			}
+			if total := body["meta"].(map[string]any)["total"]; int(total.(float64)) != len(tt.want) {
+				t.Errorf("total = %v, want %d", total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSearchTutorsPagesEverySort(t *testing.T) {
+	router := searchFixture(t)
+
+	// Ties are broken by ID in the sort's direction
+	tests := []struct {
+		sort  string
+		query string
+		want  []string
+	}{
+		{"newest", "", []string{"Marie", "Emmy", "Hopper", "Grace", "alan", "Ada"}},
+		{"oldest", "", []string{"Ada", "alan", "Grace", "Hopper", "Emmy", "Marie"}},
+		{"pay_asc", "", []string{"Ada", "Emmy", "Grace", "alan", "Hopper", "Marie"}},
+		{"pay_desc", "", []string{"Marie", "Hopper", "alan", "Grace", "Emmy", "Ada"}},
+		{"rating", "", []string{"Marie", "Emmy", "Hopper", "Grace", "alan", "Ada"}},
+		{"name", "", []string{"Ada", "alan", "Emmy", "Grace", "Hopper", "Marie"}},
+		{"relevance", "physics", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			query := url.Values{"sort": {tt.sort}}
+			if tt.query != "" {
+				query.Set("q", tt.query)
+			}
+			if tt.want == nil {
+				// The order of a single page is the reference
+				status, body := serve(t, router, http.MethodGet, "/api/tutors?"+query.Encode(), nil)
+				if status != http.StatusOK {
+					t.Fatalf("status = %d: %v", status, body)
+				}
+				tt.want = tutorNames(body)
+			}
+
+			query.Set("limit", "2")
+			paged := []string{}
+			for pages := 1; ; pages++ {
+				status, body := serve(t, router, http.MethodGet, "/api/tutors?"+query.Encode(), nil)
+				if status != http.StatusOK {
+					t.Fatalf("page %d status = %d: %v", pages, status, body)
+				}
+				names := tutorNames(body)
+				if len(names) > 2 {
+					t.Fatalf("page %d has %d tutors, over the limit", pages, len(names))
+				}
+				paged = append(paged, names...)
+				meta := body["meta"].(map[string]any)
+				if int(meta["total"].(float64)) != len(tt.want) {
+					t.Errorf("page %d total = %v, want %d", pages, meta["total"], len(tt.want))
+				}
+				next, _ := meta["next_cursor"].(string)
+				if next == "" {
+					break
+				}
+				if pages > len(tt.want) {
+					t.Fatalf("paging did not end after %d pages", pages)
+				}
+				query.Set("cursor", next)
+			}
+			if !reflect.DeepEqual(paged, tt.want) {
+				t.Errorf("pages = %q, want %q", paged, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTutorsRejectsInvalidParameters(t *testing.T) {
+	router := searchFixture(t)
+	notJSON := base64.RawURLEncoding.EncodeToString([]byte("not json"))
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"unknown sort", "sort=popularity", `unsupported sort "popularity"`},
+		{"relevance without a query", "sort=relevance", "sorting by relevance requires a search query"},
+		{"cursor that is not base64", "cursor=%21%21%21", "invalid cursor"},
+		{"cursor that is not JSON", "cursor=" + notJSON, "invalid cursor"},
+		{"unknown subject match", "subject=Math&subject_match=most", `subject_match must be "any" or "all"`},
+		{"pay that is not a number", "min_pay=cheap", "min_pay must be a number"},
+	}
+
+	for _, tt := range tests {
+		for _, path := range []string{"/api/tutors", "/api/tutors/facets"} {
+			status, body := serve(t, router, http.MethodGet, path+"?"+tt.query, nil)
+			if status != http.StatusBadRequest || body["error"] != tt.want {
+				t.Errorf("%s: GET %s = %d %v, want 400 %q", tt.name, path, status, body, tt.want)
+			}
+		}
+	}
+}
+
+func TestTutorFacetsIgnoreTheirOwnFilter(t *testing.T) {
+	router := searchFixture(t)
+
+	status, body := serve(t, router, http.MethodGet, "/api/tutors/facets?subject=Physics&language=English&max_pay=100", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d: %v", status, body)
+	}
+	facets := body["data"].(map[string]any)
+	counts := func(facet string) map[string]int {
+		got := map[string]int{}
+		for _, count := range facets[facet].([]any) {
+			count := count.(map[string]any)
+			if n := int(count["count"].(float64)); n > 0 {
+				got[count["value"].(string)] = n
+			}
+		}
+		return got
+	}
+
+	tests := []struct {
+		facet string
+		want  map[string]int
+	}{
+		// English tutors up to $100, whatever they teach
+		{"subjects", map[string]int{"Mathematics": 1, "Physics": 2, "Calculus": 1, "Chemistry": 1}},
+		// Physics tutors up to $100, whatever language they speak
+		{"languages", map[string]int{"English": 2, "German": 1}},
+		// Every filter applies to the other facets
+		{"education", map[string]int{"Master's Degree": 2}},
+		// English physics tutors at any pay
+		{"pay_brackets", map[string]int{"25-50": 1, "75-100": 1}},
+		{"rating_brackets", map[string]int{"0-3": 2}},
+	}
+	for _, tt := range tests {
+		if got := counts(tt.facet); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.facet, got, tt.want)
+		}
+	}
+
+	// The search response carries the same counts
+	status, search := serve(t, router, http.MethodGet, "/api/tutors?subject=Physics&language=English&max_pay=100&facets=true", nil)
+	if status != http.StatusOK || !reflect.DeepEqual(search["facets"], body["data"]) {
+		t.Errorf("search facets = %v, want %v", search["facets"], body["data"])
+	}
+}
+
+func TestSearchClients(t *testing.T) {
+	h, store := newTestHandler(nil)
+	clients := []models.Client{
+		{Name: "Grace", Description: "Needs help with calculus homework"},
+		{Name: "Hopper", Description: "Preparing for a calculus exam, then calculus II"},
+		{Name: "Alan", Description: "Wants to learn chemistry"},
+	}
+	for i := range clients {
+		clients[i].Email = clients[i].Name + "@example.com"
+		if err := store.clients.Create(&clients[i]); err != nil {
+			t.Fatalf("Create client: %v", err)
+		}
+	}
+	if err := store.clients.Delete(clients[0].ID, nil); err != nil {
+		t.Fatalf("Delete client: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/api/clients", h.GetClients)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"q=calculus", []string{"Hopper"}},
+		{"q=chemistry+OR+calculus", []string{"Hopper", "Alan"}},
+		{"q=calculus+-exam", []string{}},
+		{"q=chemistry&limit=1", []string{"Alan"}},
+	}
+	for _, tt := range tests {
+		status, body := serve(t, router, http.MethodGet, "/api/clients?"+tt.query, nil)
+		if status != http.StatusOK {
+			t.Fatalf("GET %s status = %d: %v", tt.query, status, body)
+		}
+		names := tutorNames(body)
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("GET /api/clients?%s = %q, want %q", tt.query, names, tt.want)
+		}
+	}
+}
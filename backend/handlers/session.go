@@ -0,0 +1,565 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionRequest is a client's request to book a session with a tutor
+type SessionRequest struct {
+	TutorID  int       `json:"tutor_id" binding:"required"`
+	Subject  string    `json:"subject" binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+// SessionRescheduleRequest moves a session to a new time
+type SessionRescheduleRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+// SessionCancelRequest cancels a session, optionally saying why
+type SessionCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+// profilesForEmail returns the user's tutor and client profiles, either of
+// which may be nil
+func (h *Handler) profilesForEmail(email string) (*models.Tutor, *models.Client, error) {
+	tutor, err := h.tutors.GetByEmail(email)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, nil, err
+	}
+
+	client, err := h.clients.GetByEmail(email)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, nil, err
+	}
+
+	return tutor, client, nil
+}
+
+// callerProfiles returns the signed-in user's tutor and client profiles,
+// either of which may be nil. It responds itself when it returns false.
+func (h *Handler) callerProfiles(c *gin.Context) (*models.Tutor, *models.Client, bool) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	tutor, client, err := h.profilesForEmail(email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve profiles",
+			"status":  "error",
+		})
+		return nil, nil, false
+	}
+
+	return tutor, client, true
+}
+
+// participantRole returns the caller's part in a booking between tutorID and
+// clientID, models.RoleTutor or models.RoleClient, or "" if they take no part
+func participantRole(tutor *models.Tutor, client *models.Client, tutorID, clientID int) string {
+	switch {
+	case tutor != nil && tutor.ID == tutorID:
+		return models.RoleTutor
+	case client != nil && client.ID == clientID:
+		return models.RoleClient
+	}
+	return ""
+}
+
+// sessionForCaller loads the session named by the :id parameter along with
+// the caller's part in it, models.RoleTutor or models.RoleClient. Sessions
+// the caller is not part of are reported as not found.
+func (h *Handler) sessionForCaller(c *gin.Context) (*models.Session, string, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid session ID",
+			"message": "Session ID must be a number",
+			"status":  "error",
+		})
+		return nil, "", false
+	}
+
+	tutor, client, ok := h.callerProfiles(c)
+	if !ok {
+		return nil, "", false
+	}
+
+	session, err := h.sessions.GetByID(id)
+	if err != nil && err != pgx.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve session",
+			"status":  "error",
+		})
+		return nil, "", false
+	}
+
+	if session != nil {
+		if role := participantRole(tutor, client, session.TutorID, session.ClientID); role != "" {
+			return session, role, true
+		}
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{
+		"error":   "Session not found",
+		"message": "No session found with the given ID",
+		"status":  "error",
+	})
+	return nil, "", false
+}
+
+// bookingProblem returns models.ErrOutsideAvailability, models.ErrTutorBusy
+// or models.ErrSessionConflict when the tutor cannot take the session at its
+// time, or an error if the check itself failed
+func (h *Handler) bookingProblem(tutor *models.Tutor, session *models.Session) (problem error, err error) {
+	if err := models.CheckTutorAvailability(tutor, session.StartsAt, session.EndsAt); err != nil {
+		if err == models.ErrOutsideAvailability {
+			return err, nil
+		}
+		return nil, err
+	}
+
+	exceptions, err := h.tutors.AvailabilityExceptions([]int{tutor.ID}, session.StartsAt, session.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(exceptions[tutor.ID]) > 0 {
+		return models.ErrTutorBusy, nil
+	}
+
+	conflicts, err := h.sessions.Conflicts(session.TutorID, session.ClientID, session.StartsAt, session.EndsAt, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return models.ErrSessionConflict, nil
+	}
+	return nil, nil
+}
+
+// checkSessionTime validates a session's time against the rules for
+// bookings, the tutor's weekly availability and busy times, and the
+// confirmed sessions of both participants. It responds itself when it returns false.
+func (h *Handler) checkSessionTime(c *gin.Context, tutor *models.Tutor, session *models.Session) bool {
+	if err := models.ValidateSessionTime(session.StartsAt, session.EndsAt, time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid session time",
+			"status":  "error",
+		})
+		return false
+	}
+
+	problem, err := h.bookingProblem(tutor, session)
+	switch {
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to check the session time",
+			"status":  "error",
+		})
+		return false
+	case problem == models.ErrOutsideAvailability:
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   problem.Error(),
+			"message": "Session is outside the tutor's availability",
+			"status":  "error",
+		})
+		return false
+	case problem == models.ErrTutorBusy:
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   problem.Error(),
+			"message": "The tutor is busy at that time",
+			"status":  "error",
+		})
+		return false
+	case problem != nil:
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   problem.Error(),
+			"message": "Session overlaps a confirmed session",
+			"status":  "error",
+		})
+		return false
+	}
+
+	return true
+}
+
+// bookingParties resolves who a new booking is between: the signed-in client
+// and the tutor with tutorID, who must teach the subject. It also returns the
+// subject's canonical name, and responds itself when it returns false.
+func (h *Handler) bookingParties(c *gin.Context, tutorID int, subject string) (*models.Tutor, *models.Client, string, bool) {
+	_, client, ok := h.callerProfiles(c)
+	if !ok {
+		return nil, nil, "", false
+	}
+	if client == nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "No client profile for this account",
+			"message": "Only clients can request sessions",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+
+	tutor, err := h.tutors.GetByID(tutorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No tutor found with the given ID",
+				"status":  "error",
+			})
+			return nil, nil, "", false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+	if tutor.Email == client.Email {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Cannot book a session with yourself",
+			"message": "Invalid session request",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+
+	taxonomy, err := h.subjects.Taxonomy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to load subject taxonomy",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+	if !tutor.Teaches(taxonomy, subject) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "The tutor does not teach " + taxonomy.Canonical(subject),
+			"message": "Invalid session request",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+
+	return tutor, client, taxonomy.Canonical(subject), true
+}
+
+// respondSessionWriteError responds to a failed session write
+func respondSessionWriteError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, models.ErrSessionConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   err.Error(),
+			"message": "Session overlaps a confirmed session",
+			"status":  "error",
+		})
+	case err == pgx.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Session not found",
+			"message": "No session found with the given ID",
+			"status":  "error",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": message,
+			"status":  "error",
+		})
+	}
+}
+
+// ListSessions handles GET /api/sessions
+// Lists the caller's sessions as tutor and as client. Filters: status
+// (comma-separated), from, to, and role=tutor|client.
+func (h *Handler) ListSessions(c *gin.Context) {
+	tutor, client, ok := h.callerProfiles(c)
+	if !ok {
+		return
+	}
+
+	filter := models.SessionFilter{}
+	role := c.Query("role")
+	if tutor != nil && role != models.RoleClient {
+		filter.TutorID = tutor.ID
+	}
+	if client != nil && role != models.RoleTutor {
+		filter.ClientID = client.ID
+	}
+	if status := c.Query("status"); status != "" {
+		filter.Statuses = strings.Split(status, ",")
+	}
+
+	var err error
+	if role != "" && role != models.RoleTutor && role != models.RoleClient {
+		err = errors.New("role must be \"tutor\" or \"client\"")
+	}
+	if err == nil {
+		filter.From, err = parseTimeQuery(c.Query("from"), false)
+	}
+	if err == nil {
+		filter.To, err = parseTimeQuery(c.Query("to"), true)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid session filters",
+			"status":  "error",
+		})
+		return
+	}
+
+	sessions := []models.Session{}
+	if filter.TutorID != 0 || filter.ClientID != 0 {
+		if sessions, err = h.sessions.List(filter); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to retrieve sessions",
+				"status":  "error",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    sessions,
+		"message": "Sessions retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetSession handles GET /api/sessions/:id
+func (h *Handler) GetSession(c *gin.Context) {
+	session, _, ok := h.sessionForCaller(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    session,
+		"message": "Session retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// RequestSession handles POST /api/sessions
+// The signed-in client asks a tutor for a session, which the tutor then confirms.
+func (h *Handler) RequestSession(c *gin.Context) {
+	var request SessionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid session request",
+			"status":  "error",
+		})
+		return
+	}
+
+	tutor, client, subject, ok := h.bookingParties(c, request.TutorID, request.Subject)
+	if !ok {
+		return
+	}
+
+	session := models.Session{
+		TutorID:    tutor.ID,
+		ClientID:   client.ID,
+		Subject:    subject,
+		StartsAt:   request.StartsAt,
+		EndsAt:     request.EndsAt,
+		Status:     models.SessionStatusRequested,
+		HourlyRate: models.MinorUnits(tutor.Pay),
+	}
+	if !h.checkSessionTime(c, tutor, &session) {
+		return
+	}
+
+	if err := h.sessions.Create(&session); err != nil {
+		respondSessionWriteError(c, err, "Failed to request session")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    session,
+		"message": "Session requested successfully",
+		"status":  "success",
+	})
+}
+
+// ConfirmSession handles POST /api/sessions/:id/confirm
+// Only the session's tutor can confirm, and only while it is still requested.
+func (h *Handler) ConfirmSession(c *gin.Context) {
+	session, role, ok := h.sessionForCaller(c)
+	if !ok {
+		return
+	}
+
+	var problem string
+	switch {
+	case role != models.RoleTutor:
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Only the tutor can confirm a session",
+			"message": "Not allowed to confirm this session",
+			"status":  "error",
+		})
+		return
+	case session.Status != models.SessionStatusRequested:
+		problem = "session is " + session.Status
+	case !session.StartsAt.After(time.Now()):
+		problem = "session has already started"
+	}
+	if problem != "" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   problem,
+			"message": "Session cannot be confirmed",
+			"status":  "error",
+		})
+		return
+	}
+
+	// The repository rejects the confirmation if another one took the time first
+	session.Status = models.SessionStatusConfirmed
+	if err := h.sessions.Update(session); err != nil {
+		respondSessionWriteError(c, err, "Failed to confirm session")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    session,
+		"message": "Session confirmed successfully",
+		"status":  "success",
+	})
+}
+
+// RescheduleSession handles POST /api/sessions/:id/reschedule
+// Either participant can move a requested or confirmed session. A confirmed
+// session moved by the client goes back to requested for the tutor to confirm.
+func (h *Handler) RescheduleSession(c *gin.Context) {
+	var request SessionRescheduleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid reschedule request",
+			"status":  "error",
+		})
+		return
+	}
+
+	session, role, ok := h.sessionForCaller(c)
+	if !ok {
+		return
+	}
+	var problem string
+	switch {
+	case session.Status == models.SessionStatusCancelled:
+		problem = "session is cancelled"
+	case !session.StartsAt.After(time.Now()):
+		problem = "session has already started"
+	}
+	if problem != "" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   problem,
+			"message": "Session cannot be rescheduled",
+			"status":  "error",
+		})
+		return
+	}
+
+	tutor, err := h.tutors.GetByID(session.TutorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	session.StartsAt, session.EndsAt = request.StartsAt, request.EndsAt
+	if !h.checkSessionTime(c, tutor, session) {
+		return
+	}
+	if role == models.RoleClient {
+		session.Status = models.SessionStatusRequested
+	}
+
+	if err := h.sessions.Update(session); err != nil {
+		respondSessionWriteError(c, err, "Failed to reschedule session")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    session,
+		"message": "Session rescheduled successfully",
+		"status":  "success",
+	})
+}
+
+// CancelSession handles POST /api/sessions/:id/cancel
+// Either participant can cancel a session that has not started yet.
+func (h *Handler) CancelSession(c *gin.Context) {
+	var request SessionCancelRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   err.Error(),
+				"message": "Invalid cancellation",
+				"status":  "error",
+			})
+			return
+		}
+	}
+
+	session, role, ok := h.sessionForCaller(c)
+	if !ok {
+		return
+	}
+
+	var problem string
+	switch {
+	case session.Status == models.SessionStatusCancelled:
+		problem = "session is already cancelled"
+	case !session.StartsAt.After(time.Now()):
+		problem = "session has already started"
+	}
+	if problem != "" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   problem,
+			"message": "Session cannot be cancelled",
+			"status":  "error",
+		})
+		return
+	}
+
+	session.Status = models.SessionStatusCancelled
+	session.CancelledBy = role
+	session.CancelReason = strings.TrimSpace(request.Reason)
+	if err := h.sessions.Update(session); err != nil {
+		respondSessionWriteError(c, err, "Failed to cancel session")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    session,
+		"message": "Session cancelled successfully",
+		"status":  "success",
+	})
+}
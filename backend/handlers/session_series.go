@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// SeriesRequest is a client's request to book a weekly recurring session.
+// StartsAt and EndsAt give the first occurrence; RRule repeats it, e.g.
+// "FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20261218". Timezone keeps the wall-clock
+// time steady across DST and defaults to the client's timezone.
+type SeriesRequest struct {
+	TutorID  int       `json:"tutor_id" binding:"required"`
+	Subject  string    `json:"subject" binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	RRule    string    `json:"rrule" binding:"required"`
+	Timezone string    `json:"timezone"`
+}
+
+// OccurrenceProblem explains why one occurrence of a series cannot be booked
+type OccurrenceProblem struct {
+	OccurrenceStart time.Time `json:"occurrence_start"`
+	Error           string    `json:"error"`
+}
+
+// respondOccurrenceProblems reports the occurrences that stop a series from
+// being booked or confirmed
+func respondOccurrenceProblems(c *gin.Context, problems []OccurrenceProblem, message string) {
+	c.JSON(http.StatusConflict, gin.H{
+		"data":    problems,
+		"error":   fmt.Sprintf("%d of the occurrences cannot be booked", len(problems)),
+		"message": message,
+		"status":  "error",
+	})
+}
+
+// seriesForCaller loads the series named by the :id parameter, its
+// occurrences and the caller's part in it. Series the caller is not part of
+// are reported as not found.
+func (h *Handler) seriesForCaller(c *gin.Context) (*models.SessionSeries, []models.Session, string, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid series ID",
+			"message": "Series ID must be a number",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+
+	tutor, client, ok := h.callerProfiles(c)
+	if !ok {
+		return nil, nil, "", false
+	}
+
+	series, err := h.sessions.GetSeries(id)
+	if err != nil && err != pgx.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve series",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+
+	role := ""
+	if series != nil {
+		role = participantRole(tutor, client, series.TutorID, series.ClientID)
+	}
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Series not found",
+			"message": "No series found with the given ID",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+
+	occurrences, err := h.sessions.List(models.SessionFilter{SeriesID: series.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve series sessions",
+			"status":  "error",
+		})
+		return nil, nil, "", false
+	}
+
+	return series, occurrences, role, true
+}
+
+// seriesOverlaps warns about the occurrences that overlap a requested
+// occurrence of another of the tutor's series. Such overlaps do not block a
+// request, as the tutor can confirm only one of the series, but the client
+// should know about them when booking.
+func (h *Handler) seriesOverlaps(tutorID int, occurrences []models.Session) ([]OccurrenceProblem, error) {
+	warnings := []OccurrenceProblem{}
+	if len(occurrences) == 0 {
+		return warnings, nil
+	}
+
+	from, to := occurrences[0].StartsAt, occurrences[0].EndsAt
+	for _, occurrence := range occurrences {
+		if occurrence.StartsAt.Before(from) {
+			from = occurrence.StartsAt
+		}
+		if occurrence.EndsAt.After(to) {
+			to = occurrence.EndsAt
+		}
+	}
+	requested, err := h.sessions.List(models.SessionFilter{
+		TutorID:  tutorID,
+		Statuses: []string{models.SessionStatusRequested},
+		From:     &from,
+		To:       &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, occurrence := range occurrences {
+		for _, other := range requested {
+			if other.SeriesID != nil && other.Overlaps(occurrence.StartsAt, occurrence.EndsAt) {
+				warnings = append(warnings, OccurrenceProblem{
+					OccurrenceStart: occurrence.StartsAt,
+					Error:           fmt.Sprintf("overlaps a requested occurrence of the tutor's series %d", *other.SeriesID),
+				})
+				break
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// RequestSeries handles POST /api/sessions/series
+// Expands the rule into requested sessions, checking every occurrence against
+// the tutor's availability and the confirmed sessions of both participants.
+// Occurrences overlapping the tutor's other requested series are booked but
+// returned as warnings.
+// Occurrences are ordinary sessions afterwards: skip one with
+// /api/sessions/:id/cancel or move it with /api/sessions/:id/reschedule.
+func (h *Handler) RequestSeries(c *gin.Context) {
+	var request SeriesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid series request",
+			"status":  "error",
+		})
+		return
+	}
+
+	tutor, client, subject, ok := h.bookingParties(c, request.TutorID, request.Subject)
+	if !ok {
+		return
+	}
+
+	timezone := request.Timezone
+	if strings.TrimSpace(timezone) == "" {
+		timezone = client.Timezone
+	}
+	series := models.SessionSeries{
+		TutorID:  tutor.ID,
+		ClientID: client.ID,
+		Subject:  subject,
+		StartsAt: request.StartsAt,
+		EndsAt:   request.EndsAt,
+	}
+	var occurrences []models.Session
+	loc, err := models.LoadTimezone(timezone)
+	if err == nil {
+		series.Timezone = loc.String()
+		err = models.ValidateSessionTime(request.StartsAt, request.EndsAt, time.Now())
+	}
+	if err == nil {
+		var rule *models.RecurrenceRule
+		if rule, err = models.ParseRecurrenceRule(request.RRule); err == nil {
+			series.Rule = rule.String()
+			occurrences, err = series.Expand()
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid series request",
+			"status":  "error",
+		})
+		return
+	}
+
+	problems := []OccurrenceProblem{}
+	for i := range occurrences {
+		occurrences[i].HourlyRate = models.MinorUnits(tutor.Pay)
+		problem, err := h.bookingProblem(tutor, &occurrences[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to check the series times",
+				"status":  "error",
+			})
+			return
+		}
+		if problem != nil {
+			problems = append(problems, OccurrenceProblem{OccurrenceStart: occurrences[i].StartsAt, Error: problem.Error()})
+		}
+	}
+	if len(problems) > 0 {
+		respondOccurrenceProblems(c, problems, "Some occurrences cannot be booked")
+		return
+	}
+
+	warnings, err := h.seriesOverlaps(tutor.ID, occurrences)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to check the tutor's other series",
+			"status":  "error",
+		})
+		return
+	}
+
+	if err := h.sessions.CreateSeries(&series, occurrences); err != nil {
+		respondSessionWriteError(c, err, "Failed to request series")
+		return
+	}
+
+	message := "Series requested successfully"
+	if len(warnings) > 0 {
+		message = "Series requested; some occurrences overlap the tutor's other requested series"
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    gin.H{"series": series, "sessions": occurrences, "warnings": warnings},
+		"message": message,
+		"status":  "success",
+	})
+}
+
+// GetSeries handles GET /api/sessions/series/:id
+// Returns the series with all of its occurrences, including moved and
+// cancelled ones.
+func (h *Handler) GetSeries(c *gin.Context) {
+	series, occurrences, _, ok := h.seriesForCaller(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    gin.H{"series": series, "sessions": occurrences},
+		"message": "Series retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// ConfirmSeries handles POST /api/sessions/series/:id/confirm
+// The tutor confirms every upcoming requested occurrence at once, or none of
+// them if any would overlap another confirmed session.
+func (h *Handler) ConfirmSeries(c *gin.Context) {
+	series, occurrences, role, ok := h.seriesForCaller(c)
+	if !ok {
+		return
+	}
+	if role != models.RoleTutor {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Only the tutor can confirm a series",
+			"message": "Not allowed to confirm this series",
+			"status":  "error",
+		})
+		return
+	}
+
+	now := time.Now()
+	pending := []models.Session{}
+	problems := []OccurrenceProblem{}
+	for _, occurrence := range occurrences {
+		if occurrence.Status != models.SessionStatusRequested || !occurrence.StartsAt.After(now) {
+			continue
+		}
+
+		conflicts, err := h.sessions.Conflicts(series.TutorID, series.ClientID, occurrence.StartsAt, occurrence.EndsAt, occurrence.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to check for conflicting sessions",
+				"status":  "error",
+			})
+			return
+		}
+		if len(conflicts) > 0 {
+			problems = append(problems, OccurrenceProblem{OccurrenceStart: occurrence.StartsAt, Error: models.ErrSessionConflict.Error()})
+		}
+
+		occurrence.Status = models.SessionStatusConfirmed
+		pending = append(pending, occurrence)
+	}
+	if len(problems) > 0 {
+		respondOccurrenceProblems(c, problems, "Series cannot be confirmed")
+		return
+	}
+	if len(pending) == 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "no upcoming occurrences are waiting for confirmation",
+			"message": "Series cannot be confirmed",
+			"status":  "error",
+		})
+		return
+	}
+
+	if err := h.sessions.UpdateMany(pending); err != nil {
+		respondSessionWriteError(c, err, "Failed to confirm series")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    gin.H{"series": series, "sessions": pending},
+		"message": "Series confirmed successfully",
+		"status":  "success",
+	})
+}
+
+// CancelSeries handles POST /api/sessions/series/:id/cancel
+// Either participant can cancel every upcoming occurrence; past ones are kept.
+func (h *Handler) CancelSeries(c *gin.Context) {
+	var request SessionCancelRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   err.Error(),
+				"message": "Invalid cancellation",
+				"status":  "error",
+			})
+			return
+		}
+	}
+
+	series, occurrences, role, ok := h.seriesForCaller(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	cancelled := []models.Session{}
+	for _, occurrence := range occurrences {
+		if occurrence.Status == models.SessionStatusCancelled || !occurrence.StartsAt.After(now) {
+			continue
+		}
+		occurrence.Status = models.SessionStatusCancelled
+		occurrence.CancelledBy = role
+		occurrence.CancelReason = strings.TrimSpace(request.Reason)
+		cancelled = append(cancelled, occurrence)
+	}
+	if len(cancelled) == 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "no upcoming occurrences left to cancel",
+			"message": "Series cannot be cancelled",
+			"status":  "error",
+		})
+		return
+	}
+
+	if err := h.sessions.UpdateMany(cancelled); err != nil {
+		respondSessionWriteError(c, err, "Failed to cancel series")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    gin.H{"series": series, "sessions": cancelled},
+		"message": "Series cancelled successfully",
+		"status":  "success",
+	})
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestSeriesWarnsAboutTutorsOtherSeries(t *testing.T) {
+	h, store := newTestHandler(nil)
+	tutor := &models.Tutor{
+		Name:         "Ada",
+		Email:        "ada@example.com",
+		Subjects:     []string{"Math"},
+		Pay:          40,
+		Availability: "every day 8am-8pm",
+		Timezone:     "UTC",
+	}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+	for _, email := range []string{"grace@example.com", "alan@example.com"} {
+		client := &models.Client{Name: email, Email: email, Subjects: []string{"Math"}, Timezone: "UTC"}
+		if err := store.clients.Create(client); err != nil {
+			t.Fatalf("Create client: %v", err)
+		}
+	}
+
+	router := gin.New()
+	router.POST("/grace/series", signedIn("grace@example.com"), h.RequestSeries)
+	router.POST("/alan/series", signedIn("alan@example.com"), h.RequestSeries)
+
+	day := time.Now().UTC().AddDate(0, 0, 7)
+	start := time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, time.UTC)
+	series := func(start time.Time) gin.H {
+		return gin.H{
+			"tutor_id":  tutor.ID,
+			"subject":   "Math",
+			"starts_at": start,
+			"ends_at":   start.Add(time.Hour),
+			"rrule":     "FREQ=WEEKLY;COUNT=3",
+		}
+	}
+
+	status, body := serve(t, router, http.MethodPost, "/grace/series", series(start))
+	if status != http.StatusCreated {
+		t.Fatalf("first series status = %d, want 201: %v", status, body)
+	}
+	if warnings := body["data"].(map[string]any)["warnings"].([]any); len(warnings) != 0 {
+		t.Errorf("first series warnings = %v, want none", warnings)
+	}
+
+	// Half an hour later on the same days overlaps every occurrence
+	status, body = serve(t, router, http.MethodPost, "/alan/series", series(start.Add(30*time.Minute)))
+	if status != http.StatusCreated {
+		t.Fatalf("overlapping series status = %d, want 201: %v", status, body)
+	}
+	if warnings := body["data"].(map[string]any)["warnings"].([]any); len(warnings) != 3 {
+		t.Errorf("overlapping series warnings = %v, want one per occurrence", warnings)
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSessionBookingConflicts(t *testing.T) {
+	h, store := newTestHandler(nil)
+	tutors := make([]*models.Tutor, 2)
+	for i, email := range []string{"ada@example.com", "alan@example.com"} {
+		tutors[i] = &models.Tutor{
+			Name:         email,
+			Email:        email,
+			Subjects:     []string{"Math"},
+			Pay:          40,
+			Availability: "every day 8am-8pm",
+			Timezone:     "UTC",
+		}
+		if err := store.tutors.Create(tutors[i]); err != nil {
+			t.Fatalf("Create tutor: %v", err)
+		}
+	}
+	clients := make([]*models.Client, 2)
+	for i, email := range []string{"grace@example.com", "hopper@example.com"} {
+		clients[i] = &models.Client{Name: email, Email: email, Subjects: []string{"Math"}, Timezone: "UTC"}
+		if err := store.clients.Create(clients[i]); err != nil {
+			t.Fatalf("Create client: %v", err)
+		}
+	}
+
+	day := time.Now().UTC().AddDate(0, 0, 7)
+	at := func(hour, minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.UTC)
+	}
+	// Ada teaches Grace from 10:00 to 11:00
+	confirmed := &models.Session{
+		TutorID:  tutors[0].ID,
+		ClientID: clients[0].ID,
+		Subject:  "Math",
+		StartsAt: at(10, 0),
+		EndsAt:   at(11, 0),
+		Status:   models.SessionStatusConfirmed,
+	}
+	if err := store.sessions.Create(confirmed); err != nil {
+		t.Fatalf("Create session: %v", err)
+	}
+
+	router := gin.New()
+	for _, client := range clients {
+		router.POST("/"+client.Email+"/sessions", signedIn(client.Email), h.RequestSession)
+	}
+
+	tests := []struct {
+		name    string
+		client  *models.Client
+		tutor   *models.Tutor
+		start   time.Time
+		status  int
+		message string
+	}{
+		{"free time", clients[1], tutors[0], at(14, 0), http.StatusCreated, "Session requested successfully"},
+		{"outside availability", clients[1], tutors[0], at(21, 0), http.StatusConflict, "Session is outside the tutor's availability"},
+		{"tutor has a confirmed session", clients[1], tutors[0], at(10, 30), http.StatusConflict, "Session overlaps a confirmed session"},
+		{"client has a confirmed session", clients[0], tutors[1], at(10, 30), http.StatusConflict, "Session overlaps a confirmed session"},
+		{"back to back", clients[0], tutors[1], at(11, 0), http.StatusCreated, "Session requested successfully"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := gin.H{"tutor_id": tt.tutor.ID, "subject": "Math", "starts_at": tt.start, "ends_at": tt.start.Add(time.Hour)}
+			status, body := serve(t, router, http.MethodPost, "/"+tt.client.Email+"/sessions", request)
+			if status != tt.status || body["message"] != tt.message {
+				t.Errorf("status = %d %v, want %d %q", status, body, tt.status, tt.message)
+			}
+		})
+	}
+}
+
+func TestSessionLifecycleConflicts(t *testing.T) {
+	h, store := newTestHandler(nil)
+	tutor := &models.Tutor{
+		Name:         "Ada",
+		Email:        "ada@example.com",
+		Subjects:     []string{"Math"},
+		Pay:          40,
+		Availability: "every day 8am-8pm",
+		Timezone:     "UTC",
+	}
+	if err := store.tutors.Create(tutor); err != nil {
+		t.Fatalf("Create tutor: %v", err)
+	}
+	clients := make([]*models.Client, 2)
+	for i, email := range []string{"grace@example.com", "hopper@example.com"} {
+		clients[i] = &models.Client{Name: email, Email: email, Subjects: []string{"Math"}, Timezone: "UTC"}
+		if err := store.clients.Create(clients[i]); err != nil {
+			t.Fatalf("Create client: %v", err)
+		}
+	}
+
+	day := time.Now().UTC().AddDate(0, 0, 7)
+	at := func(hour int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, time.UTC)
+	}
+	// Both clients ask for 10:00 and Hopper also for 14:00
+	requested := []*models.Session{
+		{TutorID: tutor.ID, ClientID: clients[0].ID, StartsAt: at(10), EndsAt: at(11)},
+		{TutorID: tutor.ID, ClientID: clients[1].ID, StartsAt: at(10), EndsAt: at(11)},
+		{TutorID: tutor.ID, ClientID: clients[1].ID, StartsAt: at(14), EndsAt: at(15)},
+	}
+	for _, session := range requested {
+		session.Subject, session.Status = "Math", models.SessionStatusRequested
+		if err := store.sessions.Create(session); err != nil {
+			t.Fatalf("Create session: %v", err)
+		}
+	}
+
+	router := gin.New()
+	router.POST("/tutor/sessions/:id/confirm", signedIn(tutor.Email), h.ConfirmSession)
+	router.POST("/tutor/sessions/:id/reschedule", signedIn(tutor.Email), h.RescheduleSession)
+	router.POST("/tutor/sessions/:id/cancel", signedIn(tutor.Email), h.CancelSession)
+	router.POST("/client/sessions/:id/cancel", signedIn(clients[1].Email), h.CancelSession)
+
+	steps := []struct {
+		name    string
+		path    string
+		id      int
+		body    any
+		status  int
+		message string
+	}{
+		{"confirm", "/tutor/sessions/%d/confirm", requested[0].ID, nil, http.StatusOK, "Session confirmed successfully"},
+		{"confirm a clashing request", "/tutor/sessions/%d/confirm", requested[1].ID, nil, http.StatusConflict, "Session overlaps a confirmed session"},
+		{"reschedule into the confirmed session", "/tutor/sessions/%d/reschedule", requested[2].ID, gin.H{"starts_at": at(10), "ends_at": at(11)}, http.StatusConflict, "Session overlaps a confirmed session"},
+		{"reschedule outside availability", "/tutor/sessions/%d/reschedule", requested[2].ID, gin.H{"starts_at": at(20), "ends_at": at(21)}, http.StatusConflict, "Session is outside the tutor's availability"},
+		{"reschedule to free time", "/tutor/sessions/%d/reschedule", requested[2].ID, gin.H{"starts_at": at(16), "ends_at": at(17)}, http.StatusOK, "Session rescheduled successfully"},
+		{"cancel", "/client/sessions/%d/cancel", requested[2].ID, gin.H{"reason": "Away"}, http.StatusOK, "Session cancelled successfully"},
+		{"cancel again", "/tutor/sessions/%d/cancel", requested[2].ID, nil, http.StatusConflict, "Session cannot be cancelled"},
+		{"reschedule a cancelled session", "/tutor/sessions/%d/reschedule", requested[2].ID, gin.H{"starts_at": at(12), "ends_at": at(13)}, http.StatusConflict, "Session cannot be rescheduled"},
+	}
+
+	for _, step := range steps {
+		path := fmt.Sprintf(step.path, step.id)
+		status, body := serve(t, router, http.MethodPost, path, step.body)
+		if status != step.status || body["message"] != step.message {
+			t.Errorf("%s: POST %s = %d %v, want %d %q", step.name, path, status, body, step.status, step.message)
+		}
+	}
+
+	stored, err := store.sessions.GetByID(requested[2].ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != models.SessionStatusCancelled || stored.CancelledBy != models.RoleClient || stored.CancelReason != "Away" ||
+		!stored.StartsAt.Equal(at(16)) {
+		t.Errorf("session = %+v, want it moved to 16:00 and cancelled by the client", stored)
+	}
+	if clashing, _ := store.sessions.GetByID(requested[1].ID); clashing.Status != models.SessionStatusRequested {
+		t.Errorf("clashing request status = %q, want it still requested", clashing.Status)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSubjects handles GET /api/subjects
+// Returns a flat list with parent IDs, or nested subjects with ?format=tree.
+func (h *Handler) GetSubjects(c *gin.Context) {
+	taxonomy, err := h.subjects.Taxonomy()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve subjects",
+			"status":  "error",
+		})
+		return
+	}
+
+	var data any = taxonomy.Subjects()
+	if c.Query("format") == "tree" {
+		data = taxonomy.Tree()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    data,
+		"message": "Subjects retrieved successfully",
+		"status":  "success",
+	})
+}
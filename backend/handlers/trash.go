@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetDeletedTutors handles GET /api/admin/tutors/deleted
+func (h *Handler) GetDeletedTutors(c *gin.Context) {
+	tutors, err := h.tutors.ListDeleted()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve deleted tutors",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    tutors,
+		"meta":    gin.H{"retention_days": int(models.RetentionPeriod().Hours() / 24)},
+		"message": "Deleted tutors retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// RestoreTutor handles POST /api/admin/tutors/:id/restore
+func (h *Handler) RestoreTutor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tutor ID",
+			"message": "Tutor ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	tutor, err := h.tutors.Restore(id, h.auditChange(c, models.AuditActionRestore, "tutor", auditID(id), nil))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Tutor not found",
+				"message": "No deleted tutor found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to restore tutor",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    tutor,
+		"message": "Tutor restored successfully",
+		"status":  "success",
+	})
+}
+
+// GetDeletedClients handles GET /api/admin/clients/deleted
+func (h *Handler) GetDeletedClients(c *gin.Context) {
+	clients, err := h.clients.ListDeleted()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to retrieve deleted clients",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    clients,
+		"meta":    gin.H{"retention_days": int(models.RetentionPeriod().Hours() / 24)},
+		"message": "Deleted clients retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// RestoreClient handles POST /api/admin/clients/:id/restore
+func (h *Handler) RestoreClient(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid client ID",
+			"message": "Client ID must be a number",
+			"status":  "error",
+		})
+		return
+	}
+
+	client, err := h.clients.Restore(id, h.auditChange(c, models.AuditActionRestore, "client", auditID(id), nil))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Client not found",
+				"message": "No deleted client found with the given ID",
+				"status":  "error",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to restore client",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    client,
+		"message": "Client restored successfully",
+		"status":  "success",
+	})
+}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"fmt"
+	"log"
 	"net/http"
 	"tutor-backend/models"
 
@@ -10,8 +10,20 @@ import (
 )
 
 // GetTutors handles GET /api/tutors
-func GetTutors(c *gin.Context) {
-	tutors, err := models.GetTutors()
+// Supports filtering, sorting and cursor pagination; see parseTutorFilter.
+// With facets=true the response also carries facet counts for the filters.
+func (h *Handler) GetTutors(c *gin.Context) {
+	filter, err := parseTutorFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid search parameters",
+			"status":  "error",
+		})
+		return
+	}
+
+	page, err := h.tutors.Search(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
@@ -21,31 +33,76 @@ func GetTutors(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":    tutors,
+	response := gin.H{
+		"data": page.Tutors,
+		"meta": gin.H{
+			"total":       page.Total,
+			"next_cursor": page.NextCursor,
+		},
 		"message": "Tutors retrieved successfully",
 		"status":  "success",
-	})
+	}
+
+	if c.Query("facets") == "true" {
+		facets, err := h.tutors.Facets(filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to count tutor facets",
+				"status":  "error",
+			})
+			return
+		}
+		response["facets"] = facets
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
-// GetTutorByEmail handles GET /api/tutors/by-email/:email
-func GetTutorByEmail(c *gin.Context) {
-	email := c.Param("email")
-	if email == "" {
+// GetTutorFacets handles GET /api/tutors/facets
+// Accepts the same filters as GET /api/tutors.
+func (h *Handler) GetTutorFacets(c *gin.Context) {
+	filter, err := parseTutorFilter(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Email parameter is required",
-			"message": "Invalid request",
+			"error":   err.Error(),
+			"message": "Invalid search parameters",
 			"status":  "error",
 		})
 		return
 	}
 
-	tutor, err := models.GetTutorByEmail(email)
+	facets, err := h.tutors.Facets(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to count tutor facets",
+			"status":  "error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    facets,
+		"message": "Tutor facets retrieved successfully",
+		"status":  "success",
+	})
+}
+
+// GetMyTutor handles GET /api/me/tutor
+// Returns the signed-in user's tutor profile, or null data if they have none.
+func (h *Handler) GetMyTutor(c *gin.Context) {
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+
+	tutor, err := h.tutors.GetByEmail(email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusOK, gin.H{
 				"data":    nil,
-				"message": "No tutor found with this email",
+				"message": "No tutor profile for this account",
 				"status":  "success",
 			})
 			return
@@ -66,7 +123,7 @@ func GetTutorByEmail(c *gin.Context) {
 }
 
 // CreateTutor handles POST /api/tutors
-func CreateTutor(c *gin.Context) {
+func (h *Handler) CreateTutor(c *gin.Context) {
 	var newTutor models.Tutor
 	
 	if err := c.ShouldBindJSON(&newTutor); err != nil {
@@ -78,9 +135,39 @@ func CreateTutor(c *gin.Context) {
 		return
 	}
 	
+	availability, err := normalizeAvailability(newTutor.Availability, newTutor.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   err.Error(),
+			"message": "Invalid availability",
+			"status":  "error",
+		})
+		return
+	}
+	newTutor.Availability = availability
+
+	// The profile belongs to the signed-in account, whatever email was submitted
+	email, ok := currentEmail(c)
+	if !ok {
+		return
+	}
+	newTutor.Email = email
+
+	// Grant the role first so a failed grant leaves no profile behind;
+	// granting again on a retry is a no-op
+	if err := h.roles.Grant(email, models.RoleTutor, "signup", nil); err != nil {
+		log.Printf("Failed to grant tutor role to %s: %v", email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   err.Error(),
+			"message": "Failed to create tutor",
+			"status":  "error",
+		})
+		return
+	}
+
 	// Save tutor to database
-	if err := models.CreateTutor(&newTutor); err != nil {
-		fmt.Printf("Error creating tutor: %v\n", err)
+	if err := h.tutors.Create(&newTutor); err != nil {
+		log.Printf("Failed to create tutor: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   err.Error(),
 			"message": "Failed to create tutor",
@@ -89,8 +176,6 @@ func CreateTutor(c *gin.Context) {
 		return
 	}
 	
-	fmt.Printf("New Tutor Created: %+v\n", newTutor)
-	
 	c.JSON(http.StatusCreated, gin.H{
 		"data":    newTutor,
 		"message": "Tutor profile created successfully",
@@ -1,35 +1,147 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
+	_ "time/tzdata" // embed the IANA timezone database for profile timezones
+	"tutor-backend/auth"
 	"tutor-backend/database"
 	"tutor-backend/handlers"
 	"tutor-backend/middleware"
+	"tutor-backend/models"
+	"tutor-backend/payments"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
+// purgeInterval is how often soft-deleted profiles are checked for purging
+const purgeInterval = 6 * time.Hour
+
+// invoiceInterval is how often completed sessions are invoiced
+const invoiceInterval = time.Hour
+
+// payoutInterval is how often last month's payout statements are checked for
+const payoutInterval = 24 * time.Hour
+
 func main() {
 	// Load environment variables from .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found or error loading .env file")
 	}
 
-	// Initialize database connection
-	if err := database.InitDB(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	// "migrate up|down [steps]|status" manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := database.InitDB(); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer database.CloseDB()
+
+		if err := runMigrateCommand(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// Every repository lives in Postgres, or in memory for local development without POSTGRES_URL
+	var tutors models.TutorRepository
+	var clients models.ClientRepository
+	var sessions models.SessionRepository
+	var feeds models.CalendarFeedRepository
+	var reviews models.ReviewRepository
+	var ledger models.LedgerRepository
+	var intents models.PaymentRepository
+	var payouts models.PayoutRepository
+	var roles models.RoleRepository
+	var pairings models.PairingRepository
+	var audit models.AuditRepository
+	var subjects models.SubjectRepository
+	if os.Getenv("POSTGRES_URL") == "" {
+		log.Println("POSTGRES_URL is not set; using an in-memory store that is lost on restart")
+		subjects = models.NewMemorySubjectRepository()
+		memoryTutors := models.NewMemoryTutorRepository(subjects)
+		memoryClients := models.NewMemoryClientRepository(subjects)
+		tutors, clients = memoryTutors, memoryClients
+		memoryLedger := models.NewMemoryLedgerRepository()
+		sessions = models.NewMemorySessionRepository(memoryLedger)
+		feeds = models.NewMemoryCalendarFeedRepository()
+		reviews = models.NewMemoryReviewRepository(memoryTutors)
+		ledger, intents = memoryLedger, models.NewMemoryPaymentRepository(memoryLedger)
+		payouts = models.NewMemoryPayoutRepository(memoryLedger)
+		roles = models.NewMemoryRoleRepository()
+		pairings = models.NewMemoryPairingRepository(memoryTutors, memoryClients)
+		audit = models.NewMemoryAuditRepository()
+	} else {
+		if err := database.InitDB(); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer database.CloseDB()
+
+		// Apply pending migrations; replicas starting together wait on the migration lock
+		if _, err := database.MigrateUp(context.Background()); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+
+		subjects = models.NewPostgresSubjectRepository(database.GetDB())
+		tutors = models.NewPostgresTutorRepository(database.GetDB(), subjects)
+		clients = models.NewPostgresClientRepository(database.GetDB(), subjects)
+		sessions = models.NewPostgresSessionRepository(database.GetDB())
+		feeds = models.NewPostgresCalendarFeedRepository(database.GetDB())
+		reviews = models.NewPostgresReviewRepository(database.GetDB())
+		ledger = models.NewPostgresLedgerRepository(database.GetDB())
+		intents = models.NewPostgresPaymentRepository(database.GetDB())
+		payouts = models.NewPostgresPayoutRepository(database.GetDB())
+		roles = models.NewPostgresRoleRepository(database.GetDB())
+		pairings = models.NewPostgresPairingRepository(database.GetDB())
+		audit = models.NewPostgresAuditRepository(database.GetDB())
+
+		// Parse legacy availability columns into availability_slots
+		if err := models.BackfillAvailabilitySlots(database.GetDB()); err != nil {
+			log.Printf("Failed to backfill availability slots: %v", err)
+		}
 	}
-	defer database.CloseDB()
 
-	// Run database migrations
-	if err := database.RunMigrations(); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Collect online payments through the provider configured by PAYMENTS_API_KEY
+	var provider payments.Provider
+	if client, err := payments.NewClient(payments.ConfigFromEnv()); err != nil {
+		log.Printf("Online payments are disabled: %v", err)
+	} else {
+		provider = client
 	}
 
+	h := handlers.NewHandler(tutors, clients, sessions, feeds, reviews, ledger, intents, payouts, roles, pairings, audit, subjects, provider)
+
+	// Seed the built-in subject taxonomy
+	if err := subjects.Seed(); err != nil {
+		log.Printf("Failed to seed subjects: %v", err)
+	}
+
+	// Seed the first admins from ADMIN_EMAILS; other roles are granted through the API
+	if err := models.BootstrapAdmins(roles, strings.Split(os.Getenv("ADMIN_EMAILS"), ",")); err != nil {
+		log.Printf("Failed to bootstrap admins: %v", err)
+	}
+
+	// Verify Firebase ID tokens for authenticated routes
+	verifier, err := auth.NewVerifier(auth.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+	authenticate := middleware.Authenticate(verifier)
+
+	// Purge soft-deleted profiles once they are past the retention window
+	go purgeDeletedProfiles(tutors, clients, models.RetentionPeriod())
+
+	// Invoice sessions as they are completed
+	go invoiceCompletedSessions(sessions, ledger)
+
+	// Issue payout statements once each month has ended
+	go generatePayoutStatements(payouts)
+
 	// Create Gin router
 	r := gin.Default()
 
@@ -47,8 +159,8 @@ func main() {
 			}
 		}
 
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-User-Email")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -69,30 +181,131 @@ func main() {
 	// API routes
 	api := r.Group("/api")
 	{
+		// Subject taxonomy
+		api.GET("/subjects", h.GetSubjects)
+
 		// Tutor routes
-		api.GET("/tutors", handlers.GetTutors)
-		api.POST("/tutors", handlers.CreateTutor)
-		api.GET("/tutors/by-email/:email", handlers.GetTutorByEmail)
+		api.GET("/tutors", h.GetTutors)
+		api.POST("/tutors", authenticate, h.CreateTutor)
+		api.GET("/tutors/available", h.GetAvailableTutors)
+		api.GET("/tutors/facets", h.GetTutorFacets)
+		api.GET("/tutors/:id/matches", authenticate, h.GetTutorMatches)
+		api.GET("/tutors/:id/availability", authenticate, h.GetTutorAvailability)
+		api.GET("/tutors/:id/reviews", h.GetTutorReviews)
 
 		// Client routes
-		api.GET("/clients", handlers.GetClients)
-		api.POST("/clients", handlers.CreateClient)
-		api.GET("/clients/by-email/:email", handlers.GetClientByEmail)
+		api.GET("/clients", h.GetClients)
+		api.POST("/clients", authenticate, h.CreateClient)
+		api.GET("/clients/available", h.GetAvailableClients)
+		api.GET("/clients/:id/matches", authenticate, h.GetClientMatches)
+		api.GET("/clients/:id/availability", authenticate, h.GetClientAvailability)
+
+		// Signed-in user's own profiles
+		me := api.Group("/me")
+		me.Use(authenticate)
+		{
+			me.GET("/access", h.GetMyAccess)
+			me.GET("/tutor", h.GetMyTutor)
+			me.PUT("/tutor", h.UpdateMyTutor)
+			me.PATCH("/tutor", h.UpdateMyTutor)
+			me.GET("/tutor/availability/exceptions", h.GetMyAvailabilityExceptions)
+			me.POST("/tutor/availability/import", h.ImportMyAvailability)
+			me.DELETE("/tutor/availability/import", h.ClearMyAvailabilityImport)
+			me.GET("/client", h.GetMyClient)
+			me.PUT("/client", h.UpdateMyClient)
+			me.PATCH("/client", h.UpdateMyClient)
+			me.GET("/calendar", h.GetMyCalendarFeed)
+			me.POST("/calendar", h.CreateMyCalendarFeed)
+			me.DELETE("/calendar", h.DeleteMyCalendarFeed)
+			me.GET("/balance", h.GetMyBalance)
+			me.GET("/earnings", h.GetMyEarnings)
+			me.GET("/invoices", h.GetMyInvoices)
+			me.GET("/invoices/:id", h.GetMyInvoice)
+			me.POST("/invoices/:id/checkout", h.CreateMyCheckout)
+			me.GET("/invoices/:id/payments", h.GetMyInvoicePayments)
+			me.GET("/payouts", h.GetMyPayouts)
+			me.GET("/payouts/:id/download", h.DownloadMyPayout)
+		}
+
+		// iCalendar feed of the token owner's sessions, for calendar apps that cannot sign in
+		api.GET("/calendar/:token", h.GetCalendarFeed)
+
+		// Payment provider webhooks, authenticated by their signature
+		api.POST("/payments/webhook", h.PaymentWebhook)
+
+		// Sessions between the signed-in user and their tutors or clients
+		sessionRoutes := api.Group("/sessions")
+		sessionRoutes.Use(authenticate)
+		{
+			sessionRoutes.GET("", h.ListSessions)
+			sessionRoutes.POST("", h.RequestSession)
+			sessionRoutes.POST("/series", h.RequestSeries)
+			sessionRoutes.GET("/series/:id", h.GetSeries)
+			sessionRoutes.POST("/series/:id/confirm", h.ConfirmSeries)
+			sessionRoutes.POST("/series/:id/cancel", h.CancelSeries)
+			sessionRoutes.GET("/:id", h.GetSession)
+			sessionRoutes.POST("/:id/confirm", h.ConfirmSession)
+			sessionRoutes.POST("/:id/reschedule", h.RescheduleSession)
+			sessionRoutes.POST("/:id/cancel", h.CancelSession)
+			sessionRoutes.POST("/:id/review", h.ReviewSession)
+		}
 
 		// Admin routes (protected)
 		admin := api.Group("/admin")
-		admin.Use(middleware.AdminAuth())
+		admin.Use(authenticate)
 		{
 			// Admin stats
-			admin.GET("/stats", handlers.GetAdminStats)
+			admin.GET("/stats", middleware.RequirePermission(roles, models.PermissionStatsRead), h.GetAdminStats)
 
 			// Admin tutor management
-			admin.PUT("/tutors/:id", handlers.UpdateTutor)
-			admin.DELETE("/tutors/:id", handlers.DeleteTutor)
+			tutorsWrite := middleware.RequirePermission(roles, models.PermissionTutorsWrite)
+			admin.PUT("/tutors/:id", tutorsWrite, h.UpdateTutor)
+			admin.DELETE("/tutors/:id", tutorsWrite, h.DeleteTutor)
+			admin.GET("/tutors/deleted", tutorsWrite, h.GetDeletedTutors)
+			admin.POST("/tutors/:id/restore", tutorsWrite, h.RestoreTutor)
 
 			// Admin client management
-			admin.PUT("/clients/:id", handlers.UpdateClient)
-			admin.DELETE("/clients/:id", handlers.DeleteClient)
+			clientsWrite := middleware.RequirePermission(roles, models.PermissionClientsWrite)
+			admin.PUT("/clients/:id", clientsWrite, h.UpdateClient)
+			admin.DELETE("/clients/:id", clientsWrite, h.DeleteClient)
+			admin.GET("/clients/deleted", clientsWrite, h.GetDeletedClients)
+			admin.POST("/clients/:id/restore", clientsWrite, h.RestoreClient)
+
+			// Admin batch assignment
+			assignmentsWrite := middleware.RequirePermission(roles, models.PermissionAssignmentsWrite)
+			admin.POST("/assignments/plan", assignmentsWrite, h.PlanAssignments)
+			admin.POST("/assignments/commit", assignmentsWrite, h.CommitAssignments)
+
+			// Review moderation
+			reviewsWrite := middleware.RequirePermission(roles, models.PermissionReviewsWrite)
+			admin.GET("/reviews", reviewsWrite, h.GetAdminReviews)
+			admin.POST("/reviews/:id/approve", reviewsWrite, h.ApproveReview)
+			admin.POST("/reviews/:id/reject", reviewsWrite, h.RejectReview)
+			admin.POST("/reviews/:id/redact", reviewsWrite, h.RedactReview)
+
+			// Billing
+			billingWrite := middleware.RequirePermission(roles, models.PermissionBillingWrite)
+			admin.POST("/invoices/generate", billingWrite, h.GenerateInvoices)
+			admin.GET("/invoices", billingWrite, h.GetAdminInvoices)
+			admin.GET("/clients/:id/balance", billingWrite, h.GetClientBalance)
+			admin.POST("/clients/:id/payments", billingWrite, h.RecordClientPayment)
+			admin.POST("/clients/:id/credits", billingWrite, h.GrantClientCredit)
+			admin.POST("/clients/:id/refunds", billingWrite, h.RefundClient)
+			admin.GET("/tutors/:id/earnings", billingWrite, h.GetTutorEarnings)
+			admin.POST("/payouts/generate", billingWrite, h.GeneratePayouts)
+			admin.GET("/payouts", billingWrite, h.GetAdminPayouts)
+			admin.POST("/payouts/:id/paid", billingWrite, h.MarkPayoutPaid)
+			admin.GET("/payouts/:id/download", billingWrite, h.DownloadPayout)
+
+			// Roles and permissions
+			rolesWrite := middleware.RequirePermission(roles, models.PermissionRolesWrite)
+			admin.GET("/roles", rolesWrite, h.GetRoles)
+			admin.GET("/users", rolesWrite, h.GetUsers)
+			admin.PUT("/users/:email/roles/:role", rolesWrite, h.GrantRole)
+			admin.DELETE("/users/:email/roles/:role", rolesWrite, h.RevokeRole)
+
+			// Audit log
+			admin.GET("/audit", middleware.RequirePermission(roles, models.PermissionAuditRead), h.GetAuditLog)
 		}
 	}
 
@@ -116,3 +329,55 @@ func main() {
 	<-quit
 	log.Println("Shutting down server...")
 }
+
+// purgeDeletedProfiles permanently removes soft-deleted profiles older than
+// the retention period, once at startup and then every purgeInterval
+func purgeDeletedProfiles(tutors models.TutorRepository, clients models.ClientRepository, retention time.Duration) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := models.PurgeDeletedProfiles(tutors, clients, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Failed to purge deleted profiles: %v", err)
+		} else if result.Tutors > 0 || result.Clients > 0 {
+			log.Printf("Purged %d tutors and %d clients deleted more than %s ago", result.Tutors, result.Clients, retention)
+		}
+		<-ticker.C
+	}
+}
+
+// invoiceCompletedSessions invoices completed sessions once at startup and
+// then every invoiceInterval
+func invoiceCompletedSessions(sessions models.SessionRepository, ledger models.LedgerRepository) {
+	ticker := time.NewTicker(invoiceInterval)
+	defer ticker.Stop()
+
+	for {
+		invoices, err := models.InvoiceCompletedSessions(sessions, ledger, time.Now(), nil)
+		if err != nil {
+			log.Printf("Failed to invoice completed sessions: %v", err)
+		} else if len(invoices) > 0 {
+			log.Printf("Issued %d invoices for completed sessions", len(invoices))
+		}
+		<-ticker.C
+	}
+}
+
+// generatePayoutStatements issues the previous month's payout statements once
+// at startup and then every payoutInterval. Sessions already on a statement
+// are skipped, so repeated runs only pick up invoices paid late.
+func generatePayoutStatements(payouts models.PayoutRepository) {
+	ticker := time.NewTicker(payoutInterval)
+	defer ticker.Stop()
+
+	for {
+		statements, err := models.GeneratePayoutStatements(payouts, time.Now(), nil)
+		if err != nil {
+			log.Printf("Failed to generate payout statements: %v", err)
+		} else if len(statements) > 0 {
+			log.Printf("Issued %d payout statements", len(statements))
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,189 @@
+package matching
+
+import (
+	"math"
+	"sort"
+	"tutor-backend/models"
+)
+
+// DefaultMinScore is the lowest score the assignment will pair a client at
+const DefaultMinScore = 0.5
+
+// Assignment is a single proposed client/tutor pairing
+type Assignment struct {
+	ClientID   int       `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	TutorID    int       `json:"tutor_id"`
+	TutorName  string    `json:"tutor_name"`
+	Score      float64   `json:"score"`
+	Breakdown  Breakdown `json:"breakdown"`
+}
+
+// Plan is a proposed set of pairings for an admin to review
+type Plan struct {
+	Assignments []Assignment `json:"assignments"`
+	Unassigned  []int        `json:"unassigned_client_ids"`
+	TotalScore  float64      `json:"total_score"`
+}
+
+// Assign computes the pairing of clients to tutors that maximizes the total
+// score. capacity maps tutor IDs to the number of additional clients each can
+// take; tutors missing from the map use their own Capacity(). Pairs scoring
+// below minScore are never proposed, so those clients are left unassigned.
+//
+// Each tutor is expanded into one column per open seat and the problem is
+// solved as a rectangular assignment with the Hungarian algorithm. Every
+// client also gets a private "unassigned" column so the matrix always has a
+// feasible solution.
+func (m *Matcher) Assign(clients []models.Client, tutors []models.Tutor, capacity map[int]int, minScore float64) Plan {
+	plan := Plan{Assignments: []Assignment{}, Unassigned: []int{}}
+	if len(clients) == 0 {
+		return plan
+	}
+
+	// One column per open seat
+	var seats []int
+	for i := range tutors {
+		open, ok := capacity[tutors[i].ID]
+		if !ok {
+			open = tutors[i].Capacity()
+		}
+		for s := 0; s < open; s++ {
+			seats = append(seats, i)
+		}
+	}
+
+	rows := len(clients)
+	cols := len(seats) + rows
+
+	// Scores are computed once per client/tutor pair, not per seat
+	breakdowns := make([][]Breakdown, rows)
+	for i, client := range clients {
+		breakdowns[i] = make([]Breakdown, len(tutors))
+		for j, tutor := range tutors {
+			breakdowns[i][j] = m.Score(client, tutor)
+		}
+	}
+
+	// Minimize cost = -score. The unassigned columns cost 0 and pairs below
+	// the threshold cost more than that, so they are never chosen.
+	cost := make([][]float64, rows)
+	for i := range clients {
+		cost[i] = make([]float64, cols)
+		for c, tutorIndex := range seats {
+			score := breakdowns[i][tutorIndex].Total()
+			if score < minScore {
+				cost[i][c] = 1
+			} else {
+				cost[i][c] = -score
+			}
+		}
+		for c := len(seats); c < cols; c++ {
+			if c-len(seats) != i {
+				cost[i][c] = 1
+			}
+		}
+	}
+
+	for i, c := range hungarian(cost) {
+		client := clients[i]
+		if c >= len(seats) || cost[i][c] > 0 {
+			plan.Unassigned = append(plan.Unassigned, client.ID)
+			continue
+		}
+
+		tutor := tutors[seats[c]]
+		breakdown := breakdowns[i][seats[c]]
+		assignment := Assignment{
+			ClientID:   client.ID,
+			ClientName: client.Name,
+			TutorID:    tutor.ID,
+			TutorName:  tutor.Name,
+			Score:      breakdown.Total(),
+			Breakdown:  breakdown,
+		}
+		plan.Assignments = append(plan.Assignments, assignment)
+		plan.TotalScore += assignment.Score
+	}
+
+	sort.SliceStable(plan.Assignments, func(i, j int) bool {
+		return plan.Assignments[i].Score > plan.Assignments[j].Score
+	})
+	plan.TotalScore = round(plan.TotalScore)
+
+	return plan
+}
+
+// hungarian solves the rectangular assignment problem for an n x m cost
+// matrix with n <= m and returns the column assigned to each row.
+func hungarian(cost [][]float64) []int {
+	n := len(cost)
+	m := len(cost[0])
+
+	// Potentials and matching are 1-indexed; column 0 is a sentinel
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, m+1)
+		used := make([]bool, m+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+
+		for {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+			if j0 == 0 {
+				break
+			}
+		}
+	}
+
+	assignment := make([]int, n)
+	for j := 1; j <= m; j++ {
+		if p[j] != 0 {
+			assignment[p[j]-1] = j - 1
+		}
+	}
+
+	return assignment
+}
@@ -0,0 +1,165 @@
+package matching
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"tutor-backend/models"
+)
+
+// bruteForce returns the lowest total cost of assigning each row of a square
+// or wide matrix to a distinct column
+func bruteForce(cost [][]float64, row int, used []bool) float64 {
+	if row == len(cost) {
+		return 0
+	}
+	best := math.Inf(1)
+	for col := range cost[row] {
+		if used[col] {
+			continue
+		}
+		used[col] = true
+		best = math.Min(best, cost[row][col]+bruteForce(cost, row+1, used))
+		used[col] = false
+	}
+	return best
+}
+
+func TestHungarianIsOptimal(t *testing.T) {
+	tests := []struct {
+		name string
+		cost [][]float64
+		want []int
+	}{
+		{
+			name: "square",
+			cost: [][]float64{
+				{4, 1, 3},
+				{2, 0, 5},
+				{3, 2, 2},
+			},
+			want: []int{1, 0, 2},
+		},
+		{
+			name: "greedy is not optimal",
+			cost: [][]float64{
+				{-0.9, -0.8},
+				{-0.85, -0.1},
+			},
+			want: []int{1, 0},
+		},
+		{
+			name: "more columns than rows",
+			cost: [][]float64{
+				{7, 2, 9, 4},
+				{3, 8, 1, 6},
+			},
+			want: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hungarian(tt.cost)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hungarian = %v, want %v", got, tt.want)
+			}
+
+			var total float64
+			for row, col := range got {
+				total += tt.cost[row][col]
+			}
+			if best := bruteForce(tt.cost, 0, make([]bool, len(tt.cost[0]))); math.Abs(total-best) > 1e-9 {
+				t.Errorf("total cost = %v, want the optimum %v", total, best)
+			}
+		})
+	}
+}
+
+func TestAssign(t *testing.T) {
+	// Only the budget counts, so each score is set by the tutor's rate
+	// against the client's budget
+	matcher := &Matcher{Weights: Weights{Budget: 1}}
+	clients := []models.Client{
+		{ID: 1, Name: "Flexible", Budget: 100},
+		{ID: 2, Name: "Tight", Budget: 60},
+		{ID: 3, Name: "Cheap", Budget: 10},
+	}
+	tutors := []models.Tutor{
+		{ID: 10, Name: "Affordable", Pay: 100},
+		{ID: 20, Name: "Pricey", Pay: 120},
+	}
+
+	tests := []struct {
+		name       string
+		capacity   map[int]int
+		minScore   float64
+		pairs      map[int]int
+		unassigned []int
+		total      float64
+	}{
+		{
+			// Greedy gives Affordable to Flexible and leaves Tight unpaired
+			name:       "maximizes the total score",
+			capacity:   map[int]int{10: 1, 20: 1},
+			minScore:   0.3,
+			pairs:      map[int]int{1: 20, 2: 10},
+			unassigned: []int{3},
+			total:      1.133,
+		},
+		{
+			name:       "fills a tutor up to capacity",
+			capacity:   map[int]int{10: 2, 20: 0},
+			minScore:   0.3,
+			pairs:      map[int]int{1: 10, 2: 10},
+			unassigned: []int{3},
+			total:      1.333,
+		},
+		{
+			name:       "no open seats",
+			capacity:   map[int]int{10: 0, 20: 0},
+			minScore:   0,
+			pairs:      map[int]int{},
+			unassigned: []int{1, 2, 3},
+			total:      0,
+		},
+		{
+			name:       "skips pairs below the minimum score",
+			capacity:   map[int]int{10: 1, 20: 1},
+			minScore:   0.9,
+			pairs:      map[int]int{1: 10},
+			unassigned: []int{2, 3},
+			total:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := matcher.Assign(clients, tutors, tt.capacity, tt.minScore)
+
+			pairs := map[int]int{}
+			seats := map[int]int{}
+			for _, assignment := range plan.Assignments {
+				pairs[assignment.ClientID] = assignment.TutorID
+				seats[assignment.TutorID]++
+				if assignment.Score != assignment.Breakdown.Total() || assignment.Score < tt.minScore {
+					t.Errorf("assignment %+v scores %v", assignment, assignment.Breakdown.Total())
+				}
+			}
+			if !reflect.DeepEqual(pairs, tt.pairs) {
+				t.Errorf("pairs = %v, want %v", pairs, tt.pairs)
+			}
+			for tutorID, taken := range seats {
+				if taken > tt.capacity[tutorID] {
+					t.Errorf("tutor %d took %d clients, over capacity %d", tutorID, taken, tt.capacity[tutorID])
+				}
+			}
+			if !reflect.DeepEqual(plan.Unassigned, tt.unassigned) {
+				t.Errorf("unassigned = %v, want %v", plan.Unassigned, tt.unassigned)
+			}
+			if plan.TotalScore != tt.total {
+				t.Errorf("total score = %v, want %v", plan.TotalScore, tt.total)
+			}
+		})
+	}
+}
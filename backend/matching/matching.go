@@ -0,0 +1,340 @@
+package matching
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"tutor-backend/models"
+)
+
+// Weights controls how much each criterion contributes to the total score
+type Weights struct {
+	Subjects     float64 `json:"subjects"`
+	Budget       float64 `json:"budget"`
+	Language     float64 `json:"language"`
+	Education    float64 `json:"education"`
+	Location     float64 `json:"location"`
+	Availability float64 `json:"availability"`
+}
+
+// DefaultWeights are the weights used when none are configured
+var DefaultWeights = Weights{
+	Subjects:     0.35,
+	Budget:       0.20,
+	Language:     0.10,
+	Education:    0.10,
+	Location:     0.10,
+	Availability: 0.15,
+}
+
+// neutralScore is used when one side did not provide enough data to compare
+const neutralScore = 0.5
+
+// Criterion is the score for a single matching criterion
+type Criterion struct {
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+	Detail string  `json:"detail"`
+}
+
+// Breakdown explains how a total score was computed
+type Breakdown struct {
+	Subjects     Criterion `json:"subjects"`
+	Budget       Criterion `json:"budget"`
+	Language     Criterion `json:"language"`
+	Education    Criterion `json:"education"`
+	Location     Criterion `json:"location"`
+	Availability Criterion `json:"availability"`
+}
+
+// Total returns the weighted sum of all criteria, normalized to 0..1
+func (b Breakdown) Total() float64 {
+	criteria := []Criterion{b.Subjects, b.Budget, b.Language, b.Education, b.Location, b.Availability}
+
+	var sum, weights float64
+	for _, criterion := range criteria {
+		sum += criterion.Score * criterion.Weight
+		weights += criterion.Weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return round(sum / weights)
+}
+
+// TutorMatch is a tutor ranked against a client
+type TutorMatch struct {
+	Tutor     models.Tutor `json:"tutor"`
+	Score     float64      `json:"score"`
+	Breakdown Breakdown    `json:"breakdown"`
+}
+
+// ClientMatch is a client ranked against a tutor
+type ClientMatch struct {
+	Client    models.Client `json:"client"`
+	Score     float64       `json:"score"`
+	Breakdown Breakdown     `json:"breakdown"`
+}
+
+// Matcher scores tutors against clients
+type Matcher struct {
+	Weights Weights
+
+	// Reference picks the week used to line up availability across
+	// timezones; the zero value means the current week
+	Reference time.Time
+
+	// Subjects expands a client's subjects to their aliases and
+	// sub-subjects; without it subjects must match by name
+	Subjects *models.SubjectTaxonomy
+
+	// Busy holds busy spans by tutor ID, such as events imported from their
+	// calendars, which are taken out of their availability
+	Busy map[int][]models.Interval
+}
+
+// NewMatcher returns a matcher using the default weights
+func NewMatcher() *Matcher {
+	return &Matcher{Weights: DefaultWeights}
+}
+
+// Score computes the per-criterion breakdown for a client/tutor pair
+func (m *Matcher) Score(client models.Client, tutor models.Tutor) Breakdown {
+	breakdown := Breakdown{
+		Subjects:     m.scoreSubjects(client.Subjects, tutor.Subjects),
+		Budget:       scoreBudget(client.Budget, tutor.Pay),
+		Language:     scoreLanguage(client.Language, tutor.Language),
+		Education:    scoreEducation(client.Education, tutor.Education),
+		Location:     scoreLocation(client.Location, tutor.Location),
+		Availability: m.scoreAvailability(client, tutor),
+	}
+
+	breakdown.Subjects.Weight = m.Weights.Subjects
+	breakdown.Budget.Weight = m.Weights.Budget
+	breakdown.Language.Weight = m.Weights.Language
+	breakdown.Education.Weight = m.Weights.Education
+	breakdown.Location.Weight = m.Weights.Location
+	breakdown.Availability.Weight = m.Weights.Availability
+
+	return breakdown
+}
+
+// RankTutors scores every tutor against the client and returns them best first
+func (m *Matcher) RankTutors(client models.Client, tutors []models.Tutor) []TutorMatch {
+	matches := make([]TutorMatch, 0, len(tutors))
+	for _, tutor := range tutors {
+		breakdown := m.Score(client, tutor)
+		matches = append(matches, TutorMatch{
+			Tutor:     tutor,
+			Score:     breakdown.Total(),
+			Breakdown: breakdown,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Tutor.Rating > matches[j].Tutor.Rating
+	})
+
+	return matches
+}
+
+// RankClients scores every client against the tutor and returns them best first.
+// It uses the same scoring model as RankTutors so both directions agree.
+func (m *Matcher) RankClients(tutor models.Tutor, clients []models.Client) []ClientMatch {
+	matches := make([]ClientMatch, 0, len(clients))
+	for _, client := range clients {
+		breakdown := m.Score(client, tutor)
+		matches = append(matches, ClientMatch{
+			Client:    client,
+			Score:     breakdown.Total(),
+			Breakdown: breakdown,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Client.CreatedAt.Before(matches[j].Client.CreatedAt)
+	})
+
+	return matches
+}
+
+// scoreSubjects returns the fraction of the client's subjects the tutor
+// covers. A tutor of "Calculus" covers a client asking for "Mathematics".
+func (m *Matcher) scoreSubjects(wanted, offered []string) Criterion {
+	if len(wanted) == 0 {
+		return Criterion{Score: neutralScore, Detail: "Client did not list any subjects"}
+	}
+
+	offeredSet := make(map[string]bool, len(offered))
+	for _, subject := range offered {
+		offeredSet[normalize(subject)] = true
+	}
+
+	var covered []string
+	for _, subject := range wanted {
+		names := []string{subject}
+		if m.Subjects != nil {
+			names = m.Subjects.Expand(subject)
+		}
+		for _, name := range names {
+			if offeredSet[normalize(name)] {
+				covered = append(covered, subject)
+				break
+			}
+		}
+	}
+
+	if len(covered) == 0 {
+		return Criterion{Score: 0, Detail: "No overlapping subjects"}
+	}
+
+	return Criterion{
+		Score:  round(float64(len(covered)) / float64(len(wanted))),
+		Detail: fmt.Sprintf("Covers %d of %d subjects: %s", len(covered), len(wanted), strings.Join(covered, ", ")),
+	}
+}
+
+// scoreBudget is 1 when the tutor is within budget and decays linearly above it
+func scoreBudget(budget, pay float64) Criterion {
+	if budget <= 0 || pay <= 0 {
+		return Criterion{Score: neutralScore, Detail: "Budget or rate not provided"}
+	}
+
+	if pay <= budget {
+		return Criterion{Score: 1, Detail: fmt.Sprintf("Rate $%.2f/hr is within budget $%.2f/hr", pay, budget)}
+	}
+
+	over := (pay - budget) / budget
+	return Criterion{
+		Score:  round(math.Max(0, 1-over)),
+		Detail: fmt.Sprintf("Rate $%.2f/hr is %.0f%% over budget $%.2f/hr", pay, over*100, budget),
+	}
+}
+
+// scoreLanguage compares the preferred language of both sides
+func scoreLanguage(wanted, offered string) Criterion {
+	if strings.TrimSpace(wanted) == "" || strings.TrimSpace(offered) == "" {
+		return Criterion{Score: neutralScore, Detail: "Language not provided"}
+	}
+
+	if normalize(wanted) == normalize(offered) {
+		return Criterion{Score: 1, Detail: fmt.Sprintf("Both speak %s", offered)}
+	}
+
+	return Criterion{Score: 0, Detail: fmt.Sprintf("Client prefers %s, tutor speaks %s", wanted, offered)}
+}
+
+// remoteLocations are locations that can be served from anywhere
+var remoteLocations = map[string]bool{
+	"online":  true,
+	"remote":  true,
+	"virtual": true,
+}
+
+// scoreLocation compares locations, giving partial credit when either side is remote
+func scoreLocation(wanted, offered string) Criterion {
+	if strings.TrimSpace(wanted) == "" || strings.TrimSpace(offered) == "" {
+		return Criterion{Score: neutralScore, Detail: "Location not provided"}
+	}
+
+	if normalize(wanted) == normalize(offered) {
+		return Criterion{Score: 1, Detail: fmt.Sprintf("Both are in %s", offered)}
+	}
+
+	if remoteLocations[normalize(wanted)] || remoteLocations[normalize(offered)] {
+		return Criterion{Score: 0.75, Detail: "Sessions can be held online"}
+	}
+
+	return Criterion{Score: 0, Detail: fmt.Sprintf("Client is in %s, tutor is in %s", wanted, offered)}
+}
+
+// educationRanks orders the education levels offered by the frontend dropdowns
+var educationRanks = map[string]float64{
+	"elementary school":                  1,
+	"middle school":                      2,
+	"high school":                        3,
+	"high school graduate":               4,
+	"some college":                       5,
+	"associate degree":                   6,
+	"teaching certificate":               7,
+	"bachelor's degree":                  7,
+	"master's degree":                    8,
+	"doctoral degree (phd)":              9,
+	"professional degree (md, jd, etc.)": 9,
+}
+
+// educationRank returns the rank of an education level, or false if unknown
+func educationRank(level string) (float64, bool) {
+	level = normalize(level)
+
+	// Degrees in progress rank halfway between the previous level and the degree
+	if strings.HasSuffix(level, "(in progress)") {
+		base := strings.TrimSpace(strings.TrimSuffix(level, "(in progress)"))
+		rank, ok := educationRanks[base]
+		return rank - 0.5, ok
+	}
+
+	rank, ok := educationRanks[level]
+	return rank, ok
+}
+
+// scoreEducation prefers tutors whose education is above the client's level
+func scoreEducation(clientLevel, tutorLevel string) Criterion {
+	clientRank, clientOK := educationRank(clientLevel)
+	tutorRank, tutorOK := educationRank(tutorLevel)
+	if !clientOK || !tutorOK {
+		return Criterion{Score: neutralScore, Detail: "Education level not comparable"}
+	}
+
+	switch {
+	case tutorRank > clientRank:
+		return Criterion{Score: 1, Detail: fmt.Sprintf("Tutor (%s) is above client level (%s)", tutorLevel, clientLevel)}
+	case tutorRank == clientRank:
+		return Criterion{Score: neutralScore, Detail: fmt.Sprintf("Tutor and client are both at %s level", clientLevel)}
+	default:
+		return Criterion{Score: 0, Detail: fmt.Sprintf("Tutor (%s) is below client level (%s)", tutorLevel, clientLevel)}
+	}
+}
+
+// scoreAvailability returns the fraction of the client's weekly hours the tutor shares.
+// Each side is read in its own timezone, so remote pairs are compared correctly.
+func (m *Matcher) scoreAvailability(client models.Client, tutor models.Tutor) Criterion {
+	clientSlots, clientErr := models.ParseAvailability(client.Availability, client.Timezone)
+	tutorSlots, tutorErr := models.ParseAvailability(tutor.Availability, tutor.Timezone)
+	if clientErr != nil || tutorErr != nil || clientSlots.TotalMinutes() == 0 || tutorSlots.TotalMinutes() == 0 {
+		return Criterion{Score: neutralScore, Detail: "Availability not provided"}
+	}
+
+	reference := m.Reference
+	if reference.IsZero() {
+		reference = time.Now()
+	}
+	tutorSlots = tutorSlots.Without(m.Busy[tutor.ID], reference)
+
+	shared := clientSlots.OverlapMinutes(tutorSlots, reference)
+	if shared == 0 {
+		return Criterion{Score: 0, Detail: "No overlapping availability"}
+	}
+
+	requested := clientSlots.TotalMinutes()
+	return Criterion{
+		Score:  round(math.Min(1, float64(shared)/float64(requested))),
+		Detail: fmt.Sprintf("%g of %g requested hours overlap", float64(shared)/60, float64(requested)/60),
+	}
+}
+
+func normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func round(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
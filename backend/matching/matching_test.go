@@ -0,0 +1,192 @@
+package matching
+
+import (
+	"testing"
+	"time"
+	"tutor-backend/models"
+)
+
+func TestScoreBreakdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		client models.Client
+		tutor  models.Tutor
+		want   Breakdown
+	}{
+		{
+			name:   "no data is neutral",
+			client: models.Client{},
+			tutor:  models.Tutor{},
+			want: Breakdown{
+				Subjects:     Criterion{Score: neutralScore},
+				Budget:       Criterion{Score: neutralScore},
+				Language:     Criterion{Score: neutralScore},
+				Education:    Criterion{Score: neutralScore},
+				Location:     Criterion{Score: neutralScore},
+				Availability: Criterion{Score: neutralScore},
+			},
+		},
+		{
+			name: "perfect fit",
+			client: models.Client{
+				Subjects:     []string{"Math", "Physics"},
+				Budget:       50,
+				Language:     "English",
+				Education:    "High School",
+				Location:     "Boston",
+				Availability: `["Mon-4:00 PM", "Mon-5:00 PM"]`,
+				Timezone:     "UTC",
+			},
+			tutor: models.Tutor{
+				Subjects:     []string{"physics", "Math", "Chemistry"},
+				Pay:          40,
+				Language:     "english",
+				Education:    "Master's Degree",
+				Location:     "boston",
+				Availability: `["Mon-3:00 PM", "Mon-4:00 PM", "Mon-5:00 PM"]`,
+				Timezone:     "UTC",
+			},
+			want: Breakdown{
+				Subjects:     Criterion{Score: 1},
+				Budget:       Criterion{Score: 1},
+				Language:     Criterion{Score: 1},
+				Education:    Criterion{Score: 1},
+				Location:     Criterion{Score: 1},
+				Availability: Criterion{Score: 1},
+			},
+		},
+		{
+			name: "partial fit",
+			client: models.Client{
+				Subjects:     []string{"Math", "Physics", "Biology", "History"},
+				Budget:       40,
+				Language:     "Spanish",
+				Education:    "Bachelor's Degree (in progress)",
+				Location:     "Online",
+				Availability: `["Tue-4:00 PM", "Tue-5:00 PM"]`,
+				Timezone:     "UTC",
+			},
+			tutor: models.Tutor{
+				Subjects:     []string{"Math"},
+				Pay:          50,
+				Language:     "English",
+				Education:    "Some College",
+				Location:     "Chicago",
+				Availability: `["Tue-5:00 PM"]`,
+				Timezone:     "UTC",
+			},
+			want: Breakdown{
+				Subjects:     Criterion{Score: 0.25},
+				Budget:       Criterion{Score: 0.75},
+				Language:     Criterion{Score: 0},
+				Education:    Criterion{Score: 0},
+				Location:     Criterion{Score: 0.75},
+				Availability: Criterion{Score: 0.5},
+			},
+		},
+		{
+			name:   "no overlap",
+			client: models.Client{Subjects: []string{"Art"}, Budget: 20, Location: "Boston"},
+			tutor:  models.Tutor{Subjects: []string{"Math"}, Pay: 60, Location: "Denver"},
+			want: Breakdown{
+				Subjects:     Criterion{Score: 0},
+				Budget:       Criterion{Score: 0},
+				Language:     Criterion{Score: neutralScore},
+				Education:    Criterion{Score: neutralScore},
+				Location:     Criterion{Score: 0},
+				Availability: Criterion{Score: neutralScore},
+			},
+		},
+	}
+
+	matcher := NewMatcher()
+	matcher.Reference = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matcher.Score(tt.client, tt.tutor)
+			criteria := map[string][2]Criterion{
+				"subjects":     {got.Subjects, tt.want.Subjects},
+				"budget":       {got.Budget, tt.want.Budget},
+				"language":     {got.Language, tt.want.Language},
+				"education":    {got.Education, tt.want.Education},
+				"location":     {got.Location, tt.want.Location},
+				"availability": {got.Availability, tt.want.Availability},
+			}
+			for name, pair := range criteria {
+				if pair[0].Score != pair[1].Score {
+					t.Errorf("%s score = %v (%s), want %v", name, pair[0].Score, pair[0].Detail, pair[1].Score)
+				}
+				if pair[0].Detail == "" {
+					t.Errorf("%s has no detail", name)
+				}
+			}
+
+			want := tt.want
+			want.Subjects.Weight = DefaultWeights.Subjects
+			want.Budget.Weight = DefaultWeights.Budget
+			want.Language.Weight = DefaultWeights.Language
+			want.Education.Weight = DefaultWeights.Education
+			want.Location.Weight = DefaultWeights.Location
+			want.Availability.Weight = DefaultWeights.Availability
+			if got.Total() != want.Total() {
+				t.Errorf("Total = %v, want %v", got.Total(), want.Total())
+			}
+		})
+	}
+}
+
+func TestBreakdownTotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		breakdown Breakdown
+		want      float64
+	}{
+		{"no weights", Breakdown{Subjects: Criterion{Score: 1}}, 0},
+		{"one criterion", Breakdown{Subjects: Criterion{Score: 0.4, Weight: 2}}, 0.4},
+		{
+			"weighted average",
+			Breakdown{
+				Subjects: Criterion{Score: 1, Weight: 0.3},
+				Budget:   Criterion{Score: 0, Weight: 0.1},
+			},
+			0.75,
+		},
+		{
+			"rounded to three decimals",
+			Breakdown{
+				Subjects: Criterion{Score: 1, Weight: 1},
+				Budget:   Criterion{Score: 0, Weight: 1},
+				Language: Criterion{Score: 0, Weight: 1},
+			},
+			0.333,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.breakdown.Total(); got != tt.want {
+			t.Errorf("%s: Total = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRankTutorsBestFirst(t *testing.T) {
+	client := models.Client{Subjects: []string{"Math"}, Budget: 40}
+	tutors := []models.Tutor{
+		{ID: 1, Subjects: []string{"Art"}, Pay: 40},
+		{ID: 2, Subjects: []string{"Math"}, Pay: 40, Rating: 4.1},
+		{ID: 3, Subjects: []string{"Math"}, Pay: 40, Rating: 4.8},
+		{ID: 4, Subjects: []string{"Math"}, Pay: 60},
+	}
+
+	matches := NewMatcher().RankTutors(client, tutors)
+	order := []int{}
+	for _, match := range matches {
+		order = append(order, match.Tutor.ID)
+	}
+	want := []int{3, 2, 4, 1}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("ranked %v, want %v", order, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"tutor-backend/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys for the verified identity set by Authenticate
+const (
+	UIDKey           = "uid"
+	EmailKey         = "email"
+	EmailVerifiedKey = "email_verified"
+)
+
+// Authenticate verifies the Firebase ID token sent as "Authorization: Bearer <token>"
+// and stores the caller's uid and email in the context
+func Authenticate(verifier *auth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Missing bearer token",
+				"message": "Authentication required",
+				"status":  "error",
+			})
+			c.Abort()
+			return
+		}
+
+		verified, err := verifier.Verify(c.Request.Context(), strings.TrimSpace(token))
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, auth.ErrKeysUnavailable) {
+				status = http.StatusServiceUnavailable
+			}
+			c.JSON(status, gin.H{
+				"error":   err.Error(),
+				"message": "Authentication failed",
+				"status":  "error",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(UIDKey, verified.UID)
+		c.Set(EmailKey, verified.Email)
+		c.Set(EmailVerifiedKey, verified.EmailVerified)
+		c.Next()
+	}
+}
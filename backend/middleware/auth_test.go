@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tutor-backend/auth"
+	"tutor-backend/auth/authtest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newAuthenticatedRouter(t *testing.T) (*gin.Engine, *authtest.Issuer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	issuer, err := authtest.NewIssuer("tutor-match-test")
+	if err != nil {
+		t.Fatalf("NewIssuer: %v", err)
+	}
+	t.Cleanup(issuer.Close)
+
+	verifier, err := auth.NewVerifier(issuer.Config())
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/whoami", Authenticate(verifier), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"uid":            c.GetString(UIDKey),
+			"email":          c.GetString(EmailKey),
+			"email_verified": c.GetBool(EmailVerifiedKey),
+		})
+	})
+	return router, issuer
+}
+
+func TestAuthenticateSetsIdentity(t *testing.T) {
+	router, issuer := newAuthenticatedRouter(t)
+
+	claims := issuer.Claims("user-1", "ada@example.com")
+	claims["email_verified"] = false
+	token, err := issuer.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", response.Code, response.Body)
+	}
+
+	var identity struct {
+		UID           string `json:"uid"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &identity); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if identity.UID != "user-1" || identity.Email != "ada@example.com" || identity.EmailVerified {
+		t.Errorf("identity = %+v, want uid user-1, email ada@example.com, unverified", identity)
+	}
+}
+
+func TestAuthenticateRejectsMissingOrMalformedHeader(t *testing.T) {
+	router, issuer := newAuthenticatedRouter(t)
+
+	token, err := issuer.Token("user-1", "ada@example.com")
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+
+	tests := map[string]string{
+		"missing":       "",
+		"no scheme":     token,
+		"basic scheme":  "Basic " + token,
+		"empty bearer":  "Bearer ",
+		"invalid token": "Bearer not-a-token",
+	}
+
+	for name, header := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if header != "" {
+				request.Header.Set("Authorization", header)
+			}
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			if response.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401: %s", response.Code, response.Body)
+			}
+		})
+	}
+}
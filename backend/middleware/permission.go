@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"tutor-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminEmailKey holds the email of the staff member making an admin request
+const AdminEmailKey = "admin_email"
+
+// RequirePermission allows the request only if the caller's roles in roles
+// grant every listed permission. It must run after Authenticate, which
+// supplies the verified email that roles are granted to.
+func RequirePermission(roles models.RoleRepository, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := c.GetString(EmailKey)
+
+		if email == "" || !c.GetBool(EmailVerifiedKey) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Missing verified email",
+				"message": "Admin access requires a verified email address",
+				"status":  "error",
+			})
+			c.Abort()
+			return
+		}
+
+		access, err := roles.Access(email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   err.Error(),
+				"message": "Failed to check permissions",
+				"status":  "error",
+			})
+			c.Abort()
+			return
+		}
+
+		var missing []string
+		for _, permission := range permissions {
+			if !access.HasPermission(permission) {
+				missing = append(missing, permission)
+			}
+		}
+
+		if len(missing) > 0 {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Access denied",
+				"message": "Missing permission: " + strings.Join(missing, ", "),
+				"status":  "error",
+			})
+			c.Abort()
+			return
+		}
+
+		// Store admin email in context for use in handlers
+		c.Set(AdminEmailKey, email)
+		c.Next()
+	}
+}
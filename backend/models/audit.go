@@ -0,0 +1,322 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Audit actions
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// FieldChange is the before and after value of one changed field
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEntry records a mutating admin action
+type AuditEntry struct {
+	ID         int64                  `json:"id"`
+	Actor      string                 `json:"actor"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   string                 `json:"entity_id"`
+	Before     json.RawMessage        `json:"before"`
+	After      json.RawMessage        `json:"after"`
+	Diff       map[string]FieldChange `json:"diff"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// AuditFilter narrows the audit log
+type AuditFilter struct {
+	Actor      string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time // exclusive
+	Cursor     string
+	Limit      int
+}
+
+// AuditPage is one page of audit entries, newest first
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// auditIgnoredFields change on every write and would only add noise to diffs
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// toFields converts a value to its JSON object fields; nil gives nil
+func toFields(value any) (map[string]any, json.RawMessage, error) {
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil()) {
+		return nil, json.RawMessage("null"), nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		// Not an object, e.g. a list: record it whole under "value"
+		var whole any
+		json.Unmarshal(encoded, &whole)
+		return map[string]any{"value": whole}, encoded, nil
+	}
+	return fields, encoded, nil
+}
+
+// AuditDiff returns the JSON fields that differ between before and after.
+// Either side may be nil, for creations and deletions.
+func AuditDiff(before, after any) (map[string]FieldChange, error) {
+	beforeFields, _, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, _, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := map[string]FieldChange{}
+	for name, value := range beforeFields {
+		if !auditIgnoredFields[name] && !reflect.DeepEqual(value, afterFields[name]) {
+			diff[name] = FieldChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, seen := beforeFields[name]; !seen && !auditIgnoredFields[name] && value != nil {
+			diff[name] = FieldChange{Before: nil, After: value}
+		}
+	}
+	return diff, nil
+}
+
+// NewAuditEntry describes a change by actor from before to after, either of
+// which may be nil
+func NewAuditEntry(actor, action, entityType, entityID string, before, after any) (*AuditEntry, error) {
+	_, beforeJSON, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	_, afterJSON, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := AuditDiff(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditEntry{
+		Actor:      actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		Diff:       diff,
+	}, nil
+}
+
+// AuditChange is one change to record in the audit log
+type AuditChange struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+}
+
+// ChangeAudit records a change by Actor in the audit log as part of saving
+// it, so the change is rolled back when it cannot be recorded. Changes is
+// called with what the repository saved, e.g. the updated tutor or the new
+// statements, and nil for deletions and role changes, so entries can
+// describe saved values and new IDs. The Postgres repositories write the
+// entries in the change's transaction; the in-memory ones record them through
+// Log and leave their state unchanged when that fails. A nil *ChangeAudit
+// records nothing.
+type ChangeAudit struct {
+	Actor   string
+	Changes func(saved any) []AuditChange
+	Log     AuditRepository
+}
+
+// entries builds the audit entries of the change
+func (a *ChangeAudit) entries(saved any) ([]*AuditEntry, error) {
+	entries := []*AuditEntry{}
+	for _, change := range a.Changes(saved) {
+		entry, err := NewAuditEntry(a.Actor, change.Action, change.EntityType, change.EntityID, change.Before, change.After)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// save writes the audit entries in the change's transaction
+func (a *ChangeAudit) save(ctx context.Context, tx pgx.Tx, saved any) error {
+	if a == nil {
+		return nil
+	}
+	entries, err := a.entries(saved)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := insertAuditEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record records the audit entries through Log, for repositories without a
+// transaction to share
+func (a *ChangeAudit) record(saved any) error {
+	if a == nil {
+		return nil
+	}
+	entries, err := a.entries(saved)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := a.Log.Record(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertAuditEntry saves an audit entry, setting its ID and creation time
+func insertAuditEntry(ctx context.Context, db queryRower, entry *AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, action, entity_type, entity_id, before, after, diff)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	return db.QueryRow(
+		ctx,
+		query,
+		entry.Actor,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		entry.Before,
+		entry.After,
+		entry.Diff,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// pageLimit returns the page size for a filter
+func (f AuditFilter) pageLimit() int {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	return min(limit, MaxPageSize)
+}
+
+// PostgresAuditRepository stores audit entries in the audit_log table
+type PostgresAuditRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresAuditRepository returns an audit repository backed by the pool
+func NewPostgresAuditRepository(db *pgxpool.Pool) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+// Record saves an audit entry, setting its ID and creation time
+func (r *PostgresAuditRepository) Record(entry *AuditEntry) error {
+	return insertAuditEntry(context.Background(), r.db, entry)
+}
+
+// List returns a page of audit entries matching the filter, newest first
+func (r *PostgresAuditRepository) List(filter AuditFilter) (*AuditPage, error) {
+	where := &conditions{}
+	if filter.Actor != "" {
+		where.add("lower(actor) = lower(" + where.arg(filter.Actor) + ")")
+	}
+	if filter.EntityType != "" {
+		where.add("entity_type = " + where.arg(filter.EntityType))
+	}
+	if filter.EntityID != "" {
+		where.add("entity_id = " + where.arg(filter.EntityID))
+	}
+	if filter.From != nil {
+		where.add("created_at >= " + where.arg(*filter.From))
+	}
+	if filter.To != nil {
+		where.add("created_at < " + where.arg(*filter.To))
+	}
+	if filter.Cursor != "" {
+		position, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where.add("id < " + where.arg(position.ID))
+	}
+
+	limit := filter.pageLimit()
+
+	query := fmt.Sprintf(`
+		SELECT id, actor, action, entity_type, entity_id, before, after, diff, created_at
+		FROM audit_log
+		%s
+		ORDER BY id DESC
+		LIMIT %s
+	`, where.where(), where.arg(limit+1))
+
+	rows, err := r.db.Query(context.Background(), query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Actor,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Before,
+			&entry.After,
+			&entry.Diff,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = encodeCursor("", int(page.Entries[limit-1].ID))
+	}
+	return page, nil
+}
@@ -0,0 +1,720 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Availability owner types stored in availability_slots
+const (
+	OwnerTypeTutor  = "tutor"
+	OwnerTypeClient = "client"
+)
+
+// SlotLength is the length of one slot on the weekly availability grid
+const SlotLength = 60
+
+// Minutes in a day and in a week
+const (
+	MinutesPerDay  = 24 * 60
+	MinutesPerWeek = 7 * MinutesPerDay
+)
+
+// Slot is a block of weekly availability in minutes-of-week, where 0 is
+// Sunday 00:00 in the owning profile's timezone
+type Slot struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Day returns the weekday the slot starts on
+func (s Slot) Day() time.Weekday {
+	return time.Weekday(s.Start / MinutesPerDay)
+}
+
+// ID returns the slot ID used by the availability grid, e.g. "Tue-3:00 PM"
+func (s Slot) ID() string {
+	return dayAbbreviations[s.Day()] + "-" + FormatClock(s.Start%MinutesPerDay)
+}
+
+// Contains reports whether the slot covers the given day and minute
+func (s Slot) Contains(day time.Weekday, minute int) bool {
+	minuteOfWeek := int(day)*MinutesPerDay + minute
+	return minuteOfWeek >= s.Start && minuteOfWeek < s.End
+}
+
+// Availability is a parsed weekly availability in a profile's timezone
+type Availability struct {
+	Timezone string `json:"timezone"`
+	Slots    []Slot `json:"slots"`
+	Note     string `json:"note,omitempty"`
+}
+
+// Location returns the availability's timezone, falling back to the default
+func (a Availability) Location() *time.Location {
+	loc, err := LoadTimezone(a.Timezone)
+	if err != nil {
+		loc, _ = LoadTimezone("")
+	}
+	return loc
+}
+
+// IsFreeAt reports whether any slot covers the given day and minute
+func (a Availability) IsFreeAt(day time.Weekday, minute int) bool {
+	for _, slot := range a.Slots {
+		if slot.Contains(day, minute) {
+			return true
+		}
+	}
+	return false
+}
+
+// TotalMinutes returns the number of available minutes per week
+func (a Availability) TotalMinutes() int {
+	total := 0
+	for _, slot := range a.Slots {
+		total += slot.End - slot.Start
+	}
+	return total
+}
+
+// Interval is an absolute span of time
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Intervals returns the slots as absolute times for the week containing
+// reference, shifted by weekOffset weeks. Wall-clock times are resolved in the
+// availability's own timezone, so DST transitions are taken into account.
+func (a Availability) Intervals(reference time.Time, weekOffset int) []Interval {
+	loc := a.Location()
+	weekStart := StartOfWeek(reference.In(loc)).AddDate(0, 0, 7*weekOffset)
+
+	intervals := make([]Interval, 0, len(a.Slots))
+	for _, slot := range a.Slots {
+		intervals = append(intervals, Interval{
+			Start: weekInstant(weekStart, slot.Start),
+			End:   weekInstant(weekStart, slot.End),
+		})
+	}
+	return intervals
+}
+
+// Covers reports whether the weekly availability covers the whole span from
+// start to end. Adjacent slots are joined, so a two-hour session fits two
+// consecutive hourly slots, including across midnight and the week boundary.
+func (a Availability) Covers(start, end time.Time) bool {
+	var intervals []Interval
+	for offset := -1; offset <= 1; offset++ {
+		intervals = append(intervals, a.Intervals(start, offset)...)
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	covered := start
+	for _, interval := range intervals {
+		if !interval.End.After(covered) {
+			continue
+		}
+		if interval.Start.After(covered) {
+			return false
+		}
+		covered = interval.End
+		if !covered.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// OverlapMinutes returns the number of minutes both availabilities share in
+// the week containing reference. Availabilities in different timezones are
+// compared as absolute times, including slots that wrap across the week boundary.
+func (a Availability) OverlapMinutes(other Availability, reference time.Time) int {
+	if a.Location().String() == other.Location().String() {
+		overlap := 0
+		for _, mine := range a.Slots {
+			for _, theirs := range other.Slots {
+				start := max(mine.Start, theirs.Start)
+				end := min(mine.End, theirs.End)
+				if end > start {
+					overlap += end - start
+				}
+			}
+		}
+		return overlap
+	}
+
+	var theirs []Interval
+	for offset := -1; offset <= 1; offset++ {
+		theirs = append(theirs, other.Intervals(reference, offset)...)
+	}
+
+	var overlap time.Duration
+	for _, mine := range a.Intervals(reference, 0) {
+		for _, interval := range theirs {
+			start := mine.Start
+			if interval.Start.After(start) {
+				start = interval.Start
+			}
+			end := mine.End
+			if interval.End.Before(end) {
+				end = interval.End
+			}
+			if end.After(start) {
+				overlap += end.Sub(start)
+			}
+		}
+	}
+	return int(overlap.Minutes())
+}
+
+// In renders the availability in another timezone, using the week containing
+// reference to resolve DST offsets
+func (a Availability) In(loc *time.Location, reference time.Time) Availability {
+	converted := Availability{Timezone: loc.String(), Slots: []Slot{}, Note: a.Note}
+
+	for _, interval := range a.Intervals(reference, 0) {
+		local := interval.Start.In(loc)
+		start := int(local.Weekday())*MinutesPerDay + local.Hour()*60 + local.Minute()
+		end := start + int(interval.End.Sub(interval.Start).Minutes())
+
+		// Split slots that run past Saturday midnight in the new timezone
+		if end > MinutesPerWeek {
+			converted.Slots = append(converted.Slots, Slot{Start: 0, End: end - MinutesPerWeek})
+			end = MinutesPerWeek
+		}
+		converted.Slots = append(converted.Slots, Slot{Start: start, End: end})
+	}
+
+	converted.Slots = normalizeSlots(converted.Slots)
+	return converted
+}
+
+// String returns the canonical stored form: a JSON array of grid slot IDs.
+// Prose is stored verbatim since its slots can always be parsed from it again.
+func (a Availability) String() string {
+	if a.Note != "" {
+		return a.Note
+	}
+	if len(a.Slots) == 0 {
+		return ""
+	}
+
+	ids := make([]string, 0, len(a.Slots))
+	for _, slot := range a.Slots {
+		ids = append(ids, slot.ID())
+	}
+
+	encoded, _ := json.Marshal(ids)
+	return string(encoded)
+}
+
+// SlotIDs returns the grid slot IDs of every slot
+func (a Availability) SlotIDs() []string {
+	ids := make([]string, 0, len(a.Slots))
+	for _, slot := range a.Slots {
+		ids = append(ids, slot.ID())
+	}
+	return ids
+}
+
+// DefaultTimezone returns the timezone assumed for profiles that have not set
+// one, from the DEFAULT_TIMEZONE environment variable or UTC
+func DefaultTimezone() string {
+	if tz := os.Getenv("DEFAULT_TIMEZONE"); tz != "" {
+		return tz
+	}
+	return "UTC"
+}
+
+// LoadTimezone loads an IANA timezone, using the default for an empty name
+func LoadTimezone(name string) (*time.Location, error) {
+	if strings.TrimSpace(name) == "" {
+		name = DefaultTimezone()
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	return loc, nil
+}
+
+// StartOfWeek returns midnight of the Sunday on or before t, in t's location
+func StartOfWeek(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()-int(t.Weekday()), 0, 0, 0, 0, t.Location())
+}
+
+// WeekInstant returns the absolute time of a weekday and wall-clock minute in
+// the week containing reference, in the given timezone
+func WeekInstant(loc *time.Location, reference time.Time, day time.Weekday, minute int) time.Time {
+	return weekInstant(StartOfWeek(reference.In(loc)), int(day)*MinutesPerDay+minute)
+}
+
+// weekInstant resolves a minute-of-week against a week start. time.Date
+// normalizes the day and minute, applying the zone offset in effect then.
+func weekInstant(weekStart time.Time, minuteOfWeek int) time.Time {
+	return time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day()+minuteOfWeek/MinutesPerDay,
+		0, minuteOfWeek%MinutesPerDay, 0, 0, weekStart.Location())
+}
+
+var dayAbbreviations = map[time.Weekday]string{
+	time.Sunday:    "Sun",
+	time.Monday:    "Mon",
+	time.Tuesday:   "Tue",
+	time.Wednesday: "Wed",
+	time.Thursday:  "Thu",
+	time.Friday:    "Fri",
+	time.Saturday:  "Sat",
+}
+
+// ParseDay parses a day name or abbreviation such as "Tue" or "tuesday"
+func ParseDay(value string) (time.Weekday, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for day, abbreviation := range dayAbbreviations {
+		full := strings.ToLower(day.String())
+		if value == strings.ToLower(abbreviation) || value == full {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %q", value)
+}
+
+var clockPattern = regexp.MustCompile(`(?i)^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+// ParseClock parses a time of day such as "3:00 PM", "3pm" or "15:00" into minutes from midnight
+func ParseClock(value string) (int, error) {
+	match := clockPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	switch strings.ToLower(match[3]) {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, fmt.Errorf("invalid time %q", value)
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, fmt.Errorf("invalid time %q", value)
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	return hour*60 + minute, nil
+}
+
+// FormatClock formats minutes from midnight the way the availability grid does, e.g. "3:00 PM"
+func FormatClock(minutes int) string {
+	hour := minutes / 60
+	period := "AM"
+	if hour >= 12 {
+		period = "PM"
+	}
+	hour %= 12
+	if hour == 0 {
+		hour = 12
+	}
+	return fmt.Sprintf("%d:%02d %s", hour, minutes%60, period)
+}
+
+// ParseSlotID parses a grid slot ID such as "Tue-3:00 PM"
+func ParseSlotID(id string) (Slot, error) {
+	dayPart, timePart, found := strings.Cut(id, "-")
+	if !found {
+		return Slot{}, fmt.Errorf("invalid slot %q", id)
+	}
+
+	day, err := ParseDay(dayPart)
+	if err != nil {
+		return Slot{}, fmt.Errorf("invalid slot %q: %w", id, err)
+	}
+
+	start, err := ParseClock(timePart)
+	if err != nil {
+		return Slot{}, fmt.Errorf("invalid slot %q: %w", id, err)
+	}
+
+	return newSlot(day, start)
+}
+
+// newSlot returns a grid slot starting at the given minute, validating its bounds
+func newSlot(day time.Weekday, start int) (Slot, error) {
+	if start < 0 || start+SlotLength > MinutesPerDay {
+		return Slot{}, fmt.Errorf("slot %s-%s runs past the end of the day", dayAbbreviations[day], FormatClock(start%MinutesPerDay))
+	}
+	offset := int(day) * MinutesPerDay
+	return Slot{Start: offset + start, End: offset + start + SlotLength}, nil
+}
+
+// legacySlot is the {day,time,available} object format written by older clients
+type legacySlot struct {
+	Day       string `json:"day"`
+	Time      string `json:"time"`
+	Available bool   `json:"available"`
+}
+
+// ParseAvailability parses any of the stored availability formats: a JSON
+// array of slot IDs, a JSON array of {day,time,available} objects, or free
+// prose. Structured formats are validated strictly; prose is read on a best
+// effort basis and kept as a note. Wall-clock times are read in timezone.
+func ParseAvailability(raw, timezone string) (Availability, error) {
+	if _, err := LoadTimezone(timezone); err != nil {
+		return Availability{}, err
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Availability{Timezone: timezone, Slots: []Slot{}}, nil
+	}
+
+	if strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{") {
+		slots, err := parseStructuredAvailability(raw)
+		if err != nil {
+			return Availability{}, err
+		}
+		return Availability{Timezone: timezone, Slots: normalizeSlots(slots)}, nil
+	}
+
+	return Availability{Timezone: timezone, Slots: normalizeSlots(parseProseAvailability(raw)), Note: raw}, nil
+}
+
+func parseStructuredAvailability(raw string) ([]Slot, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, fmt.Errorf("availability must be a JSON array: %w", err)
+	}
+
+	slots := make([]Slot, 0, len(items))
+	for _, item := range items {
+		var id string
+		if err := json.Unmarshal(item, &id); err == nil {
+			slot, err := ParseSlotID(id)
+			if err != nil {
+				return nil, err
+			}
+			slots = append(slots, slot)
+			continue
+		}
+
+		var legacy legacySlot
+		if err := json.Unmarshal(item, &legacy); err != nil {
+			return nil, fmt.Errorf("invalid availability entry %s", string(item))
+		}
+		if !legacy.Available {
+			continue
+		}
+
+		slot, err := ParseSlotID(legacy.Day + "-" + legacy.Time)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+
+	return slots, nil
+}
+
+// normalizeSlots sorts slots by day and time and removes duplicates
+func normalizeSlots(slots []Slot) []Slot {
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Start != slots[j].Start {
+			return slots[i].Start < slots[j].Start
+		}
+		return slots[i].End < slots[j].End
+	})
+
+	unique := make([]Slot, 0, len(slots))
+	for i, slot := range slots {
+		if i > 0 && slot == slots[i-1] {
+			continue
+		}
+		unique = append(unique, slot)
+	}
+	return unique
+}
+
+// Default hours used when prose names days without times, matching the grid
+const (
+	proseDayStart = 9 * 60
+	proseDayEnd   = 18 * 60
+)
+
+var (
+	proseClausePattern = regexp.MustCompile(`[,;.\n]+`)
+	proseDayPattern    = regexp.MustCompile(`(?i)\b(weekdays?|weekends?|every ?day|daily|any ?day|sun(?:day)?s?|mon(?:day)?s?|tue(?:s|sday)?s?|wed(?:nesday)?s?|thu(?:rs?|rsday)?s?|fri(?:day)?s?|sat(?:urday)?s?)\b`)
+	proseRangePattern  = regexp.MustCompile(`(?i)\b(\d{1,2}(?::\d{2})?\s*(?:am|pm)?)\s*(?:-|–|to|until)\s*(\d{1,2}(?::\d{2})?\s*(?:am|pm)?)`)
+	proseAfterPattern  = regexp.MustCompile(`(?i)\b(after|from)\s+(\d{1,2}(?::\d{2})?\s*(?:am|pm)?|noon)`)
+	proseUntilPattern  = regexp.MustCompile(`(?i)\b(before|until)\s+(\d{1,2}(?::\d{2})?\s*(?:am|pm)?|noon)`)
+	prosePeriods       = map[string][2]int{
+		"morning":   {9 * 60, 12 * 60},
+		"afternoon": {12 * 60, 17 * 60},
+		"evening":   {17 * 60, 21 * 60},
+		"night":     {18 * 60, 22 * 60},
+	}
+)
+
+// parseProseAvailability reads phrases like "weekdays after 3pm" or "Mon/Wed
+// evenings" into hourly grid slots. Clauses that only name days borrow the
+// times of the next clause ("Mondays, Wednesdays after 4pm").
+func parseProseAvailability(text string) []Slot {
+	var slots []Slot
+	var pendingDays []time.Weekday
+
+	for _, clause := range proseClausePattern.Split(strings.ToLower(text), -1) {
+		days := proseDays(clause)
+		ranges := proseRanges(clause)
+
+		if len(ranges) == 0 {
+			pendingDays = append(pendingDays, days...)
+			continue
+		}
+
+		days = append(pendingDays, days...)
+		pendingDays = nil
+		if len(days) == 0 {
+			days = allDays()
+		}
+		slots = append(slots, expandRanges(days, ranges)...)
+	}
+
+	if len(pendingDays) > 0 {
+		slots = append(slots, expandRanges(pendingDays, [][2]int{{proseDayStart, proseDayEnd}})...)
+	}
+
+	return slots
+}
+
+func proseDays(clause string) []time.Weekday {
+	var days []time.Weekday
+	for _, match := range proseDayPattern.FindAllString(clause, -1) {
+		word := strings.ReplaceAll(match, " ", "")
+		switch {
+		case strings.HasPrefix(word, "weekday"):
+			days = append(days, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		case strings.HasPrefix(word, "weekend"):
+			days = append(days, time.Saturday, time.Sunday)
+		case word == "everyday" || word == "daily" || word == "anyday":
+			days = append(days, allDays()...)
+		default:
+			if day, err := ParseDay(word[:3]); err == nil {
+				days = append(days, day)
+			}
+		}
+	}
+	return days
+}
+
+func proseRanges(clause string) [][2]int {
+	var ranges [][2]int
+
+	for _, match := range proseRangePattern.FindAllStringSubmatch(clause, -1) {
+		start, end, ok := parseProseRange(match[1], match[2])
+		if ok {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+
+	if match := proseAfterPattern.FindStringSubmatch(clause); match != nil && len(ranges) == 0 {
+		if start, err := parseProseClock(match[2], true); err == nil {
+			ranges = append(ranges, [2]int{start, max(start+SlotLength, prosePeriods["evening"][1])})
+		}
+	}
+
+	if match := proseUntilPattern.FindStringSubmatch(clause); match != nil && len(ranges) == 0 {
+		if end, err := parseProseClock(match[2], false); err == nil {
+			ranges = append(ranges, [2]int{min(proseDayStart, end-SlotLength), end})
+		}
+	}
+
+	if len(ranges) == 0 {
+		for period, bounds := range prosePeriods {
+			if strings.Contains(clause, period) {
+				ranges = append(ranges, bounds)
+			}
+		}
+	}
+
+	return ranges
+}
+
+// parseProseRange parses "3-5pm" style ranges, carrying the period of the end onto the start
+func parseProseRange(startText, endText string) (int, int, bool) {
+	end, err := parseProseClock(endText, true)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	startText = strings.TrimSpace(startText)
+	if !strings.HasSuffix(startText, "m") {
+		if strings.HasSuffix(strings.TrimSpace(endText), "pm") {
+			startText += "pm"
+		} else if strings.HasSuffix(strings.TrimSpace(endText), "am") {
+			startText += "am"
+		}
+	}
+
+	start, err := parseProseClock(startText, true)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	// "11-1pm" wraps through noon
+	if start >= end && start >= 12*60 {
+		start -= 12 * 60
+	}
+	if start >= end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// parseProseClock parses a loose clock time. Bare hours are read as afternoon
+// times when they would otherwise fall before the usual tutoring day.
+func parseProseClock(text string, preferAfternoon bool) (int, error) {
+	text = strings.TrimSpace(text)
+	if text == "noon" {
+		return 12 * 60, nil
+	}
+
+	minutes, err := ParseClock(text)
+	if err != nil {
+		return 0, err
+	}
+
+	if preferAfternoon && !strings.HasSuffix(text, "m") && minutes < 8*60 {
+		minutes += 12 * 60
+	}
+	return minutes, nil
+}
+
+// expandRanges turns day/time ranges into hourly grid slots
+func expandRanges(days []time.Weekday, ranges [][2]int) []Slot {
+	var slots []Slot
+	for _, day := range days {
+		for _, bounds := range ranges {
+			for start := bounds[0] - bounds[0]%SlotLength; start < bounds[1]; start += SlotLength {
+				if slot, err := newSlot(day, start); err == nil {
+					slots = append(slots, slot)
+				}
+			}
+		}
+	}
+	return slots
+}
+
+func allDays() []time.Weekday {
+	return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+}
+
+// SaveAvailabilitySlots replaces the stored slots for a tutor or client
+func SaveAvailabilitySlots(db beginner, ownerType string, ownerID int, availability Availability) error {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleteQuery := `DELETE FROM availability_slots WHERE owner_type = $1 AND owner_id = $2`
+	if _, err := tx.Exec(ctx, deleteQuery, ownerType, ownerID); err != nil {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO availability_slots (owner_type, owner_id, timezone, start_minute_of_week, end_minute_of_week)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	timezone := availability.Location().String()
+	for _, slot := range availability.Slots {
+		if _, err := tx.Exec(ctx, insertQuery, ownerType, ownerID, timezone, slot.Start, slot.End); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// syncAvailabilitySlots parses a raw availability column and stores its slots
+func syncAvailabilitySlots(db beginner, ownerType string, ownerID int, raw, timezone string) error {
+	availability, err := ParseAvailability(raw, timezone)
+	if err != nil {
+		return err
+	}
+	return SaveAvailabilitySlots(db, ownerType, ownerID, availability)
+}
+
+// BackfillAvailabilitySlots parses the availability column of every tutor and
+// client that has no stored slots yet. Rows that fail to parse are logged and skipped.
+func BackfillAvailabilitySlots(db *pgxpool.Pool) error {
+	query := `
+		SELECT 'tutor', id, availability, COALESCE(timezone, '') FROM tutors t
+		WHERE COALESCE(availability, '') <> ''
+		  AND NOT EXISTS (SELECT 1 FROM availability_slots s WHERE s.owner_type = 'tutor' AND s.owner_id = t.id)
+		UNION ALL
+		SELECT 'client', id, availability, COALESCE(timezone, '') FROM clients c
+		WHERE COALESCE(availability, '') <> ''
+		  AND NOT EXISTS (SELECT 1 FROM availability_slots s WHERE s.owner_type = 'client' AND s.owner_id = c.id)
+	`
+
+	rows, err := db.Query(context.Background(), query)
+	if err != nil {
+		return err
+	}
+
+	type pending struct {
+		ownerType string
+		ownerID   int
+		raw       string
+		timezone  string
+	}
+	var backlog []pending
+	for rows.Next() {
+		var row pending
+		if err := rows.Scan(&row.ownerType, &row.ownerID, &row.raw, &row.timezone); err != nil {
+			rows.Close()
+			return err
+		}
+		backlog = append(backlog, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, row := range backlog {
+		if err := syncAvailabilitySlots(db, row.ownerType, row.ownerID, row.raw, row.timezone); err != nil {
+			log.Printf("Skipping availability backfill for %s %d: %v", row.ownerType, row.ownerID, err)
+		}
+	}
+
+	if len(backlog) > 0 {
+		log.Printf("Backfilled availability slots for %d profiles", len(backlog))
+	}
+	return nil
+}
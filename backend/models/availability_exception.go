@@ -0,0 +1,237 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"tutor-backend/calendar"
+)
+
+// ExceptionSourceICS marks exceptions imported from an uploaded .ics file
+const ExceptionSourceICS = "ics"
+
+// ImportHorizon is how far ahead recurring busy events are expanded on import
+const ImportHorizon = 26 * 7 * 24 * time.Hour
+
+// ErrTutorBusy is returned when a session overlaps a busy block the tutor
+// imported from their own calendar
+var ErrTutorBusy = errors.New("the tutor is busy at that time")
+
+// AvailabilityException is a span of time a profile is busy despite its
+// weekly availability, such as an event imported from a personal calendar.
+// Event details are not kept, only the time they block.
+type AvailabilityException struct {
+	ID        int       `json:"id"`
+	OwnerType string    `json:"owner_type"`
+	OwnerID   int       `json:"owner_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BusyBlocks expands imported events into the busy spans overlapping the
+// range from-to, merging spans that overlap. Cancelled and transparent events
+// block nothing; recurring events skip their EXDATEs and the occurrences
+// other events override. It also describes the events it could not expand,
+// such as ones with unsupported recurrence rules.
+func BusyBlocks(events []calendar.ImportedEvent, from, to time.Time) ([]Interval, []string) {
+	overridden := map[string]map[int64]bool{}
+	for _, event := range events {
+		if event.RecurrenceID != nil {
+			if overridden[event.UID] == nil {
+				overridden[event.UID] = map[int64]bool{}
+			}
+			overridden[event.UID][event.RecurrenceID.Unix()] = true
+		}
+	}
+
+	blocks := []Interval{}
+	skipped := []string{}
+	for _, event := range events {
+		if event.Status == calendar.StatusCancelled || event.Transparent || !event.End.After(event.Start) {
+			continue
+		}
+
+		starts := []time.Time{event.Start}
+		excluded := map[int64]bool{}
+		if event.RRule != "" && event.RecurrenceID == nil {
+			rule, err := ParseImportedRule(event.RRule)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s: %s", describeEvent(event), err))
+				continue
+			}
+			starts = rule.ImportedOccurrences(event.Start, event.Location, to)
+			for _, exdate := range event.ExDates {
+				excluded[exdate.Unix()] = true
+			}
+			for start := range overridden[event.UID] {
+				excluded[start] = true
+			}
+		}
+
+		length := event.End.Sub(event.Start)
+		days := event.End.In(event.Location).Sub(event.Start.In(event.Location)).Hours() / 24
+		for _, start := range starts {
+			if excluded[start.Unix()] {
+				continue
+			}
+			end := start.Add(length)
+			if event.AllDay {
+				// Whole days stay whole across DST changes
+				end = start.AddDate(0, 0, int(days+0.5))
+			}
+			if start.Before(to) && end.After(from) {
+				blocks = append(blocks, Interval{Start: start, End: end})
+			}
+		}
+	}
+
+	return mergeIntervals(blocks), skipped
+}
+
+// describeEvent names an event in import reports
+func describeEvent(event calendar.ImportedEvent) string {
+	if event.Summary != "" {
+		return fmt.Sprintf("%q on %s", event.Summary, event.Start.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("event on %s", event.Start.Format("2006-01-02"))
+}
+
+// mergeIntervals sorts intervals and joins the ones that overlap or touch
+func mergeIntervals(intervals []Interval) []Interval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	merged := []Interval{}
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// Without returns the availability for the week containing reference with
+// the busy spans removed. The result may have slots that do not line up with
+// the hourly grid, so it is for computing overlaps rather than for storing.
+func (a Availability) Without(busy []Interval, reference time.Time) Availability {
+	if len(busy) == 0 {
+		return a
+	}
+
+	loc := a.Location()
+	remaining := Availability{Timezone: a.Timezone, Slots: []Slot{}, Note: a.Note}
+	for _, interval := range a.Intervals(reference, 0) {
+		pieces := []Interval{interval}
+		for _, block := range busy {
+			var next []Interval
+			for _, piece := range pieces {
+				if !block.Start.Before(piece.End) || !block.End.After(piece.Start) {
+					next = append(next, piece)
+					continue
+				}
+				if block.Start.After(piece.Start) {
+					next = append(next, Interval{Start: piece.Start, End: block.Start})
+				}
+				if block.End.Before(piece.End) {
+					next = append(next, Interval{Start: block.End, End: piece.End})
+				}
+			}
+			pieces = next
+		}
+
+		for _, piece := range pieces {
+			local := piece.Start.In(loc)
+			start := int(local.Weekday())*MinutesPerDay + local.Hour()*60 + local.Minute()
+			end := start + int(piece.End.Sub(piece.Start).Minutes())
+			if end > start {
+				remaining.Slots = append(remaining.Slots, Slot{Start: start, End: min(end, MinutesPerWeek)})
+			}
+		}
+	}
+	return remaining
+}
+
+// AvailabilityExceptions returns the exceptions of the tutors that overlap
+// the range from-to, earliest first, by tutor ID
+func (r *PostgresTutorRepository) AvailabilityExceptions(tutorIDs []int, from, to time.Time) (map[int][]AvailabilityException, error) {
+	query := `
+		SELECT id, owner_type, owner_id, starts_at, ends_at, source, created_at
+		FROM availability_exceptions
+		WHERE owner_type = $1 AND owner_id = ANY($2) AND starts_at < $4 AND ends_at > $3
+		ORDER BY starts_at, id
+	`
+
+	rows, err := r.db.Query(context.Background(), query, OwnerTypeTutor, tutorIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exceptions := map[int][]AvailabilityException{}
+	for rows.Next() {
+		var exception AvailabilityException
+		err := rows.Scan(
+			&exception.ID,
+			&exception.OwnerType,
+			&exception.OwnerID,
+			&exception.StartsAt,
+			&exception.EndsAt,
+			&exception.Source,
+			&exception.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		exceptions[exception.OwnerID] = append(exceptions[exception.OwnerID], exception)
+	}
+
+	return exceptions, rows.Err()
+}
+
+// ReplaceAvailabilityExceptions replaces the tutor's exceptions from a source
+// with the given busy spans
+func (r *PostgresTutorRepository) ReplaceAvailabilityExceptions(tutorID int, source string, blocks []Interval) ([]AvailabilityException, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	deleteQuery := `DELETE FROM availability_exceptions WHERE owner_type = $1 AND owner_id = $2 AND source = $3`
+	if _, err := tx.Exec(ctx, deleteQuery, OwnerTypeTutor, tutorID, source); err != nil {
+		return nil, err
+	}
+
+	insertQuery := `
+		INSERT INTO availability_exceptions (owner_type, owner_id, starts_at, ends_at, source)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	exceptions := make([]AvailabilityException, 0, len(blocks))
+	for _, block := range blocks {
+		exception := AvailabilityException{
+			OwnerType: OwnerTypeTutor,
+			OwnerID:   tutorID,
+			StartsAt:  block.Start,
+			EndsAt:    block.End,
+			Source:    source,
+		}
+		err := tx.QueryRow(ctx, insertQuery, OwnerTypeTutor, tutorID, block.Start, block.End, source).
+			Scan(&exception.ID, &exception.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, exception)
+	}
+
+	return exceptions, tx.Commit(ctx)
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"tutor-backend/calendar"
+)
+
+func TestBusyBlocksExpandsImportedRules(t *testing.T) {
+	// Monday 2026-03-02 09:00 UTC
+	monday := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	from := monday
+	to := monday.AddDate(0, 0, 14)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		rrule string
+		want  []time.Time
+	}{
+		{
+			name:  "daily",
+			start: monday,
+			rrule: "FREQ=DAILY;INTERVAL=3;COUNT=4",
+			want: []time.Time{
+				monday,
+				monday.AddDate(0, 0, 3),
+				monday.AddDate(0, 0, 6),
+				monday.AddDate(0, 0, 9),
+			},
+		},
+		{
+			name:  "daily on listed days",
+			start: monday,
+			rrule: "FREQ=DAILY;BYDAY=MO",
+			want:  []time.Time{monday, monday.AddDate(0, 0, 7)},
+		},
+		{
+			name:  "start off the rule's days",
+			start: monday,
+			rrule: "FREQ=WEEKLY;BYDAY=WE,FR;COUNT=4",
+			want: []time.Time{
+				monday,
+				monday.AddDate(0, 0, 2),
+				monday.AddDate(0, 0, 4),
+				monday.AddDate(0, 0, 9),
+			},
+		},
+		{
+			name:  "count beyond the strict cap",
+			start: monday,
+			rrule: "FREQ=DAILY;COUNT=500",
+			want: func() []time.Time {
+				starts := []time.Time{}
+				for day := 0; day < 14; day++ {
+					starts = append(starts, monday.AddDate(0, 0, day))
+				}
+				return starts
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := calendar.ImportedEvent{
+				UID:      tt.name,
+				Start:    tt.start,
+				End:      tt.start.Add(time.Hour),
+				Location: time.UTC,
+				RRule:    tt.rrule,
+			}
+			blocks, skipped := BusyBlocks([]calendar.ImportedEvent{event}, from, to)
+			if len(skipped) != 0 {
+				t.Fatalf("skipped = %v, want none", skipped)
+			}
+			if len(blocks) != len(tt.want) {
+				t.Fatalf("got %d blocks, want %d: %v", len(blocks), len(tt.want), blocks)
+			}
+			for i, block := range blocks {
+				if !block.Start.Equal(tt.want[i]) || !block.End.Equal(tt.want[i].Add(time.Hour)) {
+					t.Errorf("block %d = %v-%v, want start %v", i, block.Start, block.End, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceRuleStaysStrict(t *testing.T) {
+	for _, raw := range []string{"FREQ=DAILY", "FREQ=WEEKLY;COUNT=500"} {
+		if _, err := ParseRecurrenceRule(raw); err == nil {
+			t.Errorf("ParseRecurrenceRule(%q) succeeded, want an error", raw)
+		}
+		if _, err := ParseImportedRule(raw); err != nil {
+			t.Errorf("ParseImportedRule(%q): %v", raw, err)
+		}
+	}
+}
+
+func TestBusyBlocksFromParsedCalendar(t *testing.T) {
+	// Mondays at 9:00 in New York from 2 March 2026, which moves to summer
+	// time on 8 March, so the UTC start moves from 14:00 to 13:00
+	weekly := func(lines ...string) string {
+		return strings.Join(append([]string{
+			"BEGIN:VCALENDAR",
+			"BEGIN:VEVENT",
+			"UID:standup",
+			"DTSTART;TZID=America/New_York:20260302T090000",
+			"DTEND;TZID=America/New_York:20260302T100000",
+		}, append(lines, "END:VEVENT", "END:VCALENDAR")...), "\r\n")
+	}
+	monday := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC) }
+	from, to := monday(1, 0), monday(31, 0)
+
+	tests := []struct {
+		name string
+		text string
+		want []time.Time
+	}{
+		{
+			name: "weekly keeps its wall-clock time across DST",
+			text: weekly("RRULE:FREQ=WEEKLY"),
+			want: []time.Time{monday(2, 14), monday(9, 13), monday(16, 13), monday(23, 13), monday(30, 13)},
+		},
+		{
+			name: "count",
+			text: weekly("RRULE:FREQ=WEEKLY;COUNT=2"),
+			want: []time.Time{monday(2, 14), monday(9, 13)},
+		},
+		{
+			name: "until a UTC time includes an occurrence starting then",
+			text: weekly("RRULE:FREQ=WEEKLY;UNTIL=20260316T130000Z"),
+			want: []time.Time{monday(2, 14), monday(9, 13), monday(16, 13)},
+		},
+		{
+			name: "until a date includes the whole day",
+			text: weekly("RRULE:FREQ=WEEKLY;UNTIL=20260309"),
+			want: []time.Time{monday(2, 14), monday(9, 13)},
+		},
+		{
+			name: "daily on weekdays",
+			text: weekly("RRULE:FREQ=DAILY;BYDAY=MO,WE;COUNT=3"),
+			want: []time.Time{monday(2, 14), monday(4, 14), monday(9, 13)},
+		},
+		{
+			name: "exdates in the event's timezone",
+			text: weekly("RRULE:FREQ=WEEKLY;COUNT=4", "EXDATE;TZID=America/New_York:20260309T090000,20260316T090000"),
+			want: []time.Time{monday(2, 14), monday(23, 13)},
+		},
+		{
+			name: "an override moves one occurrence",
+			text: weekly("RRULE:FREQ=WEEKLY;COUNT=2", "END:VEVENT",
+				"BEGIN:VEVENT",
+				"UID:standup",
+				"RECURRENCE-ID;TZID=America/New_York:20260309T090000",
+				"DTSTART:20260310T180000Z",
+				"DURATION:PT1H",
+			),
+			want: []time.Time{monday(2, 14), monday(10, 18)},
+		},
+		{
+			name: "cancelled",
+			text: weekly("RRULE:FREQ=WEEKLY", "STATUS:CANCELLED"),
+			want: []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := calendar.Parse(strings.NewReader(tt.text), time.UTC)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			blocks, skipped := BusyBlocks(events, from, to)
+			if len(skipped) != 0 {
+				t.Fatalf("skipped = %v, want none", skipped)
+			}
+			starts := []time.Time{}
+			for _, block := range blocks {
+				if block.End.Sub(block.Start) != time.Hour {
+					t.Errorf("block %v-%v does not last an hour", block.Start, block.End)
+				}
+				starts = append(starts, block.Start.UTC())
+			}
+			if !reflect.DeepEqual(starts, tt.want) {
+				t.Errorf("starts = %v, want %v", starts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAvailabilityAcrossDSTChanges(t *testing.T) {
+	newYork, err := LoadTimezone("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadTimezone: %v", err)
+	}
+	london, err := LoadTimezone("Europe/London")
+	if err != nil {
+		t.Fatalf("LoadTimezone: %v", err)
+	}
+	tutor, err := ParseAvailability(`["Tue-10:00 AM"]`, "America/New_York")
+	if err != nil {
+		t.Fatalf("ParseAvailability: %v", err)
+	}
+	client, err := ParseAvailability(`["Tue-3:00 PM"]`, "Europe/London")
+	if err != nil {
+		t.Fatalf("ParseAvailability: %v", err)
+	}
+
+	// New York moves to summer time on 8 March 2026 and London on 29 March,
+	// so for three weeks they are four hours apart instead of five
+	tests := []struct {
+		name      string
+		reference time.Time
+		inLondon  []string
+		overlap   int
+	}{
+		{"both on winter time", time.Date(2026, 1, 13, 12, 0, 0, 0, time.UTC), []string{"Tue-3:00 PM"}, 60},
+		{"only New York on summer time", time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC), []string{"Tue-2:00 PM"}, 0},
+		{"both on summer time", time.Date(2026, 4, 14, 12, 0, 0, 0, time.UTC), []string{"Tue-3:00 PM"}, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tutor.In(london, tt.reference).SlotIDs(); !reflect.DeepEqual(got, tt.inLondon) {
+				t.Errorf("in London = %q, want %q", got, tt.inLondon)
+			}
+			if got := client.OverlapMinutes(tutor, tt.reference); got != tt.overlap {
+				t.Errorf("OverlapMinutes = %d, want %d", got, tt.overlap)
+			}
+			if got := tutor.In(london, tt.reference).In(newYork, tt.reference).SlotIDs(); !reflect.DeepEqual(got, tutor.SlotIDs()) {
+				t.Errorf("round trip = %q, want %q", got, tutor.SlotIDs())
+			}
+		})
+	}
+}
+
+func TestIntervalsOnTheDayClocksGoForward(t *testing.T) {
+	// The 1:00 and 2:00 AM slots on Sunday 8 March 2026 in New York span
+	// only one real hour, since 2:00 AM is skipped
+	availability, err := ParseAvailability(`["Sun-1:00 AM", "Sun-2:00 AM", "Mon-9:00 AM"]`, "America/New_York")
+	if err != nil {
+		t.Fatalf("ParseAvailability: %v", err)
+	}
+
+	var total time.Duration
+	for _, interval := range availability.Intervals(time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC), 0) {
+		total += interval.End.Sub(interval.Start)
+	}
+	if total != 2*time.Hour {
+		t.Errorf("intervals add up to %v, want 2h", total)
+	}
+
+	tests := []struct {
+		start, end time.Time
+		want       bool
+	}{
+		{time.Date(2026, 3, 8, 6, 0, 0, 0, time.UTC), time.Date(2026, 3, 8, 7, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 3, 8, 6, 0, 0, 0, time.UTC), time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC), false},
+		{time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 15, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := availability.Covers(tt.start, tt.end); got != tt.want {
+			t.Errorf("Covers(%v, %v) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
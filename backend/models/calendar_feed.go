@@ -0,0 +1,96 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CalendarFeed is a user's iCalendar subscription. The feed URL carries a
+// secret token, of which only the hash is stored.
+type CalendarFeed struct {
+	Email     string    `json:"email"`
+	TokenHash string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewCalendarToken returns a random secret token for a feed URL
+func NewCalendarToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashCalendarToken returns the hash stored in place of a feed token
+func HashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PostgresCalendarFeedRepository stores feeds in the calendar_feeds table
+type PostgresCalendarFeedRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresCalendarFeedRepository returns a feed repository backed by the pool
+func NewPostgresCalendarFeedRepository(db *pgxpool.Pool) *PostgresCalendarFeedRepository {
+	return &PostgresCalendarFeedRepository{db: db}
+}
+
+// GetByEmail returns the user's feed
+func (r *PostgresCalendarFeedRepository) GetByEmail(email string) (*CalendarFeed, error) {
+	query := `SELECT email, token_hash, created_at FROM calendar_feeds WHERE email = $1`
+
+	var feed CalendarFeed
+	err := r.db.QueryRow(context.Background(), query, strings.ToLower(email)).Scan(&feed.Email, &feed.TokenHash, &feed.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// GetByTokenHash returns the feed whose token has the hash
+func (r *PostgresCalendarFeedRepository) GetByTokenHash(tokenHash string) (*CalendarFeed, error) {
+	query := `SELECT email, token_hash, created_at FROM calendar_feeds WHERE token_hash = $1`
+
+	var feed CalendarFeed
+	err := r.db.QueryRow(context.Background(), query, tokenHash).Scan(&feed.Email, &feed.TokenHash, &feed.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// Save creates the user's feed or replaces its token
+func (r *PostgresCalendarFeedRepository) Save(feed *CalendarFeed) error {
+	query := `
+		INSERT INTO calendar_feeds (email, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (email) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = CURRENT_TIMESTAMP
+		RETURNING email, created_at
+	`
+
+	return r.db.QueryRow(context.Background(), query, strings.ToLower(feed.Email), feed.TokenHash).
+		Scan(&feed.Email, &feed.CreatedAt)
+}
+
+// Delete removes the user's feed
+func (r *PostgresCalendarFeedRepository) Delete(email string) error {
+	result, err := r.db.Exec(context.Background(), `DELETE FROM calendar_feeds WHERE email = $1`, strings.ToLower(email))
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -3,87 +3,136 @@ package models
 import (
 	"context"
 	"time"
-	"tutor-backend/database"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Client represents a client in the system
 type Client struct {
-	ID           int       `json:"id"`
-	Name         string    `json:"name"`
-	Email        string    `json:"email"`
-	Subjects     []string  `json:"subjects"`
-	Budget       float64   `json:"budget"`
-	Description  string    `json:"description"`
-	Language     string    `json:"language"`
-	Location     string    `json:"location"`
-	Availability string    `json:"availability"`
-	Education    string    `json:"education"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email"`
+	Subjects     []string   `json:"subjects"`
+	Budget       float64    `json:"budget"`
+	Description  string     `json:"description"`
+	Language     string     `json:"language"`
+	Location     string     `json:"location"`
+	Availability string     `json:"availability"`
+	Education    string     `json:"education"`
+	Active       *bool      `json:"active,omitempty"`
+	Timezone     string     `json:"timezone"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
 
-// GetClients returns all clients from the database
-func GetClients() ([]Client, error) {
-	db := database.GetDB()
-	if db == nil {
-		// Return sample data if database is not available
-		return getSampleClients(), nil
-	}
+// clientColumns is the column list scanned by scanClient
+const clientColumns = `id, name, email, subjects, budget, description, language, location, availability, education, active, timezone, created_at, updated_at, deleted_at`
 
-	query := `
-		SELECT id, name, email, subjects, budget, description, language, location, availability, education, created_at, updated_at
-		FROM clients 
-		ORDER BY created_at DESC
-	`
+// scanClient scans a row selected with clientColumns
+func scanClient(row pgx.Row) (Client, error) {
+	var client Client
+	err := row.Scan(
+		&client.ID,
+		&client.Name,
+		&client.Email,
+		&client.Subjects,
+		&client.Budget,
+		&client.Description,
+		&client.Language,
+		&client.Location,
+		&client.Availability,
+		&client.Education,
+		&client.Active,
+		&client.Timezone,
+		&client.CreatedAt,
+		&client.UpdatedAt,
+		&client.DeletedAt,
+	)
+	return client, err
+}
+
+// IsActive reports whether the client is still looking for a tutor.
+// Clients created before the active flag existed are treated as active.
+func (c *Client) IsActive() bool {
+	return c.Active == nil || *c.Active
+}
 
-	rows, err := db.Query(context.Background(), query)
+// PostgresClientRepository stores clients in the clients table, with their
+// parsed availability in availability_slots
+type PostgresClientRepository struct {
+	db       *pgxpool.Pool
+	subjects SubjectRepository
+}
+
+// NewPostgresClientRepository returns a client repository backed by the pool,
+// normalizing subjects through the taxonomy in subjects
+func NewPostgresClientRepository(db *pgxpool.Pool, subjects SubjectRepository) *PostgresClientRepository {
+	return &PostgresClientRepository{db: db, subjects: subjects}
+}
+
+// list runs a query selecting clientColumns and scans every row
+func (r *PostgresClientRepository) list(query string, args ...any) ([]Client, error) {
+	rows, err := r.db.Query(context.Background(), query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var clients []Client
+	clients := []Client{}
 	for rows.Next() {
-		var client Client
-		err := rows.Scan(
-			&client.ID,
-			&client.Name,
-			&client.Email,
-			&client.Subjects,
-			&client.Budget,
-			&client.Description,
-			&client.Language,
-			&client.Location,
-			&client.Availability,
-			&client.Education,
-			&client.CreatedAt,
-			&client.UpdatedAt,
-		)
+		client, err := scanClient(rows)
 		if err != nil {
 			return nil, err
 		}
 		clients = append(clients, client)
 	}
 
-	return clients, nil
+	return clients, rows.Err()
 }
 
-// CreateClient saves a new client to the database
-func CreateClient(client *Client) error {
-	db := database.GetDB()
-	if db == nil {
-		return nil // Skip database operations if not available
+// List returns all clients that are not deleted, newest first
+func (r *PostgresClientRepository) List() ([]Client, error) {
+	return r.list(`
+		SELECT ` + clientColumns + `
+		FROM clients 
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+	`)
+}
+
+// AvailableAt returns the clients whose weekly availability covers the given instant,
+// evaluated in each client's own timezone
+func (r *PostgresClientRepository) AvailableAt(at time.Time) ([]Client, error) {
+	return r.list(`
+		SELECT `+clientColumns+`
+		FROM clients 
+		WHERE deleted_at IS NULL AND EXISTS (
+			SELECT 1 FROM availability_slots s
+			WHERE s.owner_type = 'client' AND s.owner_id = clients.id
+			  AND minute_of_week($1, s.timezone) >= s.start_minute_of_week
+			  AND minute_of_week($1, s.timezone) < s.end_minute_of_week
+		)
+		ORDER BY created_at DESC
+	`, at)
+}
+
+// Create saves a new client to the database
+func (r *PostgresClientRepository) Create(client *Client) error {
+	subjects, err := normalizeSubjects(r.subjects, client.Subjects)
+	if err != nil {
+		return err
 	}
+	client.Subjects = subjects
 
 	query := `
-		INSERT INTO clients (name, email, subjects, budget, description, language, location, availability, education)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, created_at, updated_at
+		INSERT INTO clients (name, email, subjects, budget, description, language, location, availability, education, active, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, TRUE), $11)
+		RETURNING id, active, created_at, updated_at
 	`
 
-	err := db.QueryRow(
+	err = r.db.QueryRow(
 		context.Background(),
 		query,
 		client.Name,
@@ -95,39 +144,26 @@ func CreateClient(client *Client) error {
 		client.Location,
 		client.Availability,
 		client.Education,
-	).Scan(&client.ID, &client.CreatedAt, &client.UpdatedAt)
-
-	return err
-}
+		client.Active,
+		client.Timezone,
+	).Scan(&client.ID, &client.Active, &client.CreatedAt, &client.UpdatedAt)
 
-// GetClientByID retrieves a client by ID from the database
-func GetClientByID(id int) (*Client, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, pgx.ErrNoRows
+	if err != nil {
+		return err
 	}
 
+	return syncAvailabilitySlots(r.db, OwnerTypeClient, client.ID, client.Availability, client.Timezone)
+}
+
+// GetByID retrieves a client by ID from the database
+func (r *PostgresClientRepository) GetByID(id int) (*Client, error) {
 	query := `
-		SELECT id, name, email, subjects, budget, description, language, location, availability, education, created_at, updated_at
+		SELECT ` + clientColumns + `
 		FROM clients 
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	var client Client
-	err := db.QueryRow(context.Background(), query, id).Scan(
-		&client.ID,
-		&client.Name,
-		&client.Email,
-		&client.Subjects,
-		&client.Budget,
-		&client.Description,
-		&client.Language,
-		&client.Location,
-		&client.Availability,
-		&client.Education,
-		&client.CreatedAt,
-		&client.UpdatedAt,
-	)
+	client, err := scanClient(r.db.QueryRow(context.Background(), query, id))
 
 	if err != nil {
 		return nil, err
@@ -136,41 +172,15 @@ func GetClientByID(id int) (*Client, error) {
 	return &client, nil
 }
 
-// GetClientByEmail retrieves a client by email from the database
-func GetClientByEmail(email string) (*Client, error) {
-	db := database.GetDB()
-	if db == nil {
-		// Check sample data if database is not available
-		clients := getSampleClients()
-		for _, client := range clients {
-			if client.Email == email {
-				return &client, nil
-			}
-		}
-		return nil, pgx.ErrNoRows
-	}
-
+// GetByEmail retrieves a client by email from the database
+func (r *PostgresClientRepository) GetByEmail(email string) (*Client, error) {
 	query := `
-		SELECT id, name, email, subjects, budget, description, language, location, availability, education, created_at, updated_at
+		SELECT ` + clientColumns + `
 		FROM clients 
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`
 
-	var client Client
-	err := db.QueryRow(context.Background(), query, email).Scan(
-		&client.ID,
-		&client.Name,
-		&client.Email,
-		&client.Subjects,
-		&client.Budget,
-		&client.Description,
-		&client.Language,
-		&client.Location,
-		&client.Availability,
-		&client.Education,
-		&client.CreatedAt,
-		&client.UpdatedAt,
-	)
+	client, err := scanClient(r.db.QueryRow(context.Background(), query, email))
 
 	if err != nil {
 		return nil, err
@@ -179,24 +189,32 @@ func GetClientByEmail(email string) (*Client, error) {
 	return &client, nil
 }
 
-// UpdateClient updates an existing client in the database
-func UpdateClient(client *Client) error {
-	db := database.GetDB()
-	if db == nil {
-		return nil // Skip database operations if not available
+// Update updates an existing client in the database
+func (r *PostgresClientRepository) Update(client *Client, audit *ChangeAudit) error {
+	subjects, err := normalizeSubjects(r.subjects, client.Subjects)
+	if err != nil {
+		return err
 	}
+	client.Subjects = subjects
 
 	query := `
 		UPDATE clients 
 		SET name = $2, email = $3, subjects = $4, budget = $5, description = $6, 
 		    language = $7, location = $8, availability = $9, education = $10, 
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1
-		RETURNING updated_at
+		    active = COALESCE($11, active), timezone = $12, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING active, updated_at
 	`
 
-	err := db.QueryRow(
-		context.Background(),
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(
+		ctx,
 		query,
 		client.ID,
 		client.Name,
@@ -208,21 +226,38 @@ func UpdateClient(client *Client) error {
 		client.Location,
 		client.Availability,
 		client.Education,
-	).Scan(&client.UpdatedAt)
+		client.Active,
+		client.Timezone,
+	).Scan(&client.Active, &client.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := syncAvailabilitySlots(tx, OwnerTypeClient, client.ID, client.Availability, client.Timezone); err != nil {
+		return err
+	}
+	if err := audit.save(ctx, tx, client); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-// DeleteClient removes a client from the database
-func DeleteClient(id int) error {
-	db := database.GetDB()
-	if db == nil {
-		return nil // Skip database operations if not available
+// Delete soft-deletes a client. The profile is hidden from every query
+// until it is restored, and purged for good after the retention window.
+// Its open pairings end with it and stay ended if it is restored.
+func (r *PostgresClientRepository) Delete(id int, audit *ChangeAudit) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	query := `DELETE FROM clients WHERE id = $1`
+	query := `UPDATE clients SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
 
-	result, err := db.Exec(context.Background(), query, id)
+	result, err := tx.Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -232,27 +267,54 @@ func DeleteClient(id int) error {
 		return pgx.ErrNoRows
 	}
 
-	return nil
+	if err := endPairings(ctx, tx, "client_id", id); err != nil {
+		return err
+	}
+	if err := audit.save(ctx, tx, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListDeleted returns soft-deleted clients, most recently deleted first
+func (r *PostgresClientRepository) ListDeleted() ([]Client, error) {
+	return r.list(`
+		SELECT ` + clientColumns + `
+		FROM clients
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`)
 }
 
-// getSampleClients returns sample client data (fallback when database is not available)
-func getSampleClients() []Client {
-	return []Client{
-		{
-			ID:          1,
-			Name:        "Mike Davis",
-			Email:       "mike.davis@email.com",
-			Subjects:    []string{"Mathematics"},
-			Budget:      60.0,
-			Description: "Looking for advanced calculus help",
-		},
-		{
-			ID:          2,
-			Name:        "Emily Wilson",
-			Email:       "emily.wilson@email.com",
-			Subjects:    []string{"English", "Writing"},
-			Budget:      50.0,
-			Description: "Need help with essay writing and literature analysis",
-		},
+// Restore undoes a soft delete and returns the restored client
+func (r *PostgresClientRepository) Restore(id int, audit *ChangeAudit) (*Client, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE clients
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING ` + clientColumns
+
+	client, err := scanClient(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := audit.save(ctx, tx, &client); err != nil {
+		return nil, err
 	}
+
+	return &client, tx.Commit(ctx)
+}
+
+// Purge permanently removes clients soft-deleted before the cutoff, along
+// with their availability slots
+func (r *PostgresClientRepository) Purge(cutoff time.Time) (int64, error) {
+	return purgeProfiles(r.db, "clients", OwnerTypeClient, cutoff)
 }
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ClientResult is a client in full-text search results. Snippet is
+// HTML-escaped with matches in <mark> tags.
+type ClientResult struct {
+	Client
+	Rank    *float32 `json:"rank,omitempty"`
+	Snippet string   `json:"snippet,omitempty"`
+}
+
+// clientSearchText is the text shown in snippets, escaped so it is safe to render as HTML
+const clientSearchText = `replace(replace(replace(COALESCE(description, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+
+// Search returns clients whose description matches a full-text query,
+// most relevant first
+func (r *PostgresClientRepository) Search(query string, limit int) ([]ClientResult, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	where := &conditions{}
+	where.add("deleted_at IS NULL")
+	tsquery := "websearch_to_tsquery('english', " + where.arg(strings.TrimSpace(query)) + ")"
+	where.add("search_vector @@ " + tsquery)
+
+	sql := fmt.Sprintf(`
+		SELECT %s, ts_rank_cd(search_vector, %s) AS search_rank,
+		       ts_headline('english', %s, %s, '%s') AS snippet
+		FROM clients
+		%s
+		ORDER BY search_rank DESC, id DESC
+		LIMIT %s
+	`, clientColumns, tsquery, clientSearchText, tsquery, headlineOptions, where.where(), where.arg(limit))
+
+	rows, err := r.db.Query(context.Background(), sql, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []ClientResult{}
+	for rows.Next() {
+		var result ClientResult
+		result.Client, err = scanClient(searchRow{rows, &result.Rank, &result.Snippet})
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, result)
+	}
+
+	return clients, rows.Err()
+}
@@ -0,0 +1,384 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Invoice statuses
+const (
+	InvoiceStatusOpen = "open"
+	InvoiceStatusPaid = "paid"
+)
+
+// Invoice bills a client for completed sessions, one line per session at the
+// rate in effect when it was booked. Payments against the invoice add to
+// Paid; it is paid once Paid reaches Total.
+type Invoice struct {
+	ID       int           `json:"id"`
+	ClientID int           `json:"client_id"`
+	Status   string        `json:"status"`
+	Currency string        `json:"currency"`
+	Total    int64         `json:"total"`
+	Paid     int64         `json:"paid"`
+	Lines    []InvoiceLine `json:"lines"`
+	IssuedAt time.Time     `json:"issued_at"`
+	PaidAt   *time.Time    `json:"paid_at,omitempty"`
+}
+
+// InvoiceLine charges for one session
+type InvoiceLine struct {
+	SessionID   int    `json:"session_id"`
+	TutorID     int    `json:"tutor_id"`
+	Description string `json:"description"`
+	Minutes     int    `json:"minutes"`
+	HourlyRate  int64  `json:"hourly_rate"`
+	Amount      int64  `json:"amount"`
+}
+
+// InvoiceFilter selects one page of invoices, of one client if ClientID is
+// set and with Status if given
+type InvoiceFilter struct {
+	ClientID int
+	Status   string
+	Cursor   string
+	Limit    int
+}
+
+// InvoicePage is one page of invoices, newest first
+type InvoicePage struct {
+	Invoices   []Invoice `json:"invoices"`
+	Total      int       `json:"total"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// LineAmount returns the charge for minutes at an hourly rate, rounded to the
+// nearest minor unit
+func LineAmount(minutes int, hourlyRate int64) int64 {
+	return (hourlyRate*int64(minutes) + 30) / 60
+}
+
+// BuildInvoices groups completed sessions into one open invoice per client,
+// ordered by client ID, with lines in session order
+func BuildInvoices(sessions []Session) []Invoice {
+	sessions = append([]Session(nil), sessions...)
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].StartsAt.Equal(sessions[j].StartsAt) {
+			return sessions[i].StartsAt.Before(sessions[j].StartsAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+
+	byClient := map[int]*Invoice{}
+	clientIDs := []int{}
+	for _, session := range sessions {
+		invoice, ok := byClient[session.ClientID]
+		if !ok {
+			invoice = &Invoice{ClientID: session.ClientID, Status: InvoiceStatusOpen, Currency: Currency, Lines: []InvoiceLine{}}
+			byClient[session.ClientID] = invoice
+			clientIDs = append(clientIDs, session.ClientID)
+		}
+
+		minutes := int(session.EndsAt.Sub(session.StartsAt).Minutes())
+		line := InvoiceLine{
+			SessionID:   session.ID,
+			TutorID:     session.TutorID,
+			Description: fmt.Sprintf("%s on %s", session.Subject, session.StartsAt.UTC().Format("2006-01-02 15:04 MST")),
+			Minutes:     minutes,
+			HourlyRate:  session.HourlyRate,
+			Amount:      LineAmount(minutes, session.HourlyRate),
+		}
+		invoice.Lines = append(invoice.Lines, line)
+		invoice.Total += line.Amount
+	}
+
+	sort.Ints(clientIDs)
+	invoices := make([]Invoice, 0, len(clientIDs))
+	for _, id := range clientIDs {
+		invoices = append(invoices, *byClient[id])
+	}
+	return invoices
+}
+
+// Entry returns the journal entry posting the invoice: the client's account
+// is debited the total and each tutor's account credited their lines. It has
+// no lines when the invoice total is zero, and then is not posted.
+func (inv *Invoice) Entry() JournalEntry {
+	entry := JournalEntry{
+		Kind:        EntryInvoice,
+		Description: fmt.Sprintf("Invoice %d", inv.ID),
+		ClientID:    inv.ClientID,
+		Lines:       []JournalLine{},
+	}
+	if inv.ID != 0 {
+		id := inv.ID
+		entry.InvoiceID = &id
+	}
+	if inv.Total == 0 {
+		return entry
+	}
+
+	earned := map[int]int64{}
+	tutorIDs := []int{}
+	for _, line := range inv.Lines {
+		if _, ok := earned[line.TutorID]; !ok {
+			tutorIDs = append(tutorIDs, line.TutorID)
+		}
+		earned[line.TutorID] += line.Amount
+	}
+	sort.Ints(tutorIDs)
+
+	entry.Lines = append(entry.Lines, JournalLine{Account: ClientAccount(inv.ClientID), Amount: inv.Total})
+	for _, id := range tutorIDs {
+		if earned[id] != 0 {
+			entry.Lines = append(entry.Lines, JournalLine{Account: TutorAccount(id), Amount: -earned[id]})
+		}
+	}
+	return entry
+}
+
+// pageLimit returns the requested page size within the allowed bounds
+func (f InvoiceFilter) pageLimit() int {
+	if f.Limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(f.Limit, MaxPageSize)
+}
+
+// invoiceColumns is the column list scanned by scanInvoice
+const invoiceColumns = `id, client_id, status, currency, total, paid, issued_at, paid_at`
+
+// scanInvoice scans a row selected with invoiceColumns, without its lines
+func scanInvoice(row pgx.Row) (Invoice, error) {
+	invoice := Invoice{Lines: []InvoiceLine{}}
+	err := row.Scan(
+		&invoice.ID,
+		&invoice.ClientID,
+		&invoice.Status,
+		&invoice.Currency,
+		&invoice.Total,
+		&invoice.Paid,
+		&invoice.IssuedAt,
+		&invoice.PaidAt,
+	)
+	return invoice, err
+}
+
+// loadInvoiceLines fills in the lines of the invoices
+func (r *PostgresLedgerRepository) loadInvoiceLines(ctx context.Context, invoices []Invoice) error {
+	if len(invoices) == 0 {
+		return nil
+	}
+	ids := make([]int, len(invoices))
+	byID := map[int]*Invoice{}
+	for i := range invoices {
+		ids[i] = invoices[i].ID
+		byID[invoices[i].ID] = &invoices[i]
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT invoice_id, session_id, tutor_id, description, minutes, hourly_rate, amount
+		FROM invoice_lines
+		WHERE invoice_id = ANY($1)
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var invoiceID int
+		var line InvoiceLine
+		if err := rows.Scan(&invoiceID, &line.SessionID, &line.TutorID, &line.Description, &line.Minutes, &line.HourlyRate, &line.Amount); err != nil {
+			return err
+		}
+		byID[invoiceID].Lines = append(byID[invoiceID].Lines, line)
+	}
+	return rows.Err()
+}
+
+// InvoiceSessions invoices the completed sessions that are not on an invoice
+// yet, one invoice per client, and posts an entry for each. Runs are
+// serialized so a session is never invoiced twice.
+func (r *PostgresLedgerRepository) InvoiceSessions(sessions []Session, audit *ChangeAudit) ([]Invoice, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, 0)`, ledgerLock); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+	}
+	rows, err := tx.Query(ctx, `SELECT session_id FROM invoice_lines WHERE session_id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	invoiced := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		invoiced[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := []Session{}
+	for _, session := range sessions {
+		if !invoiced[session.ID] {
+			pending = append(pending, session)
+		}
+	}
+
+	invoices := BuildInvoices(pending)
+	for i := range invoices {
+		invoice := &invoices[i]
+		if invoice.Total == 0 {
+			invoice.Status = InvoiceStatusPaid
+		}
+
+		err := tx.QueryRow(ctx, `
+			INSERT INTO invoices (client_id, status, currency, total, paid_at)
+			VALUES ($1, $2, $3, $4, CASE WHEN $4::BIGINT = 0 THEN CURRENT_TIMESTAMP END)
+			RETURNING id, issued_at, paid_at
+		`, invoice.ClientID, invoice.Status, invoice.Currency, invoice.Total).
+			Scan(&invoice.ID, &invoice.IssuedAt, &invoice.PaidAt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range invoice.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO invoice_lines (invoice_id, session_id, tutor_id, description, minutes, hourly_rate, amount)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`, invoice.ID, line.SessionID, line.TutorID, line.Description, line.Minutes, line.HourlyRate, line.Amount)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if entry := invoice.Entry(); len(entry.Lines) > 0 {
+			if err := insertEntry(ctx, tx, &entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := audit.save(ctx, tx, invoices); err != nil {
+		return nil, err
+	}
+	return invoices, tx.Commit(ctx)
+}
+
+// ListInvoices returns one page of the invoices matching the filter, newest first
+func (r *PostgresLedgerRepository) ListInvoices(filter InvoiceFilter) (*InvoicePage, error) {
+	ctx := context.Background()
+	limit := filter.pageLimit()
+	where := &conditions{}
+	if filter.ClientID != 0 {
+		where.add("client_id = " + where.arg(filter.ClientID))
+	}
+	if filter.Status != "" {
+		where.add("status = " + where.arg(filter.Status))
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM invoices `+where.where(), where.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	if filter.Cursor != "" {
+		position, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where.add("id < " + where.arg(position.ID))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM invoices
+		%s
+		ORDER BY id DESC
+		LIMIT %s
+	`, invoiceColumns, where.where(), where.arg(limit+1))
+
+	rows, err := r.db.Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &InvoicePage{Invoices: invoices, Total: total}
+	if len(invoices) > limit {
+		page.Invoices = invoices[:limit]
+		page.NextCursor = encodeCursor("", page.Invoices[limit-1].ID)
+	}
+	if err := r.loadInvoiceLines(ctx, page.Invoices); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetInvoice returns an invoice with its lines
+func (r *PostgresLedgerRepository) GetInvoice(id int) (*Invoice, error) {
+	ctx := context.Background()
+	invoice, err := scanInvoice(r.db.QueryRow(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := []Invoice{invoice}
+	if err := r.loadInvoiceLines(ctx, invoices); err != nil {
+		return nil, err
+	}
+	return &invoices[0], nil
+}
+
+// InvoiceCompletedSessions invoices every session completed by now that is
+// not on an invoice yet, recording the invoices with audit
+func InvoiceCompletedSessions(sessions SessionRepository, ledger LedgerRepository, now time.Time, audit *ChangeAudit) ([]Invoice, error) {
+	confirmed, err := sessions.List(SessionFilter{Statuses: []string{SessionStatusConfirmed}, To: &now, Uninvoiced: true})
+	if err != nil {
+		return nil, err
+	}
+
+	completed := []Session{}
+	for _, session := range confirmed {
+		if session.Completed(now) {
+			completed = append(completed, session)
+		}
+	}
+	if len(completed) == 0 {
+		return []Invoice{}, nil
+	}
+	return ledger.InvoiceSessions(completed, audit)
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// invoiceSpy records the sessions it is asked to invoice
+type invoiceSpy struct {
+	*MemoryLedgerRepository
+	asked [][]int
+}
+
+func (s *invoiceSpy) InvoiceSessions(sessions []Session, audit *ChangeAudit) ([]Invoice, error) {
+	ids := []int{}
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+	s.asked = append(s.asked, ids)
+	return s.MemoryLedgerRepository.InvoiceSessions(sessions, audit)
+}
+
+func TestInvoiceCompletedSessionsSkipsInvoicedSessions(t *testing.T) {
+	ledger := &invoiceSpy{MemoryLedgerRepository: NewMemoryLedgerRepository()}
+	sessions := NewMemorySessionRepository(ledger.MemoryLedgerRepository)
+	now := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	book := func(clientID, hour int, status string) *Session {
+		t.Helper()
+		start := time.Date(2026, 3, 2, hour, 0, 0, 0, time.UTC)
+		session := &Session{TutorID: 1, ClientID: clientID, Subject: "Math", StartsAt: start, EndsAt: start.Add(time.Hour), Status: status, HourlyRate: 4000}
+		if err := sessions.Create(session); err != nil {
+			t.Fatalf("Create session: %v", err)
+		}
+		return session
+	}
+
+	first := book(1, 9, SessionStatusConfirmed)
+	book(2, 11, SessionStatusCancelled)
+	late := book(2, 17, SessionStatusConfirmed) // still running at 17:59
+	invoices, err := InvoiceCompletedSessions(sessions, ledger, now.Add(-time.Minute), nil)
+	if err != nil {
+		t.Fatalf("InvoiceCompletedSessions: %v", err)
+	}
+	if len(invoices) != 1 || invoices[0].ClientID != 1 {
+		t.Fatalf("invoices = %+v, want one for client 1", invoices)
+	}
+
+	// The next run is only given the sessions not invoiced yet
+	second := book(1, 13, SessionStatusConfirmed)
+	invoices, err = InvoiceCompletedSessions(sessions, ledger, now, nil)
+	if err != nil {
+		t.Fatalf("InvoiceCompletedSessions: %v", err)
+	}
+	if len(invoices) != 2 {
+		t.Errorf("invoices = %+v, want one for each client", invoices)
+	}
+	if want := [][]int{{first.ID}, {second.ID, late.ID}}; !reflect.DeepEqual(ledger.asked, want) {
+		t.Errorf("asked to invoice %v, want %v", ledger.asked, want)
+	}
+
+	// Nothing is left once every completed session is invoiced
+	if invoices, err := InvoiceCompletedSessions(sessions, ledger, now, nil); err != nil || len(invoices) != 0 {
+		t.Errorf("InvoiceCompletedSessions = %+v, %v; want nothing to invoice", invoices, err)
+	}
+	if len(ledger.asked) != 2 {
+		t.Errorf("asked to invoice %v after every session was invoiced", ledger.asked[2:])
+	}
+
+	listed, err := sessions.List(SessionFilter{Uninvoiced: true})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Status != SessionStatusCancelled {
+		t.Errorf("uninvoiced sessions = %+v, want only the cancelled one", listed)
+	}
+}
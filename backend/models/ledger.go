@@ -0,0 +1,365 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Currency is the currency of every ledger amount. Amounts are integer minor
+// units (cents), never floats.
+const Currency = "usd"
+
+// Journal entry kinds
+const (
+	EntryInvoice = "invoice"
+	EntryPayment = "payment"
+	EntryCredit  = "credit"
+	EntryRefund  = "refund"
+)
+
+// Platform accounts. Each client has a receivable account (ClientAccount)
+// whose debit balance is what they owe, and each tutor a payable account
+// (TutorAccount) whose credit balance is what they have earned.
+const (
+	AccountCash    = "cash"
+	AccountCredits = "credits"
+)
+
+// ErrUnbalancedEntry is returned when a journal entry's lines do not add up to zero
+var ErrUnbalancedEntry = errors.New("journal entry lines must add up to zero")
+
+// ErrInvalidAmount is returned for amounts that are not positive
+var ErrInvalidAmount = errors.New("amount must be a positive number of minor units")
+
+// ErrOverpayment is returned for a payment against an invoice that is more
+// than is still due on it
+var ErrOverpayment = errors.New("payment is more than is due on the invoice")
+
+// ErrRefundExceedsPaid is returned for a refund against an invoice that is
+// more than has been paid on it
+var ErrRefundExceedsPaid = errors.New("refund is more than was paid on the invoice")
+
+// ClientAccount returns the receivable account of a client
+func ClientAccount(clientID int) string {
+	return fmt.Sprintf("client:%d", clientID)
+}
+
+// TutorAccount returns the payable account of a tutor
+func TutorAccount(tutorID int) string {
+	return fmt.Sprintf("tutor:%d", tutorID)
+}
+
+// MinorUnits converts a decimal amount, like a tutor's hourly pay, to minor
+// units
+func MinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// JournalLine posts an amount to an account: a debit when positive, a credit
+// when negative
+type JournalLine struct {
+	Account string `json:"account"`
+	Amount  int64  `json:"amount"`
+}
+
+// JournalEntry is one balanced posting to the ledger. Entries are never
+// changed once posted; mistakes are corrected by posting another entry.
+type JournalEntry struct {
+	ID          int           `json:"id"`
+	Kind        string        `json:"kind"`
+	Description string        `json:"description"`
+	ClientID    int           `json:"client_id,omitempty"`
+	InvoiceID   *int          `json:"invoice_id,omitempty"`
+	CreatedBy   string        `json:"created_by,omitempty"`
+	Lines       []JournalLine `json:"lines"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// Validate checks that the entry has at least two non-zero lines that add up
+// to zero
+func (e *JournalEntry) Validate() error {
+	if len(e.Lines) < 2 {
+		return ErrUnbalancedEntry
+	}
+	var sum int64
+	for _, line := range e.Lines {
+		if line.Amount == 0 {
+			return fmt.Errorf("line for %s has no amount", line.Account)
+		}
+		sum += line.Amount
+	}
+	if sum != 0 {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// Amount returns the entry's total debits
+func (e *JournalEntry) Amount() int64 {
+	var amount int64
+	for _, line := range e.Lines {
+		if line.Amount > 0 {
+			amount += line.Amount
+		}
+	}
+	return amount
+}
+
+// checkSettlement checks that settling an amount against an invoice with the
+// given total and paid amount neither overpays it nor refunds more than was
+// paid
+func checkSettlement(total, paid, settled int64) error {
+	switch {
+	case settled > 0 && paid+settled > total:
+		return ErrOverpayment
+	case settled < 0 && paid+settled < 0:
+		return ErrRefundExceedsPaid
+	}
+	return nil
+}
+
+// Settlement returns how much the entry changes the paid amount of its
+// invoice: payments add to it and refunds take from it
+func (e *JournalEntry) Settlement() int64 {
+	if e.InvoiceID == nil {
+		return 0
+	}
+	switch e.Kind {
+	case EntryPayment:
+		return e.Amount()
+	case EntryRefund:
+		return -e.Amount()
+	}
+	return 0
+}
+
+// PaymentEntry records money received from a client, optionally against one
+// of their invoices
+func PaymentEntry(clientID int, amount int64, invoiceID *int, description string) JournalEntry {
+	return JournalEntry{
+		Kind:        EntryPayment,
+		Description: description,
+		ClientID:    clientID,
+		InvoiceID:   invoiceID,
+		Lines: []JournalLine{
+			{Account: AccountCash, Amount: amount},
+			{Account: ClientAccount(clientID), Amount: -amount},
+		},
+	}
+}
+
+// CreditEntry records a credit granted to a client, which lowers what they owe
+// without money changing hands
+func CreditEntry(clientID int, amount int64, description string) JournalEntry {
+	return JournalEntry{
+		Kind:        EntryCredit,
+		Description: description,
+		ClientID:    clientID,
+		Lines: []JournalLine{
+			{Account: AccountCredits, Amount: amount},
+			{Account: ClientAccount(clientID), Amount: -amount},
+		},
+	}
+}
+
+// RefundEntry records money returned to a client, optionally of a payment
+// against one of their invoices
+func RefundEntry(clientID int, amount int64, invoiceID *int, description string) JournalEntry {
+	return JournalEntry{
+		Kind:        EntryRefund,
+		Description: description,
+		ClientID:    clientID,
+		InvoiceID:   invoiceID,
+		Lines: []JournalLine{
+			{Account: ClientAccount(clientID), Amount: amount},
+			{Account: AccountCash, Amount: -amount},
+		},
+	}
+}
+
+// ClientBalance summarizes a client's receivable account. Balance is what the
+// client owes; a negative balance is money held in their favour.
+type ClientBalance struct {
+	ClientID int    `json:"client_id"`
+	Currency string `json:"currency"`
+	Invoiced int64  `json:"invoiced"`
+	Paid     int64  `json:"paid"`
+	Credited int64  `json:"credited"`
+	Refunded int64  `json:"refunded"`
+	Balance  int64  `json:"balance"`
+}
+
+// NewClientBalance builds a client's balance from their account's totals by
+// entry kind
+func NewClientBalance(clientID int, totals map[string]int64) ClientBalance {
+	balance := ClientBalance{
+		ClientID: clientID,
+		Currency: Currency,
+		Invoiced: totals[EntryInvoice],
+		Paid:     -totals[EntryPayment],
+		Credited: -totals[EntryCredit],
+		Refunded: totals[EntryRefund],
+	}
+	for _, amount := range totals {
+		balance.Balance += amount
+	}
+	return balance
+}
+
+// TutorEarnings summarizes a tutor's payable account. Balance is what the
+// platform owes the tutor: what they earned, less the commission on their
+// payout statements and what has been paid out.
+type TutorEarnings struct {
+	TutorID    int    `json:"tutor_id"`
+	Currency   string `json:"currency"`
+	Earned     int64  `json:"earned"`
+	Commission int64  `json:"commission"`
+	PaidOut    int64  `json:"paid_out"`
+	Balance    int64  `json:"balance"`
+}
+
+// NewTutorEarnings builds a tutor's earnings from their account's totals by
+// entry kind
+func NewTutorEarnings(tutorID int, totals map[string]int64) TutorEarnings {
+	earnings := TutorEarnings{
+		TutorID:    tutorID,
+		Currency:   Currency,
+		Earned:     -totals[EntryInvoice],
+		Commission: totals[EntryCommission],
+		PaidOut:    totals[EntryPayout],
+	}
+	for _, amount := range totals {
+		earnings.Balance -= amount
+	}
+	return earnings
+}
+
+// Advisory lock namespace serializing invoicing runs
+const ledgerLock = 3
+
+// PostgresLedgerRepository stores invoices and journal entries in the
+// invoices, invoice_lines, journal_entries and journal_lines tables
+type PostgresLedgerRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresLedgerRepository returns a ledger repository backed by the pool
+func NewPostgresLedgerRepository(db *pgxpool.Pool) *PostgresLedgerRepository {
+	return &PostgresLedgerRepository{db: db}
+}
+
+// insertEntry inserts a validated journal entry and its lines inside a transaction
+func insertEntry(ctx context.Context, tx pgx.Tx, entry *JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO journal_entries (kind, description, client_id, invoice_id, created_by)
+		VALUES ($1, $2, NULLIF($3, 0), $4, NULLIF($5, ''))
+		RETURNING id, created_at
+	`
+	err := tx.QueryRow(ctx, query, entry.Kind, entry.Description, entry.ClientID, entry.InvoiceID, entry.CreatedBy).
+		Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range entry.Lines {
+		_, err := tx.Exec(ctx, `INSERT INTO journal_lines (entry_id, account, amount) VALUES ($1, $2, $3)`,
+			entry.ID, line.Account, line.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postEntry inserts an entry inside a transaction and applies its settlement
+// to its invoice, which is paid while its paid amount covers the total. It
+// returns pgx.ErrNoRows if the client has no such invoice, and
+// ErrOverpayment or ErrRefundExceedsPaid if the settlement does not fit it.
+func postEntry(ctx context.Context, tx pgx.Tx, entry *JournalEntry) error {
+	settled := entry.Settlement()
+	if settled != 0 {
+		var total, paid int64
+		err := tx.QueryRow(ctx, `SELECT total, paid FROM invoices WHERE id = $1 AND client_id = $2 FOR UPDATE`,
+			*entry.InvoiceID, entry.ClientID).Scan(&total, &paid)
+		if err != nil {
+			return err
+		}
+		if err := checkSettlement(total, paid, settled); err != nil {
+			return err
+		}
+	}
+
+	if err := insertEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+	if settled == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE invoices
+		SET paid = paid + $2,
+		    status = CASE WHEN paid + $2 >= total THEN $3 ELSE $4 END,
+		    paid_at = CASE WHEN paid + $2 >= total THEN COALESCE(paid_at, CURRENT_TIMESTAMP) END
+		WHERE id = $1
+	`
+	_, err := tx.Exec(ctx, query, *entry.InvoiceID, settled, InvoiceStatusPaid, InvoiceStatusOpen)
+	return err
+}
+
+// Post saves a balanced journal entry, setting its ID and creation time, and
+// applies a payment or refund against an invoice to the invoice
+func (r *PostgresLedgerRepository) Post(entry *JournalEntry, audit *ChangeAudit) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := postEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+	if err := audit.save(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AccountTotals returns the sum of an account's lines by entry kind
+func (r *PostgresLedgerRepository) AccountTotals(account string) (map[string]int64, error) {
+	query := `
+		SELECT e.kind, SUM(l.amount)
+		FROM journal_lines l
+		JOIN journal_entries e ON e.id = l.entry_id
+		WHERE l.account = $1
+		GROUP BY e.kind
+	`
+	rows, err := r.db.Query(context.Background(), query, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := map[string]int64{}
+	for rows.Next() {
+		var kind string
+		var amount int64
+		if err := rows.Scan(&kind, &amount); err != nil {
+			return nil, err
+		}
+		totals[kind] = amount
+	}
+	return totals, rows.Err()
+}
@@ -0,0 +1,750 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryTutorRepository keeps tutors in memory. It behaves like the Postgres
+// repository, so it backs dev mode without a database and handler tests.
+type MemoryTutorRepository struct {
+	mu              sync.RWMutex
+	subjects        SubjectRepository
+	tutors          map[int]Tutor
+	nextID          int
+	exceptions      []AvailabilityException
+	nextExceptionID int
+}
+
+// NewMemoryTutorRepository returns an empty in-memory tutor repository,
+// normalizing subjects through the taxonomy in subjects
+func NewMemoryTutorRepository(subjects SubjectRepository) *MemoryTutorRepository {
+	return &MemoryTutorRepository{subjects: subjects, tutors: map[int]Tutor{}, nextID: 1, nextExceptionID: 1}
+}
+
+// copyTutor returns a tutor that shares no slices or pointers with the stored one
+func copyTutor(tutor Tutor) Tutor {
+	tutor.Subjects = append([]string(nil), tutor.Subjects...)
+	if tutor.DeletedAt != nil {
+		deletedAt := *tutor.DeletedAt
+		tutor.DeletedAt = &deletedAt
+	}
+	return tutor
+}
+
+// collect returns copies of the stored tutors accepted by keep, newest first
+func (r *MemoryTutorRepository) collect(keep func(Tutor) bool) []Tutor {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tutors := []Tutor{}
+	for _, tutor := range r.tutors {
+		if keep(tutor) {
+			tutors = append(tutors, copyTutor(tutor))
+		}
+	}
+	sort.Slice(tutors, func(i, j int) bool {
+		if !tutors[i].CreatedAt.Equal(tutors[j].CreatedAt) {
+			return tutors[i].CreatedAt.After(tutors[j].CreatedAt)
+		}
+		return tutors[i].ID > tutors[j].ID
+	})
+	return tutors
+}
+
+// List returns all tutors that are not deleted, newest first
+func (r *MemoryTutorRepository) List() ([]Tutor, error) {
+	return r.collect(func(t Tutor) bool { return t.DeletedAt == nil }), nil
+}
+
+// Search returns one page of tutors matching the filter. Full-text queries
+// are approximated by stemmed word matching; see textQuery.
+func (r *MemoryTutorRepository) Search(filter TutorFilter) (*TutorPage, error) {
+	if err := filter.expandSubjects(r.subjects); err != nil {
+		return nil, err
+	}
+	order, err := filter.sortOrder()
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.pageLimit()
+
+	var after *cursor
+	if filter.Cursor != "" {
+		if after, err = decodeCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	query := parseTextQuery(filter.Query)
+	results := []TutorResult{}
+	for _, tutor := range r.collect(r.listed(filter.AvailableAt)) {
+		if result, ok := filter.match(tutor, query); ok {
+			results = append(results, result)
+		}
+	}
+	total := len(results)
+
+	// Order by the sort value, then by ID in the same direction
+	compare := func(a TutorResult, aID int, b string, bID int) int {
+		cmp := compareSortValues(order.cast, order.value(a), b)
+		if cmp == 0 {
+			cmp = aID - bID
+		}
+		if order.desc {
+			cmp = -cmp
+		}
+		return cmp
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return compare(results[i], results[i].ID, order.value(results[j]), results[j].ID) < 0
+	})
+
+	tutors := []TutorResult{}
+	for _, result := range results {
+		if after != nil && compare(result, result.ID, after.Value, after.ID) <= 0 {
+			continue
+		}
+		tutors = append(tutors, result)
+	}
+
+	page := &TutorPage{Tutors: tutors, Total: total}
+	if len(tutors) > limit {
+		page.Tutors = tutors[:limit]
+		last := page.Tutors[limit-1]
+		page.NextCursor = encodeCursor(order.value(last), last.ID)
+	}
+	return page, nil
+}
+
+// Facets counts tutors per facet value under the filter. Each facet ignores
+// its own filter, as in the Postgres repository.
+func (r *MemoryTutorRepository) Facets(filter TutorFilter) (*TutorFacets, error) {
+	if err := filter.expandSubjects(r.subjects); err != nil {
+		return nil, err
+	}
+
+	tutors := r.collect(r.listed(filter.AvailableAt))
+	query := parseTextQuery(filter.Query)
+	matching := func(filter TutorFilter) []Tutor {
+		matched := []Tutor{}
+		for _, tutor := range tutors {
+			if _, ok := filter.match(tutor, query); ok {
+				matched = append(matched, tutor)
+			}
+		}
+		return matched
+	}
+
+	withoutSubjects := filter
+	withoutSubjects.Subjects, withoutSubjects.subjectGroups = nil, nil
+	withoutLanguage := filter
+	withoutLanguage.Language = ""
+	withoutEducation := filter
+	withoutEducation.Education = ""
+	withoutPay := filter
+	withoutPay.MinPay, withoutPay.MaxPay = nil, nil
+	withoutRating := filter
+	withoutRating.MinRating = nil
+
+	return &TutorFacets{
+		Subjects: countValues(matching(withoutSubjects), func(t Tutor) []string {
+			return t.Subjects
+		}),
+		Languages: countValues(matching(withoutLanguage), func(t Tutor) []string {
+			return []string{t.Language}
+		}),
+		Education: countValues(matching(withoutEducation), func(t Tutor) []string {
+			return []string{t.Education}
+		}),
+		PayBrackets: countInBrackets(matching(withoutPay), payBrackets, func(t Tutor) float64 {
+			return t.Pay
+		}),
+		RatingBrackets: countInBrackets(matching(withoutRating), ratingBrackets, func(t Tutor) float64 {
+			return t.Rating
+		}),
+	}, nil
+}
+
+// AvailableAt returns the tutors whose weekly availability covers the given
+// instant, evaluated in each tutor's own timezone
+func (r *MemoryTutorRepository) AvailableAt(at time.Time) ([]Tutor, error) {
+	return r.collect(func(t Tutor) bool {
+		return r.listed(&at)(t) && availableAt(t.Availability, t.Timezone, at)
+	}), nil
+}
+
+// listed returns a collect filter for tutors that are not deleted and, if at
+// is set, not busy at that instant. It reads exceptions without locking, as
+// collect already holds the lock.
+func (r *MemoryTutorRepository) listed(at *time.Time) func(Tutor) bool {
+	return func(t Tutor) bool {
+		if t.DeletedAt != nil {
+			return false
+		}
+		if at != nil {
+			for _, exception := range r.exceptions {
+				if exception.OwnerID == t.ID && !exception.StartsAt.After(*at) && exception.EndsAt.After(*at) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// AvailabilityExceptions returns the exceptions of the tutors that overlap
+// the range from-to, earliest first, by tutor ID
+func (r *MemoryTutorRepository) AvailabilityExceptions(tutorIDs []int, from, to time.Time) (map[int][]AvailabilityException, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := map[int]bool{}
+	for _, id := range tutorIDs {
+		wanted[id] = true
+	}
+
+	exceptions := map[int][]AvailabilityException{}
+	for _, exception := range r.exceptions {
+		if wanted[exception.OwnerID] && exception.StartsAt.Before(to) && exception.EndsAt.After(from) {
+			exceptions[exception.OwnerID] = append(exceptions[exception.OwnerID], exception)
+		}
+	}
+	for _, list := range exceptions {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].StartsAt.Before(list[j].StartsAt) })
+	}
+	return exceptions, nil
+}
+
+// ReplaceAvailabilityExceptions replaces the tutor's exceptions from a source
+// with the given busy spans
+func (r *MemoryTutorRepository) ReplaceAvailabilityExceptions(tutorID int, source string, blocks []Interval) ([]AvailabilityException, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := []AvailabilityException{}
+	for _, exception := range r.exceptions {
+		if exception.OwnerID != tutorID || exception.Source != source {
+			kept = append(kept, exception)
+		}
+	}
+
+	now := time.Now()
+	added := make([]AvailabilityException, 0, len(blocks))
+	for _, block := range blocks {
+		added = append(added, AvailabilityException{
+			ID:        r.nextExceptionID,
+			OwnerType: OwnerTypeTutor,
+			OwnerID:   tutorID,
+			StartsAt:  block.Start,
+			EndsAt:    block.End,
+			Source:    source,
+			CreatedAt: now,
+		})
+		r.nextExceptionID++
+	}
+	r.exceptions = append(kept, added...)
+	return append([]AvailabilityException(nil), added...), nil
+}
+
+// GetByID returns the tutor with the ID
+func (r *MemoryTutorRepository) GetByID(id int) (*Tutor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tutor, ok := r.tutors[id]
+	if !ok || tutor.DeletedAt != nil {
+		return nil, pgx.ErrNoRows
+	}
+	tutor = copyTutor(tutor)
+	return &tutor, nil
+}
+
+// GetByEmail returns the tutor with the email
+func (r *MemoryTutorRepository) GetByEmail(email string) (*Tutor, error) {
+	matches := r.collect(func(t Tutor) bool { return t.DeletedAt == nil && t.Email == email })
+	if len(matches) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return &matches[0], nil
+}
+
+// Create saves a new tutor
+func (r *MemoryTutorRepository) Create(tutor *Tutor) error {
+	subjects, err := normalizeSubjects(r.subjects, tutor.Subjects)
+	if err != nil {
+		return err
+	}
+	tutor.Subjects = subjects
+	if _, err := ParseAvailability(tutor.Availability, tutor.Timezone); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	tutor.ID = r.nextID
+	tutor.Rating, tutor.ReviewCount = 0, 0
+	tutor.MaxClients = tutor.Capacity()
+	tutor.CreatedAt, tutor.UpdatedAt, tutor.DeletedAt = now, now, nil
+	r.nextID++
+	r.tutors[tutor.ID] = copyTutor(*tutor)
+	return nil
+}
+
+// Update replaces the fields of an existing tutor
+func (r *MemoryTutorRepository) Update(tutor *Tutor, audit *ChangeAudit) error {
+	subjects, err := normalizeSubjects(r.subjects, tutor.Subjects)
+	if err != nil {
+		return err
+	}
+	tutor.Subjects = subjects
+	if _, err := ParseAvailability(tutor.Availability, tutor.Timezone); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tutors[tutor.ID]
+	if !ok || stored.DeletedAt != nil {
+		return pgx.ErrNoRows
+	}
+	if tutor.MaxClients == 0 {
+		tutor.MaxClients = stored.MaxClients
+	}
+	tutor.Rating, tutor.ReviewCount = stored.Rating, stored.ReviewCount
+	tutor.CreatedAt, tutor.UpdatedAt, tutor.DeletedAt = stored.CreatedAt, time.Now(), nil
+	if err := audit.record(tutor); err != nil {
+		return err
+	}
+	r.tutors[tutor.ID] = copyTutor(*tutor)
+	return nil
+}
+
+// setRating stores a tutor's rating derived from their reviews
+func (r *MemoryTutorRepository) setRating(id int, rating float64, count int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tutor, ok := r.tutors[id]; ok {
+		tutor.Rating, tutor.ReviewCount = rating, count
+		r.tutors[id] = tutor
+	}
+}
+
+// Delete soft-deletes a tutor
+func (r *MemoryTutorRepository) Delete(id int, audit *ChangeAudit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tutor, ok := r.tutors[id]
+	if !ok || tutor.DeletedAt != nil {
+		return pgx.ErrNoRows
+	}
+	if err := audit.record(nil); err != nil {
+		return err
+	}
+	now := time.Now()
+	tutor.DeletedAt = &now
+	r.tutors[id] = tutor
+	return nil
+}
+
+// ListDeleted returns soft-deleted tutors, most recently deleted first
+func (r *MemoryTutorRepository) ListDeleted() ([]Tutor, error) {
+	tutors := r.collect(func(t Tutor) bool { return t.DeletedAt != nil })
+	sort.SliceStable(tutors, func(i, j int) bool { return tutors[i].DeletedAt.After(*tutors[j].DeletedAt) })
+	return tutors, nil
+}
+
+// Restore undoes a soft delete and returns the restored tutor
+func (r *MemoryTutorRepository) Restore(id int, audit *ChangeAudit) (*Tutor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tutor, ok := r.tutors[id]
+	if !ok || tutor.DeletedAt == nil {
+		return nil, pgx.ErrNoRows
+	}
+	tutor.DeletedAt, tutor.UpdatedAt = nil, time.Now()
+	restored := copyTutor(tutor)
+	if err := audit.record(&restored); err != nil {
+		return nil, err
+	}
+	r.tutors[id] = tutor
+	return &restored, nil
+}
+
+// Purge permanently removes tutors soft-deleted before the cutoff
+func (r *MemoryTutorRepository) Purge(cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, tutor := range r.tutors {
+		if tutor.DeletedAt != nil && tutor.DeletedAt.Before(cutoff) {
+			delete(r.tutors, id)
+			purged++
+		}
+	}
+
+	kept := []AvailabilityException{}
+	for _, exception := range r.exceptions {
+		if _, ok := r.tutors[exception.OwnerID]; ok {
+			kept = append(kept, exception)
+		}
+	}
+	r.exceptions = kept
+	return purged, nil
+}
+
+// MemoryClientRepository keeps clients in memory. It behaves like the
+// Postgres repository, so it backs dev mode without a database and handler tests.
+type MemoryClientRepository struct {
+	mu       sync.RWMutex
+	subjects SubjectRepository
+	clients  map[int]Client
+	nextID   int
+}
+
+// NewMemoryClientRepository returns an empty in-memory client repository,
+// normalizing subjects through the taxonomy in subjects
+func NewMemoryClientRepository(subjects SubjectRepository) *MemoryClientRepository {
+	return &MemoryClientRepository{subjects: subjects, clients: map[int]Client{}, nextID: 1}
+}
+
+// copyClient returns a client that shares no slices or pointers with the stored one
+func copyClient(client Client) Client {
+	client.Subjects = append([]string(nil), client.Subjects...)
+	if client.Active != nil {
+		active := *client.Active
+		client.Active = &active
+	}
+	if client.DeletedAt != nil {
+		deletedAt := *client.DeletedAt
+		client.DeletedAt = &deletedAt
+	}
+	return client
+}
+
+// collect returns copies of the stored clients accepted by keep, newest first
+func (r *MemoryClientRepository) collect(keep func(Client) bool) []Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clients := []Client{}
+	for _, client := range r.clients {
+		if keep(client) {
+			clients = append(clients, copyClient(client))
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		if !clients[i].CreatedAt.Equal(clients[j].CreatedAt) {
+			return clients[i].CreatedAt.After(clients[j].CreatedAt)
+		}
+		return clients[i].ID > clients[j].ID
+	})
+	return clients
+}
+
+// List returns all clients that are not deleted, newest first
+func (r *MemoryClientRepository) List() ([]Client, error) {
+	return r.collect(func(c Client) bool { return c.DeletedAt == nil }), nil
+}
+
+// Search returns clients whose description matches a full-text query, most
+// relevant first. Matching is approximated by stemmed words; see textQuery.
+func (r *MemoryClientRepository) Search(query string, limit int) ([]ClientResult, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	text := parseTextQuery(query)
+	results := []ClientResult{}
+	for _, client := range r.collect(func(c Client) bool { return c.DeletedAt == nil }) {
+		rank, snippet, ok := text.match(client.Description)
+		if !ok {
+			continue
+		}
+		results = append(results, ClientResult{Client: client, Rank: &rank, Snippet: snippet})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if *results[i].Rank != *results[j].Rank {
+			return *results[i].Rank > *results[j].Rank
+		}
+		return results[i].ID > results[j].ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// AvailableAt returns the clients whose weekly availability covers the given
+// instant, evaluated in each client's own timezone
+func (r *MemoryClientRepository) AvailableAt(at time.Time) ([]Client, error) {
+	return r.collect(func(c Client) bool {
+		return c.DeletedAt == nil && availableAt(c.Availability, c.Timezone, at)
+	}), nil
+}
+
+// GetByID returns the client with the ID
+func (r *MemoryClientRepository) GetByID(id int) (*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, ok := r.clients[id]
+	if !ok || client.DeletedAt != nil {
+		return nil, pgx.ErrNoRows
+	}
+	client = copyClient(client)
+	return &client, nil
+}
+
+// GetByEmail returns the client with the email
+func (r *MemoryClientRepository) GetByEmail(email string) (*Client, error) {
+	matches := r.collect(func(c Client) bool { return c.DeletedAt == nil && c.Email == email })
+	if len(matches) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return &matches[0], nil
+}
+
+// Create saves a new client
+func (r *MemoryClientRepository) Create(client *Client) error {
+	subjects, err := normalizeSubjects(r.subjects, client.Subjects)
+	if err != nil {
+		return err
+	}
+	client.Subjects = subjects
+	if _, err := ParseAvailability(client.Availability, client.Timezone); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if client.Active == nil {
+		active := true
+		client.Active = &active
+	}
+	client.ID = r.nextID
+	client.CreatedAt, client.UpdatedAt, client.DeletedAt = now, now, nil
+	r.nextID++
+	r.clients[client.ID] = copyClient(*client)
+	return nil
+}
+
+// Update replaces the fields of an existing client
+func (r *MemoryClientRepository) Update(client *Client, audit *ChangeAudit) error {
+	subjects, err := normalizeSubjects(r.subjects, client.Subjects)
+	if err != nil {
+		return err
+	}
+	client.Subjects = subjects
+	if _, err := ParseAvailability(client.Availability, client.Timezone); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.clients[client.ID]
+	if !ok || stored.DeletedAt != nil {
+		return pgx.ErrNoRows
+	}
+	if client.Active == nil {
+		client.Active = copyClient(stored).Active
+	}
+	client.CreatedAt, client.UpdatedAt, client.DeletedAt = stored.CreatedAt, time.Now(), nil
+	if err := audit.record(client); err != nil {
+		return err
+	}
+	r.clients[client.ID] = copyClient(*client)
+	return nil
+}
+
+// Delete soft-deletes a client
+func (r *MemoryClientRepository) Delete(id int, audit *ChangeAudit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[id]
+	if !ok || client.DeletedAt != nil {
+		return pgx.ErrNoRows
+	}
+	if err := audit.record(nil); err != nil {
+		return err
+	}
+	now := time.Now()
+	client.DeletedAt = &now
+	r.clients[id] = client
+	return nil
+}
+
+// ListDeleted returns soft-deleted clients, most recently deleted first
+func (r *MemoryClientRepository) ListDeleted() ([]Client, error) {
+	clients := r.collect(func(c Client) bool { return c.DeletedAt != nil })
+	sort.SliceStable(clients, func(i, j int) bool { return clients[i].DeletedAt.After(*clients[j].DeletedAt) })
+	return clients, nil
+}
+
+// Restore undoes a soft delete and returns the restored client
+func (r *MemoryClientRepository) Restore(id int, audit *ChangeAudit) (*Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[id]
+	if !ok || client.DeletedAt == nil {
+		return nil, pgx.ErrNoRows
+	}
+	client.DeletedAt, client.UpdatedAt = nil, time.Now()
+	restored := copyClient(client)
+	if err := audit.record(&restored); err != nil {
+		return nil, err
+	}
+	r.clients[id] = client
+	return &restored, nil
+}
+
+// Purge permanently removes clients soft-deleted before the cutoff. Their
+// sessions and reviews live in other repositories and are kept, so no
+// rating changes.
+func (r *MemoryClientRepository) Purge(cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, client := range r.clients {
+		if client.DeletedAt != nil && client.DeletedAt.Before(cutoff) {
+			delete(r.clients, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// availableAt reports whether a raw availability covers the instant in its timezone
+func availableAt(raw, timezone string, at time.Time) bool {
+	availability, err := ParseAvailability(raw, timezone)
+	if err != nil {
+		return false
+	}
+	local := at.In(availability.Location())
+	return availability.IsFreeAt(local.Weekday(), local.Hour()*60+local.Minute())
+}
+
+// countValues counts profiles per non-empty value, most common first
+func countValues[T any](profiles []T, values func(T) []string) []FacetCount {
+	totals := map[string]int{}
+	for _, profile := range profiles {
+		seen := map[string]bool{}
+		for _, value := range values(profile) {
+			if value != "" && !seen[value] {
+				seen[value] = true
+				totals[value]++
+			}
+		}
+	}
+
+	counts := []FacetCount{}
+	for value, count := range totals {
+		counts = append(counts, FacetCount{Value: value, Label: value, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	return counts
+}
+
+// countInBrackets counts profiles per bracket of a numeric value, in bracket order
+func countInBrackets[T any](profiles []T, brackets []bracket, value func(T) float64) []FacetCount {
+	counts := make([]FacetCount, 0, len(brackets))
+	for _, b := range brackets {
+		count := FacetCount{Value: b.value, Label: b.label}
+		for _, profile := range profiles {
+			if v := value(profile); v >= b.min && (b.max == 0 || v < b.max) {
+				count.Count++
+			}
+		}
+		counts = append(counts, count)
+	}
+	return counts
+}
+
+// match reports whether a tutor passes the filter, returning it as a search
+// result with rank and snippet set for full-text searches. It mirrors the SQL
+// built by conditions.
+func (f TutorFilter) match(tutor Tutor, query textQuery) (TutorResult, bool) {
+	result := TutorResult{Tutor: tutor}
+
+	if strings.TrimSpace(f.Query) != "" {
+		var text []string
+		for _, field := range []string{tutor.Bio, tutor.Experience, tutor.Certification} {
+			if field != "" {
+				text = append(text, field)
+			}
+		}
+		rank, snippet, ok := query.match(strings.Join(text, " … "))
+		if !ok {
+			return result, false
+		}
+		result.Rank, result.Snippet = &rank, snippet
+	}
+
+	groups := f.subjectGroups
+	if groups == nil {
+		for _, subject := range lowerAll(f.Subjects) {
+			groups = append(groups, []string{subject})
+		}
+	}
+	if len(groups) > 0 {
+		subjects := map[string]bool{}
+		for _, subject := range tutor.Subjects {
+			subjects[strings.ToLower(subject)] = true
+		}
+		inGroup := func(group []string) bool {
+			for _, name := range group {
+				if subjects[name] {
+					return true
+				}
+			}
+			return false
+		}
+
+		matched := 0
+		for _, group := range groups {
+			if inGroup(group) {
+				matched++
+			}
+		}
+		if matched == 0 || (f.MatchAll && matched < len(groups)) {
+			return result, false
+		}
+	}
+
+	switch {
+	case f.MinPay != nil && tutor.Pay < *f.MinPay,
+		f.MaxPay != nil && tutor.Pay > *f.MaxPay,
+		f.MinRating != nil && tutor.Rating < *f.MinRating,
+		f.Language != "" && !strings.EqualFold(tutor.Language, f.Language),
+		f.Location != "" && !strings.Contains(strings.ToLower(tutor.Location), strings.ToLower(f.Location)),
+		f.Education != "" && !strings.EqualFold(tutor.Education, f.Education),
+		f.AvailableAt != nil && !availableAt(tutor.Availability, tutor.Timezone, *f.AvailableAt):
+		return result, false
+	}
+
+	return result, true
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryAuditRepository keeps audit entries in memory
+type MemoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []AuditEntry
+	nextID  int64
+}
+
+// NewMemoryAuditRepository returns an empty in-memory audit repository
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{nextID: 1}
+}
+
+// Record saves an audit entry, setting its ID and creation time
+func (r *MemoryAuditRepository) Record(entry *AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = r.nextID
+	entry.CreatedAt = time.Now()
+	r.nextID++
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+// List returns a page of audit entries matching the filter, newest first
+func (r *MemoryAuditRepository) List(filter AuditFilter) (*AuditPage, error) {
+	var before int64
+	if filter.Cursor != "" {
+		position, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		before = int64(position.ID)
+	}
+	limit := filter.pageLimit()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []AuditEntry{}
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		entry := r.entries[i]
+		switch {
+		case before > 0 && entry.ID >= before,
+			filter.Actor != "" && !strings.EqualFold(entry.Actor, filter.Actor),
+			filter.EntityType != "" && entry.EntityType != filter.EntityType,
+			filter.EntityID != "" && entry.EntityID != filter.EntityID,
+			filter.From != nil && entry.CreatedAt.Before(*filter.From),
+			filter.To != nil && !entry.CreatedAt.Before(*filter.To):
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	page := &AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = encodeCursor("", int(page.Entries[limit-1].ID))
+	}
+	return page, nil
+}
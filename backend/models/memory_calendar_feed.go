@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryCalendarFeedRepository keeps feeds in memory
+type MemoryCalendarFeedRepository struct {
+	mu    sync.RWMutex
+	feeds map[string]CalendarFeed
+}
+
+// NewMemoryCalendarFeedRepository returns an empty in-memory feed repository
+func NewMemoryCalendarFeedRepository() *MemoryCalendarFeedRepository {
+	return &MemoryCalendarFeedRepository{feeds: map[string]CalendarFeed{}}
+}
+
+// GetByEmail returns the user's feed
+func (r *MemoryCalendarFeedRepository) GetByEmail(email string) (*CalendarFeed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	feed, ok := r.feeds[strings.ToLower(email)]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &feed, nil
+}
+
+// GetByTokenHash returns the feed whose token has the hash
+func (r *MemoryCalendarFeedRepository) GetByTokenHash(tokenHash string) (*CalendarFeed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, feed := range r.feeds {
+		if feed.TokenHash == tokenHash {
+			return &feed, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// Save creates the user's feed or replaces its token
+func (r *MemoryCalendarFeedRepository) Save(feed *CalendarFeed) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	feed.Email = strings.ToLower(feed.Email)
+	feed.CreatedAt = time.Now()
+	r.feeds[feed.Email] = *feed
+	return nil
+}
+
+// Delete removes the user's feed
+func (r *MemoryCalendarFeedRepository) Delete(email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email = strings.ToLower(email)
+	if _, ok := r.feeds[email]; !ok {
+		return pgx.ErrNoRows
+	}
+	delete(r.feeds, email)
+	return nil
+}
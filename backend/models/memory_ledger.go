@@ -0,0 +1,228 @@
+package models
+
+import (
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryLedgerRepository keeps invoices and journal entries in memory
+type MemoryLedgerRepository struct {
+	mu            sync.RWMutex
+	entries       []JournalEntry
+	invoices      map[int]Invoice
+	invoiced      map[int]bool
+	nextEntryID   int
+	nextInvoiceID int
+}
+
+// NewMemoryLedgerRepository returns an empty in-memory ledger
+func NewMemoryLedgerRepository() *MemoryLedgerRepository {
+	return &MemoryLedgerRepository{invoices: map[int]Invoice{}, invoiced: map[int]bool{}, nextEntryID: 1, nextInvoiceID: 1}
+}
+
+// copyInvoice returns an invoice that shares no slices or pointers with the stored one
+func copyInvoice(invoice Invoice) Invoice {
+	invoice.Lines = append([]InvoiceLine{}, invoice.Lines...)
+	if invoice.PaidAt != nil {
+		paidAt := *invoice.PaidAt
+		invoice.PaidAt = &paidAt
+	}
+	return invoice
+}
+
+// snapshot returns a function that rolls the ledger back to its current
+// state. The caller holds the lock.
+func (r *MemoryLedgerRepository) snapshot() func() {
+	entries, nextEntryID, nextInvoiceID := len(r.entries), r.nextEntryID, r.nextInvoiceID
+	invoices, invoiced := maps.Clone(r.invoices), maps.Clone(r.invoiced)
+	return func() {
+		r.entries, r.nextEntryID, r.nextInvoiceID = r.entries[:entries], nextEntryID, nextInvoiceID
+		r.invoices, r.invoiced = invoices, invoiced
+	}
+}
+
+// post saves a validated entry. The caller holds the lock.
+func (r *MemoryLedgerRepository) post(entry *JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	entry.ID = r.nextEntryID
+	entry.CreatedAt = time.Now()
+	r.nextEntryID++
+
+	stored := *entry
+	stored.Lines = append([]JournalLine{}, entry.Lines...)
+	r.entries = append(r.entries, stored)
+	return nil
+}
+
+// Post saves a balanced journal entry and applies a payment or refund against
+// an invoice to the invoice, which is paid while its paid amount covers the total
+func (r *MemoryLedgerRepository) Post(entry *JournalEntry, audit *ChangeAudit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rollback := r.snapshot()
+	if err := r.apply(entry); err != nil {
+		return err
+	}
+	if err := audit.record(entry); err != nil {
+		rollback()
+		return err
+	}
+	return nil
+}
+
+// apply saves an entry and applies its settlement to its invoice. The caller
+// holds the lock.
+func (r *MemoryLedgerRepository) apply(entry *JournalEntry) error {
+	settled := entry.Settlement()
+	invoice, ok := Invoice{}, false
+	if settled != 0 {
+		if invoice, ok = r.invoices[*entry.InvoiceID]; !ok || invoice.ClientID != entry.ClientID {
+			return pgx.ErrNoRows
+		}
+		if err := checkSettlement(invoice.Total, invoice.Paid, settled); err != nil {
+			return err
+		}
+	}
+
+	if err := r.post(entry); err != nil {
+		return err
+	}
+
+	if settled != 0 {
+		invoice.Paid += settled
+		switch {
+		case invoice.Paid < invoice.Total:
+			invoice.Status, invoice.PaidAt = InvoiceStatusOpen, nil
+		case invoice.Status != InvoiceStatusPaid:
+			invoice.Status, invoice.PaidAt = InvoiceStatusPaid, &entry.CreatedAt
+		}
+		r.invoices[invoice.ID] = copyInvoice(invoice)
+	}
+	return nil
+}
+
+// AccountTotals returns the sum of an account's lines by entry kind
+func (r *MemoryLedgerRepository) AccountTotals(account string) (map[string]int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	totals := map[string]int64{}
+	for _, entry := range r.entries {
+		for _, line := range entry.Lines {
+			if line.Account == account {
+				totals[entry.Kind] += line.Amount
+			}
+		}
+	}
+	return totals, nil
+}
+
+// invoicedSessions returns the IDs of the sessions on an invoice
+func (r *MemoryLedgerRepository) invoicedSessions() map[int]bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.invoiced)
+}
+
+// InvoiceSessions invoices the completed sessions that are not on an invoice
+// yet, one invoice per client, and posts an entry for each
+func (r *MemoryLedgerRepository) InvoiceSessions(sessions []Session, audit *ChangeAudit) ([]Invoice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rollback := r.snapshot()
+
+	pending := []Session{}
+	for _, session := range sessions {
+		if !r.invoiced[session.ID] {
+			pending = append(pending, session)
+		}
+	}
+
+	invoices := BuildInvoices(pending)
+	now := time.Now()
+	for i := range invoices {
+		invoice := &invoices[i]
+		invoice.ID = r.nextInvoiceID
+		invoice.IssuedAt = now
+		if invoice.Total == 0 {
+			invoice.Status = InvoiceStatusPaid
+			invoice.PaidAt = &now
+		}
+		r.nextInvoiceID++
+
+		if entry := invoice.Entry(); len(entry.Lines) > 0 {
+			if err := r.post(&entry); err != nil {
+				rollback()
+				return nil, err
+			}
+		}
+		r.invoices[invoice.ID] = copyInvoice(*invoice)
+		for _, line := range invoice.Lines {
+			r.invoiced[line.SessionID] = true
+		}
+	}
+	if err := audit.record(invoices); err != nil {
+		rollback()
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// ListInvoices returns one page of the invoices matching the filter, newest first
+func (r *MemoryLedgerRepository) ListInvoices(filter InvoiceFilter) (*InvoicePage, error) {
+	afterID := 0
+	if filter.Cursor != "" {
+		position, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		afterID = position.ID
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := []Invoice{}
+	for _, invoice := range r.invoices {
+		if (filter.ClientID == 0 || invoice.ClientID == filter.ClientID) &&
+			(filter.Status == "" || invoice.Status == filter.Status) {
+			all = append(all, copyInvoice(invoice))
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID > all[j].ID })
+
+	invoices := []Invoice{}
+	for _, invoice := range all {
+		if filter.Cursor == "" || invoice.ID < afterID {
+			invoices = append(invoices, invoice)
+		}
+	}
+
+	limit := filter.pageLimit()
+	page := &InvoicePage{Invoices: invoices, Total: len(all)}
+	if len(invoices) > limit {
+		page.Invoices = invoices[:limit]
+		page.NextCursor = encodeCursor("", page.Invoices[limit-1].ID)
+	}
+	return page, nil
+}
+
+// GetInvoice returns an invoice with its lines
+func (r *MemoryLedgerRepository) GetInvoice(id int) (*Invoice, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invoice, ok := r.invoices[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	invoice = copyInvoice(invoice)
+	return &invoice, nil
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryPairingRepository keeps pairings in memory, checking them against
+// the in-memory tutors and clients
+type MemoryPairingRepository struct {
+	mu       sync.Mutex
+	tutors   *MemoryTutorRepository
+	clients  *MemoryClientRepository
+	pairings []Pairing
+	nextID   int
+}
+
+// NewMemoryPairingRepository returns an empty pairing repository over the
+// given tutors and clients
+func NewMemoryPairingRepository(tutors *MemoryTutorRepository, clients *MemoryClientRepository) *MemoryPairingRepository {
+	return &MemoryPairingRepository{tutors: tutors, clients: clients, nextID: 1}
+}
+
+// ListActive returns all pairings that have not ended, skipping those whose
+// tutor or client is soft-deleted
+func (r *MemoryPairingRepository) ListActive() ([]Pairing, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.active(), nil
+}
+
+// endDeleted ends the open pairings whose tutor or client has been deleted,
+// as deleting them does in Postgres. The caller holds r.mu.
+func (r *MemoryPairingRepository) endDeleted() {
+	now := time.Now()
+	for i, pairing := range r.pairings {
+		if pairing.EndedAt != nil {
+			continue
+		}
+		_, tutorErr := r.tutors.GetByID(pairing.TutorID)
+		_, clientErr := r.clients.GetByID(pairing.ClientID)
+		if tutorErr != nil || clientErr != nil {
+			r.pairings[i].Status, r.pairings[i].EndedAt = PairingStatusEnded, &now
+		}
+	}
+}
+
+// active lists the active pairings, newest first. The caller holds r.mu.
+func (r *MemoryPairingRepository) active() []Pairing {
+	r.endDeleted()
+	pairings := []Pairing{}
+	for _, pairing := range r.pairings {
+		if pairing.Status == PairingStatusActive {
+			pairings = append(pairings, pairing)
+		}
+	}
+	sort.SliceStable(pairings, func(i, j int) bool { return pairings[i].ID > pairings[j].ID })
+	return pairings
+}
+
+// Create saves a batch of pairings. Each client must still be active and
+// unpaired and each tutor must have a free seat when its pairing is saved;
+// otherwise nothing is saved and a *PairingConflictError is returned.
+func (r *MemoryPairingRepository) Create(pairings []Pairing, audit *ChangeAudit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	paired := map[int]bool{}
+	counts := map[int]int{}
+	for _, pairing := range r.active() {
+		paired[pairing.ClientID] = true
+		counts[pairing.TutorID]++
+	}
+
+	for _, pairing := range pairings {
+		client, err := r.clients.GetByID(pairing.ClientID)
+		if err != nil || !client.IsActive() || paired[pairing.ClientID] {
+			return &PairingConflictError{Reason: fmt.Sprintf("client %d is not active or is already paired", pairing.ClientID)}
+		}
+
+		tutor, err := r.tutors.GetByID(pairing.TutorID)
+		if err != nil {
+			return &PairingConflictError{Reason: fmt.Sprintf("tutor %d not found", pairing.TutorID)}
+		}
+		if counts[pairing.TutorID] >= tutor.Capacity() {
+			return &PairingConflictError{Reason: fmt.Sprintf("tutor %d has no remaining capacity", pairing.TutorID)}
+		}
+
+		paired[pairing.ClientID] = true
+		counts[pairing.TutorID]++
+	}
+
+	now := time.Now()
+	for i := range pairings {
+		pairings[i].ID = r.nextID + i
+		pairings[i].Status = PairingStatusActive
+		pairings[i].CreatedAt = now
+	}
+	if err := audit.record(pairings); err != nil {
+		return err
+	}
+	r.nextID += len(pairings)
+	r.pairings = append(r.pairings, pairings...)
+	return nil
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryPaymentRepository keeps payment intents and processed webhook events
+// in memory. It posts entries to the in-memory ledger, as the Postgres
+// repository does to the journal tables.
+type MemoryPaymentRepository struct {
+	mu      sync.Mutex
+	intents map[int]PaymentIntent
+	events  map[string]bool
+	nextID  int
+	ledger  *MemoryLedgerRepository
+}
+
+// NewMemoryPaymentRepository returns an empty in-memory payment repository
+// posting to ledger
+func NewMemoryPaymentRepository(ledger *MemoryLedgerRepository) *MemoryPaymentRepository {
+	return &MemoryPaymentRepository{intents: map[int]PaymentIntent{}, events: map[string]bool{}, nextID: 1, ledger: ledger}
+}
+
+// CreateIntent saves a new payment intent, or returns the saved one if the
+// provider handed back an intent it had already created
+func (r *MemoryPaymentRepository) CreateIntent(intent *PaymentIntent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	secret := intent.ClientSecret
+	for _, existing := range r.intents {
+		if existing.ProviderID == intent.ProviderID {
+			*intent = existing
+			intent.ClientSecret = secret
+			return nil
+		}
+	}
+
+	intent.ID = r.nextID
+	intent.CreatedAt = time.Now()
+	intent.UpdatedAt = intent.CreatedAt
+	r.nextID++
+
+	stored := *intent
+	stored.ClientSecret = ""
+	r.intents[intent.ID] = stored
+	return nil
+}
+
+// ListIntents returns the payment intents of an invoice, newest first
+func (r *MemoryPaymentRepository) ListIntents(invoiceID int) ([]PaymentIntent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	intents := []PaymentIntent{}
+	for _, intent := range r.intents {
+		if intent.InvoiceID == invoiceID {
+			intents = append(intents, intent)
+		}
+	}
+	sort.Slice(intents, func(i, j int) bool { return intents[i].ID > intents[j].ID })
+	return intents, nil
+}
+
+// ApplyEvent applies a webhook event to its intent and posts the resulting
+// entries, skipping events already applied. It reports whether the event was new.
+func (r *MemoryPaymentRepository) ApplyEvent(event PaymentEvent) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.events[event.ID] {
+		return false, nil
+	}
+
+	var intent PaymentIntent
+	found := false
+	for _, stored := range r.intents {
+		if stored.ProviderID == event.ProviderID {
+			intent, found = stored, true
+			break
+		}
+	}
+	if !found {
+		return false, pgx.ErrNoRows
+	}
+	if err := intent.Check(event); err != nil {
+		return false, err
+	}
+
+	r.ledger.mu.Lock()
+	defer r.ledger.mu.Unlock()
+	invoice, ok := r.ledger.invoices[intent.InvoiceID]
+	if !ok {
+		return false, pgx.ErrNoRows
+	}
+
+	changed, entries := intent.Apply(event, &invoice)
+	for i := range entries {
+		if err := r.ledger.apply(&entries[i]); err != nil {
+			return false, err
+		}
+	}
+	if changed {
+		intent.UpdatedAt = time.Now()
+		r.intents[intent.ID] = intent
+	}
+	r.events[event.ID] = true
+	return true, nil
+}
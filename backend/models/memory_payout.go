@@ -0,0 +1,203 @@
+package models
+
+import (
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryPayoutRepository keeps payout statements in memory. It reads paid
+// invoices from and posts entries to the in-memory ledger.
+type MemoryPayoutRepository struct {
+	mu         sync.Mutex
+	statements map[int]PayoutStatement
+	paidOut    map[int]int64
+	// paidOutRate is the commission rate of each session's latest payout
+	paidOutRate map[int]int64
+	nextID      int
+	ledger      *MemoryLedgerRepository
+}
+
+// NewMemoryPayoutRepository returns an empty in-memory payout repository
+// over ledger
+func NewMemoryPayoutRepository(ledger *MemoryLedgerRepository) *MemoryPayoutRepository {
+	return &MemoryPayoutRepository{statements: map[int]PayoutStatement{}, paidOut: map[int]int64{}, paidOutRate: map[int]int64{}, nextID: 1, ledger: ledger}
+}
+
+// copyStatement returns a statement that shares no slices or pointers with the stored one
+func copyStatement(statement PayoutStatement) PayoutStatement {
+	statement.Lines = append([]PayoutLine{}, statement.Lines...)
+	if statement.PaidAt != nil {
+		paidAt := *statement.PaidAt
+		statement.PaidAt = &paidAt
+	}
+	return statement
+}
+
+// snapshot returns a function that rolls the statements and the ledger back
+// to their current state. The caller holds both locks.
+func (r *MemoryPayoutRepository) snapshot() func() {
+	statements, paidOut, paidOutRate, nextID := maps.Clone(r.statements), maps.Clone(r.paidOut), maps.Clone(r.paidOutRate), r.nextID
+	rollbackLedger := r.ledger.snapshot()
+	return func() {
+		r.statements, r.paidOut, r.paidOutRate, r.nextID = statements, paidOut, paidOutRate, nextID
+		rollbackLedger()
+	}
+}
+
+// CreateStatements builds and saves a statement per tutor for the period from
+// the lines of invoices paid before it ended that no statement covers yet and
+// the reversals of lines paid out on invoices no longer paid, and posts each
+// statement's commission
+func (r *MemoryPayoutRepository) CreateStatements(period PayoutPeriod, rate int64, audit *ChangeAudit) ([]PayoutStatement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ledger.mu.Lock()
+	defer r.ledger.mu.Unlock()
+	rollback := r.snapshot()
+
+	invoiceIDs := []int{}
+	for id := range r.ledger.invoices {
+		invoiceIDs = append(invoiceIDs, id)
+	}
+	sort.Ints(invoiceIDs)
+
+	lines := []PayoutLine{}
+	for _, id := range invoiceIDs {
+		invoice := r.ledger.invoices[id]
+		paid := invoice.Status == InvoiceStatusPaid && invoice.PaidAt != nil && invoice.PaidAt.Before(period.End)
+		for _, line := range invoice.Lines {
+			due := int64(0)
+			if paid {
+				due = line.Amount
+			}
+			owed, ok := owedLine(PayoutLine{
+				SessionID:   line.SessionID,
+				InvoiceID:   id,
+				TutorID:     line.TutorID,
+				Description: line.Description,
+				Minutes:     line.Minutes,
+			}, due, r.paidOut[line.SessionID], rate, r.paidOutRate[line.SessionID])
+			if ok {
+				lines = append(lines, owed)
+			}
+		}
+	}
+
+	statements := BuildStatements(lines, period, rate)
+	now := time.Now()
+	for i := range statements {
+		statement := &statements[i]
+		statement.ID = r.nextID
+		statement.CreatedAt = now
+		r.nextID++
+
+		if entry := statement.CommissionEntry(); entry != nil {
+			if err := r.ledger.post(entry); err != nil {
+				rollback()
+				return nil, err
+			}
+		}
+		r.statements[statement.ID] = copyStatement(*statement)
+		for _, line := range statement.Lines {
+			r.paidOut[line.SessionID] += line.Amount
+			if line.Amount > 0 {
+				r.paidOutRate[line.SessionID] = line.CommissionRate
+			}
+		}
+	}
+	if err := audit.record(statements); err != nil {
+		rollback()
+		return nil, err
+	}
+	return statements, nil
+}
+
+// ListStatements returns one page of the statements matching the filter, newest first
+func (r *MemoryPayoutRepository) ListStatements(filter PayoutFilter) (*PayoutPage, error) {
+	afterID := 0
+	if filter.Cursor != "" {
+		position, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		afterID = position.ID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := []PayoutStatement{}
+	for _, statement := range r.statements {
+		if (filter.TutorID == 0 || statement.TutorID == filter.TutorID) &&
+			(filter.Status == "" || statement.Status == filter.Status) {
+			all = append(all, copyStatement(statement))
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID > all[j].ID })
+
+	statements := []PayoutStatement{}
+	for _, statement := range all {
+		if filter.Cursor == "" || statement.ID < afterID {
+			statements = append(statements, statement)
+		}
+	}
+
+	limit := filter.pageLimit()
+	page := &PayoutPage{Statements: statements, Total: len(all)}
+	if len(statements) > limit {
+		page.Statements = statements[:limit]
+		page.NextCursor = encodeCursor("", page.Statements[limit-1].ID)
+	}
+	return page, nil
+}
+
+// GetStatement returns a statement with its lines
+func (r *MemoryPayoutRepository) GetStatement(id int) (*PayoutStatement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statement, ok := r.statements[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	statement = copyStatement(statement)
+	return &statement, nil
+}
+
+// MarkPaid records that a statement's net amount was paid to the tutor and
+// posts the payout
+func (r *MemoryPayoutRepository) MarkPaid(statement *PayoutStatement, audit *ChangeAudit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ledger.mu.Lock()
+	defer r.ledger.mu.Unlock()
+
+	stored, ok := r.statements[statement.ID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	if stored.Status != PayoutStatusPending {
+		return ErrStatementPaid
+	}
+
+	now := time.Now()
+	stored.Status, stored.PaidBy, stored.Reference, stored.PaidAt = PayoutStatusPaid, statement.PaidBy, statement.Reference, &now
+	rollback := r.snapshot()
+	if entry := stored.PayoutEntry(); entry != nil {
+		if err := r.ledger.apply(entry); err != nil {
+			return err
+		}
+	}
+	paid := copyStatement(stored)
+	if err := audit.record(&paid); err != nil {
+		rollback()
+		return err
+	}
+	r.statements[stored.ID] = stored
+	*statement = paid
+	return nil
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemoryReviewRepository keeps reviews in memory. It writes ratings to the
+// in-memory tutor repository, as the Postgres repository does to the tutors
+// table.
+type MemoryReviewRepository struct {
+	mu      sync.RWMutex
+	reviews map[int]Review
+	nextID  int
+	tutors  *MemoryTutorRepository
+}
+
+// NewMemoryReviewRepository returns an empty in-memory review repository
+// whose ratings are written to tutors
+func NewMemoryReviewRepository(tutors *MemoryTutorRepository) *MemoryReviewRepository {
+	return &MemoryReviewRepository{reviews: map[int]Review{}, nextID: 1, tutors: tutors}
+}
+
+// List returns one page of the reviews matching the filter, newest first
+func (r *MemoryReviewRepository) List(filter ReviewFilter) (*ReviewPage, error) {
+	var afterTime time.Time
+	var afterID int
+	if filter.Cursor != "" {
+		var err error
+		if afterTime, afterID, err = decodeReviewCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := map[string]bool{}
+	for _, status := range filter.Statuses {
+		statuses[status] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := []Review{}
+	for _, review := range r.reviews {
+		if (filter.TutorID == 0 || review.TutorID == filter.TutorID) &&
+			(len(statuses) == 0 || statuses[review.Status]) {
+			all = append(all, copyReview(review))
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.After(all[j].CreatedAt)
+		}
+		return all[i].ID > all[j].ID
+	})
+
+	reviews := []Review{}
+	for _, review := range all {
+		if filter.Cursor != "" && (review.CreatedAt.After(afterTime) ||
+			review.CreatedAt.Equal(afterTime) && review.ID >= afterID) {
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+
+	limit := filter.pageLimit()
+	page := &ReviewPage{Reviews: reviews, Total: len(all)}
+	if len(reviews) > limit {
+		page.Reviews = reviews[:limit]
+		page.NextCursor = reviewCursor(page.Reviews[limit-1])
+	}
+	return page, nil
+}
+
+// copyReview returns a review that shares no pointers with the stored one
+func copyReview(review Review) Review {
+	if review.ModeratedAt != nil {
+		moderatedAt := *review.ModeratedAt
+		review.ModeratedAt = &moderatedAt
+	}
+	return review
+}
+
+// GetByID returns a review
+func (r *MemoryReviewRepository) GetByID(id int) (*Review, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	review, ok := r.reviews[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	review = copyReview(review)
+	return &review, nil
+}
+
+// GetBySession returns the review of a session
+func (r *MemoryReviewRepository) GetBySession(sessionID int) (*Review, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, review := range r.reviews {
+		if review.SessionID == sessionID {
+			review = copyReview(review)
+			return &review, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// Create saves a new review and recomputes its tutor's rating
+func (r *MemoryReviewRepository) Create(review *Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.reviews {
+		if existing.SessionID == review.SessionID {
+			return ErrAlreadyReviewed
+		}
+	}
+
+	review.ID = r.nextID
+	review.CreatedAt = time.Now()
+	r.nextID++
+	r.reviews[review.ID] = *review
+
+	r.refreshTutorRating(review.TutorID)
+	return nil
+}
+
+// Moderate saves a review's status, comment, redaction and moderator, and
+// recomputes its tutor's rating
+func (r *MemoryReviewRepository) Moderate(review *Review, audit *ChangeAudit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.reviews[review.ID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	now := time.Now()
+	stored.Status, stored.Comment, stored.Redacted = review.Status, review.Comment, review.Redacted
+	stored.ModeratedBy, stored.ModeratedAt = review.ModeratedBy, &now
+	moderated := copyReview(stored)
+	if err := audit.record(&moderated); err != nil {
+		return err
+	}
+	r.reviews[review.ID] = stored
+	*review = moderated
+
+	r.refreshTutorRating(review.TutorID)
+	return nil
+}
+
+// refreshTutorRating recomputes a tutor's rating from their published
+// reviews. The caller holds the lock.
+func (r *MemoryReviewRepository) refreshTutorRating(tutorID int) {
+	var count, total int
+	for _, review := range r.reviews {
+		if review.TutorID == tutorID && review.Status == ReviewStatusPublished {
+			count++
+			total += review.Rating
+		}
+	}
+	r.tutors.setRating(tutorID, BayesianRating(count, total), count)
+}
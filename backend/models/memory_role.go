@@ -0,0 +1,178 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// builtInRoles are the roles and permissions seeded by the migrations
+var builtInRoles = []Role{
+	{ID: 1, Name: RoleAdmin, Description: "Full access, including managing roles", Permissions: []string{
+		PermissionAssignmentsWrite,
+		PermissionAuditRead,
+		PermissionBillingWrite,
+		PermissionClientsWrite,
+		PermissionReviewsWrite,
+		PermissionRolesWrite,
+		PermissionStatsRead,
+		PermissionTutorsWrite,
+	}},
+	{ID: 2, Name: RoleCoordinator, Description: "Manages profiles and pairings", Permissions: []string{
+		PermissionAssignmentsWrite,
+		PermissionClientsWrite,
+		PermissionReviewsWrite,
+		PermissionStatsRead,
+		PermissionTutorsWrite,
+	}},
+	{ID: 3, Name: RoleTutor, Description: "Tutor with a profile", Permissions: []string{}},
+	{ID: 4, Name: RoleClient, Description: "Client with a profile", Permissions: []string{}},
+}
+
+// MemoryRoleRepository keeps role grants in memory over the built-in roles
+type MemoryRoleRepository struct {
+	mu     sync.RWMutex
+	users  map[string]User
+	nextID int
+}
+
+// NewMemoryRoleRepository returns a role repository with no grants
+func NewMemoryRoleRepository() *MemoryRoleRepository {
+	return &MemoryRoleRepository{users: map[string]User{}, nextID: 1}
+}
+
+// findRole returns the built-in role with the name
+func findRole(name string) (Role, bool) {
+	for _, role := range builtInRoles {
+		if role.Name == name {
+			return role, true
+		}
+	}
+	return Role{}, false
+}
+
+// Roles returns every role with its permissions
+func (r *MemoryRoleRepository) Roles() ([]Role, error) {
+	roles := make([]Role, 0, len(builtInRoles))
+	for _, role := range builtInRoles {
+		role.Permissions = append([]string{}, role.Permissions...)
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// Users returns every user holding at least one role, ordered by email
+func (r *MemoryRoleRepository) Users() ([]User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []User{}
+	for _, user := range r.users {
+		if len(user.Roles) > 0 {
+			user.Roles = append([]string{}, user.Roles...)
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	return users, nil
+}
+
+// Access returns the roles and permissions granted to an email
+func (r *MemoryRoleRepository) Access(email string) (*UserAccess, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	access := &UserAccess{Email: email, Roles: []string{}, Permissions: []string{}}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	granted := map[string]bool{}
+	for _, name := range r.users[email].Roles {
+		access.Roles = append(access.Roles, name)
+		role, _ := findRole(name)
+		for _, permission := range role.Permissions {
+			if !granted[permission] {
+				granted[permission] = true
+				access.Permissions = append(access.Permissions, permission)
+			}
+		}
+	}
+	sort.Strings(access.Permissions)
+	return access, nil
+}
+
+// Grant gives a role to the user with the email, creating the user if
+// needed. Granting a role the user already holds is a no-op.
+func (r *MemoryRoleRepository) Grant(email, role, grantedBy string, audit *ChangeAudit) error {
+	if _, ok := findRole(role); !ok {
+		return ErrUnknownRole
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := audit.record(nil); err != nil {
+		return err
+	}
+	user, ok := r.users[email]
+	if !ok {
+		user = User{ID: r.nextID, Email: email, Roles: []string{}, CreatedAt: time.Now()}
+		r.nextID++
+	}
+	for _, held := range user.Roles {
+		if held == role {
+			return nil
+		}
+	}
+	user.Roles = append(append([]string{}, user.Roles...), role)
+	sort.Strings(user.Roles)
+	r.users[email] = user
+	return nil
+}
+
+// Revoke removes a role from the user with the email. The admin role
+// cannot be revoked from the last remaining admin.
+func (r *MemoryRoleRepository) Revoke(email, role string, audit *ChangeAudit) error {
+	if _, ok := findRole(role); !ok {
+		return ErrUnknownRole
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user := r.users[email]
+	remaining := []string{}
+	for _, held := range user.Roles {
+		if held != role {
+			remaining = append(remaining, held)
+		}
+	}
+	if len(remaining) == len(user.Roles) {
+		return pgx.ErrNoRows
+	}
+
+	if role == RoleAdmin {
+		admins := 0
+		for _, other := range r.users {
+			for _, held := range other.Roles {
+				if held == RoleAdmin {
+					admins++
+				}
+			}
+		}
+		if admins == 1 {
+			return ErrLastAdmin
+		}
+	}
+
+	if err := audit.record(nil); err != nil {
+		return err
+	}
+	user.Roles = remaining
+	r.users[email] = user
+	return nil
+}
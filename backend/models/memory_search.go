@@ -0,0 +1,160 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// textQuery is a websearch-style query evaluated without Postgres. Every
+// clause must match, where a clause is one or more terms joined by "or";
+// excluded terms ("-word") must not appear. Words are compared by a light
+// English stem and stop words are ignored, approximating to_tsvector('english').
+type textQuery struct {
+	clauses  [][]string
+	excluded []string
+}
+
+var textWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// textStopWords are common English words the Postgres english config drops
+var textStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "this": true, "to": true, "with": true,
+}
+
+// textStem reduces a lower-case word to a crude stem, so "teaching",
+// "teaches" and "teach" compare equal
+func textStem(word string) string {
+	switch {
+	case len(word) > 5 && strings.HasSuffix(word, "ing"):
+		return word[:len(word)-3]
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 4 && strings.HasSuffix(word, "ed"):
+		return word[:len(word)-2]
+	case len(word) > 4 && strings.HasSuffix(word, "es"):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// parseTextQuery parses a websearch-style query such as `calculus or algebra -online`
+func parseTextQuery(query string) textQuery {
+	var parsed textQuery
+	joinNext := false
+
+	for _, token := range strings.Fields(strings.ToLower(query)) {
+		if token == "or" {
+			joinNext = len(parsed.clauses) > 0
+			continue
+		}
+
+		excluded := strings.HasPrefix(token, "-")
+		for _, word := range textWordPattern.FindAllString(token, -1) {
+			if textStopWords[word] {
+				continue
+			}
+			stem := textStem(word)
+			switch {
+			case excluded:
+				parsed.excluded = append(parsed.excluded, stem)
+			case joinNext:
+				last := len(parsed.clauses) - 1
+				parsed.clauses[last] = append(parsed.clauses[last], stem)
+			default:
+				parsed.clauses = append(parsed.clauses, []string{stem})
+			}
+			joinNext = false
+		}
+	}
+
+	return parsed
+}
+
+// empty reports whether the query has no terms to search for
+func (q textQuery) empty() bool {
+	return len(q.clauses) == 0 && len(q.excluded) == 0
+}
+
+// match reports whether text satisfies the query. A query of only stop words
+// matches nothing, like an empty tsquery. The rank grows with the
+// number of matching words; the snippet is the HTML-escaped text with matches
+// wrapped in <mark> tags.
+func (q textQuery) match(text string) (float32, string, bool) {
+	if q.empty() {
+		return 0, "", false
+	}
+
+	stems := map[string]int{}
+	for _, word := range textWordPattern.FindAllString(strings.ToLower(text), -1) {
+		stems[textStem(word)]++
+	}
+
+	for _, stem := range q.excluded {
+		if stems[stem] > 0 {
+			return 0, "", false
+		}
+	}
+
+	wanted := map[string]bool{}
+	hits := 0
+	for _, clause := range q.clauses {
+		found := false
+		for _, stem := range clause {
+			if stems[stem] > 0 {
+				found = true
+				wanted[stem] = true
+				hits += stems[stem]
+			}
+		}
+		if !found {
+			return 0, "", false
+		}
+	}
+
+	var snippet strings.Builder
+	position := 0
+	for _, bounds := range textWordPattern.FindAllStringIndex(text, -1) {
+		snippet.WriteString(escapeHTML(text[position:bounds[0]]))
+		word := text[bounds[0]:bounds[1]]
+		if wanted[textStem(strings.ToLower(word))] {
+			snippet.WriteString("<mark>" + escapeHTML(word) + "</mark>")
+		} else {
+			snippet.WriteString(escapeHTML(word))
+		}
+		position = bounds[1]
+	}
+	snippet.WriteString(escapeHTML(text[position:]))
+
+	return float32(hits) / 10, snippet.String(), true
+}
+
+// escapeHTML escapes text the same way the SQL snippet expressions do
+var escapeHTML = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+// compareSortValues compares two cursor values of a sort option, parsed by
+// the option's SQL cast
+func compareSortValues(cast, a, b string) int {
+	switch cast {
+	case "timestamptz":
+		at, _ := time.Parse(time.RFC3339Nano, a)
+		bt, _ := time.Parse(time.RFC3339Nano, b)
+		return at.Compare(bt)
+	case "numeric", "real":
+		af, _ := strconv.ParseFloat(a, 64)
+		bf, _ := strconv.ParseFloat(b, 64)
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
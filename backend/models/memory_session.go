@@ -0,0 +1,228 @@
+package models
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MemorySessionRepository keeps sessions in memory, enforcing the same
+// no-overlap rule for confirmed sessions as the Postgres repository
+type MemorySessionRepository struct {
+	mu           sync.RWMutex
+	sessions     map[int]Session
+	series       map[int]SessionSeries
+	nextID       int
+	nextSeriesID int
+	ledger       *MemoryLedgerRepository
+}
+
+// NewMemorySessionRepository returns an empty in-memory session repository
+// whose sessions are invoiced in ledger
+func NewMemorySessionRepository(ledger *MemoryLedgerRepository) *MemorySessionRepository {
+	return &MemorySessionRepository{
+		sessions:     map[int]Session{},
+		series:       map[int]SessionSeries{},
+		nextID:       1,
+		nextSeriesID: 1,
+		ledger:       ledger,
+	}
+}
+
+// collect returns the stored sessions accepted by keep, earliest first. The
+// caller must hold the lock.
+func (r *MemorySessionRepository) collect(keep func(Session) bool) []Session {
+	sessions := []Session{}
+	for _, session := range r.sessions {
+		if keep(session) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].StartsAt.Equal(sessions[j].StartsAt) {
+			return sessions[i].StartsAt.Before(sessions[j].StartsAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
+
+// List returns the sessions matching the filter, earliest first
+func (r *MemorySessionRepository) List(filter SessionFilter) ([]Session, error) {
+	statuses := map[string]bool{}
+	for _, status := range filter.Statuses {
+		statuses[status] = true
+	}
+	var invoiced map[int]bool
+	if filter.Uninvoiced {
+		invoiced = r.ledger.invoicedSessions()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.collect(func(s Session) bool {
+		switch {
+		case (filter.TutorID != 0 || filter.ClientID != 0) &&
+			!(filter.TutorID != 0 && s.TutorID == filter.TutorID) &&
+			!(filter.ClientID != 0 && s.ClientID == filter.ClientID):
+			return false
+		case filter.SeriesID != 0 && (s.SeriesID == nil || *s.SeriesID != filter.SeriesID):
+			return false
+		case len(statuses) > 0 && !statuses[s.Status]:
+			return false
+		case filter.From != nil && !s.EndsAt.After(*filter.From):
+			return false
+		case filter.To != nil && !s.StartsAt.Before(*filter.To):
+			return false
+		case invoiced[s.ID]:
+			return false
+		}
+		return true
+	}), nil
+}
+
+// GetByID returns the session with the ID
+func (r *MemorySessionRepository) GetByID(id int) (*Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &session, nil
+}
+
+// conflicts returns confirmed sessions of the tutor or client overlapping the
+// span. The caller must hold the lock.
+func (r *MemorySessionRepository) conflicts(tutorID, clientID int, start, end time.Time, excludeID int) []Session {
+	return r.collect(func(s Session) bool {
+		return s.Status == SessionStatusConfirmed && s.ID != excludeID &&
+			(s.TutorID == tutorID || s.ClientID == clientID) && s.Overlaps(start, end)
+	})
+}
+
+// Conflicts returns confirmed sessions of the tutor or client overlapping the span
+func (r *MemorySessionRepository) Conflicts(tutorID, clientID int, start, end time.Time, excludeID int) ([]Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.conflicts(tutorID, clientID, start, end, excludeID), nil
+}
+
+// insert stores a new session. The caller must hold the lock.
+func (r *MemorySessionRepository) insert(session *Session) error {
+	if session.Status == SessionStatusConfirmed &&
+		len(r.conflicts(session.TutorID, session.ClientID, session.StartsAt, session.EndsAt, 0)) > 0 {
+		return ErrSessionConflict
+	}
+
+	now := time.Now()
+	session.ID = r.nextID
+	session.CreatedAt, session.UpdatedAt = now, now
+	r.nextID++
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// update stores a session's changes. The caller must hold the lock.
+func (r *MemorySessionRepository) update(session *Session) error {
+	stored, ok := r.sessions[session.ID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	if session.Status == SessionStatusConfirmed &&
+		len(r.conflicts(session.TutorID, session.ClientID, session.StartsAt, session.EndsAt, session.ID)) > 0 {
+		return ErrSessionConflict
+	}
+
+	stored.Subject = session.Subject
+	stored.StartsAt, stored.EndsAt = session.StartsAt, session.EndsAt
+	stored.Status = session.Status
+	stored.CancelledBy, stored.CancelReason = session.CancelledBy, session.CancelReason
+	stored.UpdatedAt = time.Now()
+	r.sessions[session.ID] = stored
+
+	session.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+// snapshot copies the stored sessions so a failed batch can be undone. The
+// caller must hold the lock.
+func (r *MemorySessionRepository) snapshot() (map[int]Session, int) {
+	sessions := make(map[int]Session, len(r.sessions))
+	for id, session := range r.sessions {
+		sessions[id] = session
+	}
+	return sessions, r.nextID
+}
+
+// Create saves a new session
+func (r *MemorySessionRepository) Create(session *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(session)
+}
+
+// Update saves a session's subject, times, status and cancellation
+func (r *MemorySessionRepository) Update(session *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.update(session)
+}
+
+// UpdateMany saves several sessions; either all of them are saved or none
+func (r *MemorySessionRepository) UpdateMany(sessions []Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved, nextID := r.snapshot()
+	for i := range sessions {
+		if err := r.update(&sessions[i]); err != nil {
+			r.sessions, r.nextID = saved, nextID
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSeries returns the session series with the ID
+func (r *MemorySessionRepository) GetSeries(id int) (*SessionSeries, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	series, ok := r.series[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &series, nil
+}
+
+// CreateSeries saves a new series and its occurrences; either all of them are
+// saved or none
+func (r *MemorySessionRepository) CreateSeries(series *SessionSeries, occurrences []Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved, nextID := r.snapshot()
+	seriesID := r.nextSeriesID
+	for i := range occurrences {
+		occurrences[i].SeriesID = &seriesID
+		if err := r.insert(&occurrences[i]); err != nil {
+			r.sessions, r.nextID = saved, nextID
+			return err
+		}
+	}
+
+	now := time.Now()
+	series.ID = seriesID
+	series.CreatedAt, series.UpdatedAt = now, now
+	r.nextSeriesID++
+	r.series[series.ID] = *series
+	return nil
+}
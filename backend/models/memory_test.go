@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestMemoryTutorSoftDelete(t *testing.T) {
+	tutors := NewMemoryTutorRepository(NewMemorySubjectRepository())
+	ada := &Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}, Timezone: "UTC"}
+	if err := tutors.Create(ada); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := tutors.Delete(ada.ID, nil); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := tutors.GetByID(ada.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetByID of a deleted tutor = %v, want ErrNoRows", err)
+	}
+	if listed, _ := tutors.List(); len(listed) != 0 {
+		t.Errorf("List = %+v, want deleted tutors left out", listed)
+	}
+	if err := tutors.Delete(ada.ID, nil); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second Delete = %v, want ErrNoRows", err)
+	}
+	deleted, _ := tutors.ListDeleted()
+	if len(deleted) != 1 || deleted[0].ID != ada.ID || deleted[0].DeletedAt == nil {
+		t.Fatalf("ListDeleted = %+v, want Ada with a deletion time", deleted)
+	}
+
+	restored, err := tutors.Restore(ada.ID, nil)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Email != ada.Email {
+		t.Errorf("Restore = %+v, want Ada without a deletion time", restored)
+	}
+	if _, err := tutors.GetByID(ada.ID); err != nil {
+		t.Errorf("GetByID of a restored tutor = %v", err)
+	}
+	if _, err := tutors.Restore(ada.ID, nil); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("Restore of a tutor that is not deleted = %v, want ErrNoRows", err)
+	}
+	if deleted, _ := tutors.ListDeleted(); len(deleted) != 0 {
+		t.Errorf("ListDeleted = %+v, want none after restoring", deleted)
+	}
+}
+
+func TestMemoryPurgeKeepsProfilesInsideRetention(t *testing.T) {
+	t.Setenv("SOFT_DELETE_RETENTION_DAYS", "7")
+	retention := RetentionPeriod()
+	if retention != 7*24*time.Hour {
+		t.Fatalf("RetentionPeriod = %v, want 7 days", retention)
+	}
+
+	subjects := NewMemorySubjectRepository()
+	tutors := NewMemoryTutorRepository(subjects)
+	clients := NewMemoryClientRepository(subjects)
+	ada := &Tutor{Name: "Ada", Email: "ada@example.com", Subjects: []string{"Math"}, Timezone: "UTC"}
+	alan := &Tutor{Name: "Alan", Email: "alan@example.com", Subjects: []string{"Math"}, Timezone: "UTC"}
+	for _, tutor := range []*Tutor{ada, alan} {
+		if err := tutors.Create(tutor); err != nil {
+			t.Fatalf("Create tutor: %v", err)
+		}
+	}
+	grace := &Client{Name: "Grace", Email: "grace@example.com", Subjects: []string{"Math"}, Timezone: "UTC"}
+	if err := clients.Create(grace); err != nil {
+		t.Fatalf("Create client: %v", err)
+	}
+	busy := []Interval{{Start: time.Now(), End: time.Now().Add(time.Hour)}}
+	for _, tutor := range []*Tutor{ada, alan} {
+		if _, err := tutors.ReplaceAvailabilityExceptions(tutor.ID, "calendar", busy); err != nil {
+			t.Fatalf("ReplaceAvailabilityExceptions: %v", err)
+		}
+	}
+	if err := tutors.Delete(ada.ID, nil); err != nil {
+		t.Fatalf("Delete tutor: %v", err)
+	}
+	if err := clients.Delete(grace.ID, nil); err != nil {
+		t.Fatalf("Delete client: %v", err)
+	}
+
+	// Deleted just now, so still inside the retention window
+	result, err := PurgeDeletedProfiles(tutors, clients, time.Now().Add(-retention))
+	if err != nil {
+		t.Fatalf("PurgeDeletedProfiles: %v", err)
+	}
+	if *result != (PurgeResult{}) {
+		t.Errorf("purge inside the retention window = %+v, want nothing purged", result)
+	}
+	if _, err := tutors.Restore(ada.ID, nil); err != nil {
+		t.Fatalf("Restore inside the retention window: %v", err)
+	}
+	if err := tutors.Delete(ada.ID, nil); err != nil {
+		t.Fatalf("Delete tutor: %v", err)
+	}
+
+	// Once the window has passed the purge removes them for good
+	result, err = PurgeDeletedProfiles(tutors, clients, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("PurgeDeletedProfiles: %v", err)
+	}
+	if *result != (PurgeResult{Tutors: 1, Clients: 1}) {
+		t.Errorf("purge past the retention window = %+v, want one tutor and one client", result)
+	}
+	if _, err := tutors.Restore(ada.ID, nil); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("Restore of a purged tutor = %v, want ErrNoRows", err)
+	}
+	if _, err := clients.Restore(grace.ID, nil); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("Restore of a purged client = %v, want ErrNoRows", err)
+	}
+	if deleted, _ := tutors.ListDeleted(); len(deleted) != 0 {
+		t.Errorf("ListDeleted = %+v, want none after purging", deleted)
+	}
+
+	// Only the purged tutor's exceptions go with them
+	exceptions, _ := tutors.AvailabilityExceptions([]int{ada.ID, alan.ID}, busy[0].Start, busy[0].End)
+	if len(exceptions[ada.ID]) != 0 || len(exceptions[alan.ID]) != 1 {
+		t.Errorf("exceptions = %+v, want only Alan's kept", exceptions)
+	}
+	if _, err := tutors.GetByID(alan.ID); err != nil {
+		t.Errorf("GetByID of a tutor that was never deleted = %v", err)
+	}
+}
+
+func TestRetentionPeriod(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", DefaultRetentionDays * 24 * time.Hour},
+		{"0", 0},
+		{"90", 90 * 24 * time.Hour},
+		{"-1", DefaultRetentionDays * 24 * time.Hour},
+		{"a month", DefaultRetentionDays * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Setenv("SOFT_DELETE_RETENTION_DAYS", tt.value)
+		if got := RetentionPeriod(); got != tt.want {
+			t.Errorf("RetentionPeriod with %q = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Pairing statuses
+const (
+	PairingStatusActive = "active"
+	PairingStatusEnded  = "ended"
+)
+
+// Pairing records that a client has been paired with a tutor
+type Pairing struct {
+	ID        int        `json:"id"`
+	TutorID   int        `json:"tutor_id"`
+	ClientID  int        `json:"client_id"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+// pairingLock is the advisory lock namespace serializing pairing commits
+const pairingLock = 4
+
+// PairingConflictError explains why a pairing can no longer be made
+type PairingConflictError struct {
+	Reason string
+}
+
+func (e *PairingConflictError) Error() string {
+	return e.Reason
+}
+
+// endPairings ends the open pairings of the tutor or client whose ID is in
+// column, e.g. when the profile is deleted
+func endPairings(ctx context.Context, tx pgx.Tx, column string, id int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE pairings
+		SET status = $2, ended_at = CURRENT_TIMESTAMP
+		WHERE `+column+` = $1 AND ended_at IS NULL
+	`, id, PairingStatusEnded)
+	return err
+}
+
+// GetPairedClientIDs returns the set of clients that currently have an active pairing
+func GetPairedClientIDs(pairings PairingRepository) (map[int]bool, error) {
+	active, err := pairings.ListActive()
+	if err != nil {
+		return nil, err
+	}
+
+	paired := make(map[int]bool, len(active))
+	for _, pairing := range active {
+		paired[pairing.ClientID] = true
+	}
+
+	return paired, nil
+}
+
+// GetActivePairingCounts returns the number of active pairings per tutor
+func GetActivePairingCounts(pairings PairingRepository) (map[int]int, error) {
+	active, err := pairings.ListActive()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int)
+	for _, pairing := range active {
+		counts[pairing.TutorID]++
+	}
+
+	return counts, nil
+}
+
+// PostgresPairingRepository stores pairings in the pairings table
+type PostgresPairingRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresPairingRepository returns a pairing repository backed by the pool
+func NewPostgresPairingRepository(db *pgxpool.Pool) *PostgresPairingRepository {
+	return &PostgresPairingRepository{db: db}
+}
+
+// ListActive returns all pairings that have not ended, skipping those whose
+// tutor or client is soft-deleted
+func (r *PostgresPairingRepository) ListActive() ([]Pairing, error) {
+	query := `
+		SELECT p.id, p.tutor_id, p.client_id, p.status, p.created_at, p.ended_at
+		FROM pairings p
+		JOIN tutors t ON t.id = p.tutor_id AND t.deleted_at IS NULL
+		JOIN clients c ON c.id = p.client_id AND c.deleted_at IS NULL
+		WHERE p.status = $1
+		ORDER BY p.created_at DESC
+	`
+
+	rows, err := r.db.Query(context.Background(), query, PairingStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairings := []Pairing{}
+	for rows.Next() {
+		var pairing Pairing
+		err := rows.Scan(
+			&pairing.ID,
+			&pairing.TutorID,
+			&pairing.ClientID,
+			&pairing.Status,
+			&pairing.CreatedAt,
+			&pairing.EndedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pairings = append(pairings, pairing)
+	}
+
+	return pairings, rows.Err()
+}
+
+// Create saves a batch of pairings in a single transaction. Commits are
+// serialized, and each client must still be active and unpaired and each
+// tutor must have a free seat when its pairing is saved; otherwise nothing is
+// saved and a *PairingConflictError is returned.
+func (r *PostgresPairingRepository) Create(pairings []Pairing, audit *ChangeAudit) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, 0)`, pairingLock); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO pairings (tutor_id, client_id, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, status, created_at
+	`
+
+	for i := range pairings {
+		var open bool
+		err := tx.QueryRow(ctx, `
+			SELECT COALESCE(c.active, TRUE) AND NOT EXISTS (
+				SELECT 1 FROM pairings p WHERE p.client_id = c.id AND p.ended_at IS NULL
+			)
+			FROM clients c
+			WHERE c.id = $1 AND c.deleted_at IS NULL
+		`, pairings[i].ClientID).Scan(&open)
+		if err != nil && err != pgx.ErrNoRows {
+			return err
+		}
+		if !open {
+			return &PairingConflictError{Reason: fmt.Sprintf("client %d is not active or is already paired", pairings[i].ClientID)}
+		}
+
+		// Counted like ListActive, which the plan was built from
+		tutor := Tutor{}
+		var paired int
+		err = tx.QueryRow(ctx, `
+			SELECT t.max_clients, (
+				SELECT COUNT(*)
+				FROM pairings p
+				JOIN clients c ON c.id = p.client_id AND c.deleted_at IS NULL
+				WHERE p.tutor_id = t.id AND p.status = $2
+			)
+			FROM tutors t
+			WHERE t.id = $1 AND t.deleted_at IS NULL
+		`, pairings[i].TutorID, PairingStatusActive).Scan(&tutor.MaxClients, &paired)
+		if err == pgx.ErrNoRows {
+			return &PairingConflictError{Reason: fmt.Sprintf("tutor %d not found", pairings[i].TutorID)}
+		}
+		if err != nil {
+			return err
+		}
+		if paired >= tutor.Capacity() {
+			return &PairingConflictError{Reason: fmt.Sprintf("tutor %d has no remaining capacity", pairings[i].TutorID)}
+		}
+
+		err = tx.QueryRow(ctx, query, pairings[i].TutorID, pairings[i].ClientID, PairingStatusActive).
+			Scan(&pairings[i].ID, &pairings[i].Status, &pairings[i].CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	if err := audit.save(ctx, tx, pairings); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
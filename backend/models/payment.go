@@ -0,0 +1,266 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Payment intent statuses
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusRefunded  = "refunded"
+)
+
+// Payment outcomes reported by the provider
+const (
+	PaymentSucceeded = "succeeded"
+	PaymentFailed    = "failed"
+	PaymentRefunded  = "refunded"
+)
+
+// ErrEventMismatch is returned for webhook events whose amount or currency
+// differ from their intent's
+var ErrEventMismatch = errors.New("event amount or currency does not match the payment intent")
+
+// PaymentIntent is a checkout started with the payment provider to collect
+// what is due on an invoice. Refunded is the part of Amount returned since.
+// Unapplied is the part of Amount that was not due on the invoice when the
+// payment succeeded, e.g. because it had been paid another way meanwhile; it
+// is held as credit for the client and should be refunded. The client secret
+// comes from the provider and is only returned when the checkout is started.
+type PaymentIntent struct {
+	ID           int       `json:"id"`
+	ProviderID   string    `json:"provider_id"`
+	InvoiceID    int       `json:"invoice_id"`
+	ClientID     int       `json:"client_id"`
+	Amount       int64     `json:"amount"`
+	Refunded     int64     `json:"refunded"`
+	Unapplied    int64     `json:"unapplied"`
+	Currency     string    `json:"currency"`
+	Status       string    `json:"status"`
+	CheckoutURL  string    `json:"checkout_url,omitempty"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PaymentEvent is a provider webhook event about an intent. Amount and
+// Currency are the intent's as the provider reports them; for refunds,
+// Refunded is the running total refunded on the intent.
+type PaymentEvent struct {
+	ID         string
+	Outcome    string
+	ProviderID string
+	Amount     int64
+	Currency   string
+	Refunded   int64
+}
+
+// Check returns ErrEventMismatch unless the event is for the intent's amount
+// and currency
+func (p *PaymentIntent) Check(event PaymentEvent) error {
+	if event.Amount != p.Amount || !strings.EqualFold(event.Currency, p.Currency) || event.Refunded > p.Amount {
+		return ErrEventMismatch
+	}
+	return nil
+}
+
+// Apply updates the intent for a checked event and returns the journal
+// entries to post, given its invoice as it stands. Only what is still due on
+// an open invoice is applied to it; the rest of a payment is posted as
+// unapplied credit, and refunds return that credit first. Events that do not
+// move the intent forward, like a late failure after it succeeded, change
+// nothing.
+func (p *PaymentIntent) Apply(event PaymentEvent, invoice *Invoice) (changed bool, entries []JournalEntry) {
+	invoiceID := p.InvoiceID
+	switch event.Outcome {
+	case PaymentSucceeded:
+		if p.Status != PaymentStatusPending && p.Status != PaymentStatusFailed {
+			return false, nil
+		}
+		p.Status = PaymentStatusSucceeded
+
+		applied := p.Amount
+		if invoice.Status != InvoiceStatusOpen {
+			applied = 0
+		}
+		applied = max(min(applied, invoice.Total-invoice.Paid), 0)
+		p.Unapplied = p.Amount - applied
+		if applied > 0 {
+			entries = append(entries, PaymentEntry(p.ClientID, applied, &invoiceID, "Card payment "+p.ProviderID))
+		}
+		if p.Unapplied > 0 {
+			entries = append(entries, PaymentEntry(p.ClientID, p.Unapplied, nil,
+				fmt.Sprintf("Card payment %s not due on invoice %d, held as credit", p.ProviderID, invoiceID)))
+		}
+		return true, entries
+
+	case PaymentFailed:
+		if p.Status != PaymentStatusPending {
+			return false, nil
+		}
+		p.Status = PaymentStatusFailed
+		return true, nil
+
+	case PaymentRefunded:
+		if p.Status != PaymentStatusSucceeded && p.Status != PaymentStatusRefunded {
+			return false, nil
+		}
+		if event.Refunded <= p.Refunded {
+			return false, nil
+		}
+		refund := event.Refunded - p.Refunded
+		credit := min(refund, max(p.Unapplied-p.Refunded, 0))
+		applied := min(refund-credit, invoice.Paid)
+		credit = refund - applied
+		if applied > 0 {
+			entries = append(entries, RefundEntry(p.ClientID, applied, &invoiceID, "Card refund "+p.ProviderID))
+		}
+		if credit > 0 {
+			entries = append(entries, RefundEntry(p.ClientID, credit, nil,
+				fmt.Sprintf("Card refund %s of credit not applied to invoice %d", p.ProviderID, invoiceID)))
+		}
+		p.Refunded, p.Status = event.Refunded, PaymentStatusRefunded
+		return true, entries
+	}
+	return false, nil
+}
+
+// paymentIntentColumns is the column list scanned by scanPaymentIntent
+const paymentIntentColumns = `id, provider_id, invoice_id, client_id, amount, refunded, unapplied, currency, status, COALESCE(checkout_url, ''), created_at, updated_at`
+
+// scanPaymentIntent scans a row selected with paymentIntentColumns
+func scanPaymentIntent(row pgx.Row) (PaymentIntent, error) {
+	var intent PaymentIntent
+	err := row.Scan(
+		&intent.ID,
+		&intent.ProviderID,
+		&intent.InvoiceID,
+		&intent.ClientID,
+		&intent.Amount,
+		&intent.Refunded,
+		&intent.Unapplied,
+		&intent.Currency,
+		&intent.Status,
+		&intent.CheckoutURL,
+		&intent.CreatedAt,
+		&intent.UpdatedAt,
+	)
+	return intent, err
+}
+
+// PostgresPaymentRepository stores payment intents and processed webhook
+// events in the payment_intents and payment_events tables
+type PostgresPaymentRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresPaymentRepository returns a payment repository backed by the pool
+func NewPostgresPaymentRepository(db *pgxpool.Pool) *PostgresPaymentRepository {
+	return &PostgresPaymentRepository{db: db}
+}
+
+// CreateIntent saves a new payment intent, or returns the saved one if the
+// provider handed back an intent it had already created
+func (r *PostgresPaymentRepository) CreateIntent(intent *PaymentIntent) error {
+	query := `
+		INSERT INTO payment_intents (provider_id, invoice_id, client_id, amount, currency, status, checkout_url)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+		ON CONFLICT (provider_id) DO UPDATE SET provider_id = EXCLUDED.provider_id
+		RETURNING ` + paymentIntentColumns
+
+	secret := intent.ClientSecret
+	saved, err := scanPaymentIntent(r.db.QueryRow(context.Background(), query, intent.ProviderID, intent.InvoiceID,
+		intent.ClientID, intent.Amount, intent.Currency, intent.Status, intent.CheckoutURL))
+	if err != nil {
+		return err
+	}
+	*intent = saved
+	intent.ClientSecret = secret
+	return nil
+}
+
+// ListIntents returns the payment intents of an invoice, newest first
+func (r *PostgresPaymentRepository) ListIntents(invoiceID int) ([]PaymentIntent, error) {
+	query := `SELECT ` + paymentIntentColumns + ` FROM payment_intents WHERE invoice_id = $1 ORDER BY id DESC`
+
+	rows, err := r.db.Query(context.Background(), query, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	intents := []PaymentIntent{}
+	for rows.Next() {
+		intent, err := scanPaymentIntent(rows)
+		if err != nil {
+			return nil, err
+		}
+		intents = append(intents, intent)
+	}
+	return intents, rows.Err()
+}
+
+// ApplyEvent applies a webhook event to its intent and posts the resulting
+// entries, recording the event in the same transaction so a redelivered event
+// is skipped. It reports whether the event was new.
+func (r *PostgresPaymentRepository) ApplyEvent(event PaymentEvent) (bool, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO payment_events (event_id, outcome, provider_id) VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO NOTHING
+	`, event.ID, event.Outcome, event.ProviderID)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	query := `SELECT ` + paymentIntentColumns + ` FROM payment_intents WHERE provider_id = $1 FOR UPDATE`
+	intent, err := scanPaymentIntent(tx.QueryRow(ctx, query, event.ProviderID))
+	if err != nil {
+		return false, err
+	}
+	if err := intent.Check(event); err != nil {
+		return false, err
+	}
+
+	invoice := Invoice{ID: intent.InvoiceID}
+	err = tx.QueryRow(ctx, `SELECT status, total, paid FROM invoices WHERE id = $1 FOR UPDATE`, intent.InvoiceID).
+		Scan(&invoice.Status, &invoice.Total, &invoice.Paid)
+	if err != nil {
+		return false, err
+	}
+
+	changed, entries := intent.Apply(event, &invoice)
+	if changed {
+		_, err := tx.Exec(ctx, `
+			UPDATE payment_intents SET status = $2, refunded = $3, unapplied = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+		`, intent.ID, intent.Status, intent.Refunded, intent.Unapplied)
+		if err != nil {
+			return false, err
+		}
+	}
+	for i := range entries {
+		if err := postEntry(ctx, tx, &entries[i]); err != nil {
+			return false, fmt.Errorf("posting %s for intent %s: %w", entries[i].Kind, intent.ProviderID, err)
+		}
+	}
+
+	return true, tx.Commit(ctx)
+}
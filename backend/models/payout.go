@@ -0,0 +1,524 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Payout statement statuses
+const (
+	PayoutStatusPending = "pending"
+	PayoutStatusPaid    = "paid"
+)
+
+// Journal entry kinds for payouts. A statement's commission moves from the
+// tutor's account to AccountCommission; paying it moves the rest to cash.
+const (
+	EntryCommission = "commission"
+	EntryPayout     = "payout"
+)
+
+// AccountCommission is the platform's commission revenue
+const AccountCommission = "commission"
+
+// DefaultCommissionPercent is the platform's commission on tutor earnings
+// when PAYOUT_COMMISSION_PERCENT is not set
+const DefaultCommissionPercent = 15
+
+// ErrStatementPaid is returned when marking a statement that is already paid
+var ErrStatementPaid = errors.New("the payout statement has already been paid")
+
+// CommissionRate returns the platform's commission in basis points, from the
+// percentage in PAYOUT_COMMISSION_PERCENT, e.g. 12.5 gives 1250
+func CommissionRate() int64 {
+	percent := float64(DefaultCommissionPercent)
+	if value := os.Getenv("PAYOUT_COMMISSION_PERCENT"); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 0 && parsed <= 100 {
+			percent = parsed
+		}
+	}
+	return int64(math.Round(percent * 100))
+}
+
+// Commission returns the commission at a rate in basis points on an amount,
+// rounded to the nearest minor unit, so a reversal gives back exactly what
+// was taken on the amount it reverses
+func Commission(amount, rate int64) int64 {
+	if amount < 0 {
+		return -Commission(-amount, rate)
+	}
+	return (amount*rate + 5000) / 10000
+}
+
+// PayoutPeriod is the span a statement covers, from Start up to End
+type PayoutPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// MonthPeriod returns the calendar month, in UTC, that contains t
+func MonthPeriod(t time.Time) PayoutPeriod {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return PayoutPeriod{Start: start, End: start.AddDate(0, 1, 0)}
+}
+
+// ParseMonthPeriod parses a YYYY-MM month
+func ParseMonthPeriod(value string) (PayoutPeriod, error) {
+	month, err := time.Parse("2006-01", value)
+	if err != nil {
+		return PayoutPeriod{}, fmt.Errorf("period must be a YYYY-MM month")
+	}
+	return MonthPeriod(month), nil
+}
+
+// Label returns the period's month as YYYY-MM
+func (p PayoutPeriod) Label() string {
+	return p.Start.Format("2006-01")
+}
+
+// PayoutStatement is what a tutor earned over a period: every session on an
+// invoice paid before the period ended that no earlier statement covered,
+// less the platform's commission. Sessions paid out on an earlier statement
+// whose invoice has since been refunded are reversed with negative lines,
+// which also reverse their commission at the rate it was taken at.
+type PayoutStatement struct {
+	ID             int          `json:"id"`
+	TutorID        int          `json:"tutor_id"`
+	Period         PayoutPeriod `json:"period"`
+	Currency       string       `json:"currency"`
+	Gross          int64        `json:"gross"`
+	CommissionRate int64        `json:"commission_rate"`
+	Commission     int64        `json:"commission"`
+	Net            int64        `json:"net"`
+	Status         string       `json:"status"`
+	Lines          []PayoutLine `json:"lines"`
+	PaidBy         string       `json:"paid_by,omitempty"`
+	Reference      string       `json:"reference,omitempty"`
+	PaidAt         *time.Time   `json:"paid_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// PayoutLine is one paid session on a statement, at its invoiced amount, or
+// the reversal of one at the negated amount. CommissionRate is the statement's
+// for earnings and that of the statement that paid the session out for
+// reversals.
+type PayoutLine struct {
+	SessionID      int    `json:"session_id"`
+	InvoiceID      int    `json:"invoice_id"`
+	TutorID        int    `json:"-"`
+	Description    string `json:"description"`
+	Minutes        int    `json:"minutes"`
+	Amount         int64  `json:"amount"`
+	CommissionRate int64  `json:"commission_rate"`
+}
+
+// PayoutFilter selects one page of statements, of one tutor if TutorID is
+// set and with Status if given
+type PayoutFilter struct {
+	TutorID int
+	Status  string
+	Cursor  string
+	Limit   int
+}
+
+// PayoutPage is one page of statements, newest first
+type PayoutPage struct {
+	Statements []PayoutStatement `json:"statements"`
+	Total      int               `json:"total"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// owedLine returns line for what is due on its session less what earlier
+// statements paid out for it, and false when they match. Earnings take
+// commission at rate; sessions no longer due after being paid out are
+// reversed at paidOutRate, the rate of the statement that paid them out.
+func owedLine(line PayoutLine, due, paidOut, rate, paidOutRate int64) (PayoutLine, bool) {
+	if due == paidOut {
+		return line, false
+	}
+	line.CommissionRate = rate
+	if due < paidOut {
+		line.Description = "Reversal: " + line.Description
+		line.Minutes = -line.Minutes
+		line.CommissionRate = paidOutRate
+	}
+	line.Amount = due - paidOut
+	return line, true
+}
+
+// BuildStatements groups paid invoice lines into one pending statement per
+// tutor, ordered by tutor ID. Tutors whose lines add up to nothing get none,
+// so reversals carry over until they are covered by new earnings. Commission
+// is taken on the lines at each of their rates, so reversals give back what
+// was taken when they were paid out.
+func BuildStatements(lines []PayoutLine, period PayoutPeriod, rate int64) []PayoutStatement {
+	byTutor := map[int]*PayoutStatement{}
+	byRate := map[int]map[int64]int64{}
+	tutorIDs := []int{}
+	for _, line := range lines {
+		statement, ok := byTutor[line.TutorID]
+		if !ok {
+			statement = &PayoutStatement{
+				TutorID:        line.TutorID,
+				Period:         period,
+				Currency:       Currency,
+				CommissionRate: rate,
+				Status:         PayoutStatusPending,
+				Lines:          []PayoutLine{},
+			}
+			byTutor[line.TutorID] = statement
+			byRate[line.TutorID] = map[int64]int64{}
+			tutorIDs = append(tutorIDs, line.TutorID)
+		}
+		statement.Lines = append(statement.Lines, line)
+		statement.Gross += line.Amount
+		byRate[line.TutorID][line.CommissionRate] += line.Amount
+	}
+
+	sort.Ints(tutorIDs)
+	statements := []PayoutStatement{}
+	for _, id := range tutorIDs {
+		statement := byTutor[id]
+		if statement.Gross <= 0 {
+			continue
+		}
+		for lineRate, amount := range byRate[id] {
+			statement.Commission += Commission(amount, lineRate)
+		}
+		statement.Net = statement.Gross - statement.Commission
+		statements = append(statements, *statement)
+	}
+	return statements
+}
+
+// CommissionEntry returns the entry moving the statement's commission from
+// the tutor's account, or nil if there is none
+func (s *PayoutStatement) CommissionEntry() *JournalEntry {
+	if s.Commission == 0 {
+		return nil
+	}
+	return &JournalEntry{
+		Kind:        EntryCommission,
+		Description: fmt.Sprintf("Commission on payout statement %d", s.ID),
+		Lines: []JournalLine{
+			{Account: TutorAccount(s.TutorID), Amount: s.Commission},
+			{Account: AccountCommission, Amount: -s.Commission},
+		},
+	}
+}
+
+// PayoutEntry returns the entry paying the statement's net amount to the
+// tutor, or nil if there is none
+func (s *PayoutStatement) PayoutEntry() *JournalEntry {
+	if s.Net == 0 {
+		return nil
+	}
+	return &JournalEntry{
+		Kind:        EntryPayout,
+		Description: fmt.Sprintf("Payout statement %d", s.ID),
+		CreatedBy:   s.PaidBy,
+		Lines: []JournalLine{
+			{Account: TutorAccount(s.TutorID), Amount: s.Net},
+			{Account: AccountCash, Amount: -s.Net},
+		},
+	}
+}
+
+// pageLimit returns the requested page size within the allowed bounds
+func (f PayoutFilter) pageLimit() int {
+	if f.Limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(f.Limit, MaxPageSize)
+}
+
+// GeneratePayoutStatements builds the statements of the month before now at
+// the configured commission, recording them with audit
+func GeneratePayoutStatements(payouts PayoutRepository, now time.Time, audit *ChangeAudit) ([]PayoutStatement, error) {
+	return payouts.CreateStatements(MonthPeriod(MonthPeriod(now).Start.AddDate(0, -1, 0)), CommissionRate(), audit)
+}
+
+// payoutColumns is the column list scanned by scanPayoutStatement
+const payoutColumns = `id, tutor_id, period_start, period_end, currency, gross, commission_rate, commission, net, status, COALESCE(paid_by, ''), COALESCE(reference, ''), paid_at, created_at`
+
+// scanPayoutStatement scans a row selected with payoutColumns, without its lines
+func scanPayoutStatement(row pgx.Row) (PayoutStatement, error) {
+	statement := PayoutStatement{Lines: []PayoutLine{}}
+	err := row.Scan(
+		&statement.ID,
+		&statement.TutorID,
+		&statement.Period.Start,
+		&statement.Period.End,
+		&statement.Currency,
+		&statement.Gross,
+		&statement.CommissionRate,
+		&statement.Commission,
+		&statement.Net,
+		&statement.Status,
+		&statement.PaidBy,
+		&statement.Reference,
+		&statement.PaidAt,
+		&statement.CreatedAt,
+	)
+	return statement, err
+}
+
+// PostgresPayoutRepository stores payout statements in the payout_statements
+// and payout_lines tables, posting their entries to the journal
+type PostgresPayoutRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresPayoutRepository returns a payout repository backed by the pool
+func NewPostgresPayoutRepository(db *pgxpool.Pool) *PostgresPayoutRepository {
+	return &PostgresPayoutRepository{db: db}
+}
+
+// loadPayoutLines fills in the lines of the statements
+func loadPayoutLines(ctx context.Context, db querier, statements []PayoutStatement) error {
+	if len(statements) == 0 {
+		return nil
+	}
+	ids := make([]int, len(statements))
+	byID := map[int]*PayoutStatement{}
+	for i := range statements {
+		ids[i] = statements[i].ID
+		byID[statements[i].ID] = &statements[i]
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT statement_id, session_id, invoice_id, description, minutes, amount, commission_rate
+		FROM payout_lines
+		WHERE statement_id = ANY($1)
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var statementID int
+		var line PayoutLine
+		if err := rows.Scan(&statementID, &line.SessionID, &line.InvoiceID, &line.Description, &line.Minutes, &line.Amount, &line.CommissionRate); err != nil {
+			return err
+		}
+		line.TutorID = byID[statementID].TutorID
+		byID[statementID].Lines = append(byID[statementID].Lines, line)
+	}
+	return rows.Err()
+}
+
+// CreateStatements builds and saves a statement per tutor for the period from
+// the lines of invoices paid before it ended that no statement covers yet and
+// the reversals of lines paid out on invoices no longer paid, and posts each
+// statement's commission. Runs are serialized with invoicing.
+func (r *PostgresPayoutRepository) CreateStatements(period PayoutPeriod, rate int64, audit *ChangeAudit) ([]PayoutStatement, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, 0)`, ledgerLock); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.session_id, l.invoice_id, l.tutor_id, l.description, l.minutes,
+		       CASE WHEN i.status = $1 AND i.paid_at < $2 THEN l.amount ELSE 0 END,
+		       COALESCE(p.amount, 0), COALESCE(r.commission_rate, 0)
+		FROM invoice_lines l
+		JOIN invoices i ON i.id = l.invoice_id
+		LEFT JOIN (
+			SELECT session_id, SUM(amount) AS amount FROM payout_lines GROUP BY session_id
+		) p ON p.session_id = l.session_id
+		LEFT JOIN (
+			SELECT DISTINCT ON (session_id) session_id, commission_rate
+			FROM payout_lines
+			WHERE amount > 0
+			ORDER BY session_id, id DESC
+		) r ON r.session_id = l.session_id
+		WHERE (i.status = $1 AND i.paid_at < $2) OR p.amount <> 0
+		ORDER BY l.tutor_id, l.id
+	`, InvoiceStatusPaid, period.End)
+	if err != nil {
+		return nil, err
+	}
+	lines := []PayoutLine{}
+	for rows.Next() {
+		var line PayoutLine
+		var due, paidOut, paidOutRate int64
+		if err := rows.Scan(&line.SessionID, &line.InvoiceID, &line.TutorID, &line.Description, &line.Minutes, &due, &paidOut, &paidOutRate); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if line, ok := owedLine(line, due, paidOut, rate, paidOutRate); ok {
+			lines = append(lines, line)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statements := BuildStatements(lines, period, rate)
+	for i := range statements {
+		statement := &statements[i]
+		err := tx.QueryRow(ctx, `
+			INSERT INTO payout_statements (tutor_id, period_start, period_end, currency, gross, commission_rate, commission, net, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, created_at
+		`, statement.TutorID, statement.Period.Start, statement.Period.End, statement.Currency, statement.Gross,
+			statement.CommissionRate, statement.Commission, statement.Net, statement.Status).
+			Scan(&statement.ID, &statement.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range statement.Lines {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO payout_lines (statement_id, session_id, invoice_id, description, minutes, amount, commission_rate)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`, statement.ID, line.SessionID, line.InvoiceID, line.Description, line.Minutes, line.Amount, line.CommissionRate)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if entry := statement.CommissionEntry(); entry != nil {
+			if err := insertEntry(ctx, tx, entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := audit.save(ctx, tx, statements); err != nil {
+		return nil, err
+	}
+	return statements, tx.Commit(ctx)
+}
+
+// ListStatements returns one page of the statements matching the filter, newest first
+func (r *PostgresPayoutRepository) ListStatements(filter PayoutFilter) (*PayoutPage, error) {
+	ctx := context.Background()
+	limit := filter.pageLimit()
+	where := &conditions{}
+	if filter.TutorID != 0 {
+		where.add("tutor_id = " + where.arg(filter.TutorID))
+	}
+	if filter.Status != "" {
+		where.add("status = " + where.arg(filter.Status))
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM payout_statements `+where.where(), where.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	if filter.Cursor != "" {
+		position, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where.add("id < " + where.arg(position.ID))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM payout_statements
+		%s
+		ORDER BY id DESC
+		LIMIT %s
+	`, payoutColumns, where.where(), where.arg(limit+1))
+
+	rows, err := r.db.Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statements := []PayoutStatement{}
+	for rows.Next() {
+		statement, err := scanPayoutStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &PayoutPage{Statements: statements, Total: total}
+	if len(statements) > limit {
+		page.Statements = statements[:limit]
+		page.NextCursor = encodeCursor("", page.Statements[limit-1].ID)
+	}
+	if err := loadPayoutLines(ctx, r.db, page.Statements); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetStatement returns a statement with its lines
+func (r *PostgresPayoutRepository) GetStatement(id int) (*PayoutStatement, error) {
+	ctx := context.Background()
+	statement, err := scanPayoutStatement(r.db.QueryRow(ctx, `SELECT `+payoutColumns+` FROM payout_statements WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []PayoutStatement{statement}
+	if err := loadPayoutLines(ctx, r.db, statements); err != nil {
+		return nil, err
+	}
+	return &statements[0], nil
+}
+
+// MarkPaid records that a statement's net amount was paid to the tutor and
+// posts the payout
+func (r *PostgresPayoutRepository) MarkPaid(statement *PayoutStatement, audit *ChangeAudit) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE payout_statements
+		SET status = $2, paid_by = NULLIF($3, ''), reference = NULLIF($4, ''), paid_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $5
+		RETURNING paid_at
+	`, statement.ID, PayoutStatusPaid, statement.PaidBy, statement.Reference, PayoutStatusPending).
+		Scan(&statement.PaidAt)
+	if err == pgx.ErrNoRows {
+		return ErrStatementPaid
+	}
+	if err != nil {
+		return err
+	}
+	statement.Status = PayoutStatusPaid
+
+	if entry := statement.PayoutEntry(); entry != nil {
+		if err := insertEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+	if err := audit.save(ctx, tx, statement); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultRetentionDays is how long soft-deleted profiles are kept when
+// SOFT_DELETE_RETENTION_DAYS is not set
+const DefaultRetentionDays = 30
+
+// RetentionPeriod returns how long soft-deleted profiles are kept before they
+// are purged, from SOFT_DELETE_RETENTION_DAYS
+func RetentionPeriod() time.Duration {
+	days := DefaultRetentionDays
+	if value := os.Getenv("SOFT_DELETE_RETENTION_DAYS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			days = parsed
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PurgeResult counts the profiles removed by a purge
+type PurgeResult struct {
+	Tutors  int64 `json:"tutors"`
+	Clients int64 `json:"clients"`
+}
+
+// PurgeDeletedProfiles permanently removes tutors and clients soft-deleted
+// before the cutoff
+func PurgeDeletedProfiles(tutors TutorRepository, clients ClientRepository, cutoff time.Time) (*PurgeResult, error) {
+	result := &PurgeResult{}
+
+	var err error
+	if result.Tutors, err = tutors.Purge(cutoff); err != nil {
+		return nil, err
+	}
+	if result.Clients, err = clients.Purge(cutoff); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// purgeProfiles deletes the rows of a profile table soft-deleted before the
+// cutoff, together with their availability slots and exceptions, in one
+// transaction. Sessions and reviews go with them, so the ratings of tutors
+// reviewed by purged clients are recomputed. Profiles with invoiced sessions
+// are kept, since purging would delete sessions the ledger and payouts refer to.
+func purgeProfiles(db *pgxpool.Pool, table, ownerType string, cutoff time.Time) (int64, error) {
+	ctx := context.Background()
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var ids []int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(array_agg(p.id), '{}') FROM `+table+` p
+		WHERE p.deleted_at < $1 AND NOT EXISTS (
+			SELECT 1 FROM sessions s
+			JOIN invoice_lines l ON l.session_id = s.id
+			WHERE s.`+ownerType+`_id = p.id
+		)
+	`, cutoff).Scan(&ids)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	for _, owned := range []string{"availability_slots", "availability_exceptions"} {
+		_, err = tx.Exec(ctx, `DELETE FROM `+owned+` WHERE owner_type = $1 AND owner_id = ANY($2)`, ownerType, ids)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Reviews by purged clients are deleted with them; reviews of purged
+	// tutors leave no rating behind to fix
+	var reviewed []int
+	if ownerType == OwnerTypeClient {
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(array_agg(DISTINCT tutor_id), '{}') FROM reviews WHERE client_id = ANY($1)
+		`, ids).Scan(&reviewed)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, tutorID := range reviewed {
+		if err := refreshTutorRating(ctx, tx, tutorID); err != nil {
+			return 0, err
+		}
+	}
+
+	return result.RowsAffected(), tx.Commit(ctx)
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Page size limits for list endpoints
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// conditions collects WHERE clauses and their positional arguments
+type conditions struct {
+	clauses []string
+	args    []any
+}
+
+// arg appends a query argument and returns its placeholder, e.g. "$3"
+func (c *conditions) arg(value any) string {
+	c.args = append(c.args, value)
+	return "$" + strconv.Itoa(len(c.args))
+}
+
+// add appends a clause; build it with arg so placeholders line up
+func (c *conditions) add(clause string) {
+	c.clauses = append(c.clauses, clause)
+}
+
+// where renders the clauses as a WHERE statement, or an empty string
+func (c *conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// clone copies the conditions so a query can be extended without affecting another
+func (c *conditions) clone() *conditions {
+	return &conditions{
+		clauses: append([]string(nil), c.clauses...),
+		args:    append([]any(nil), c.args...),
+	}
+}
+
+// searchRow appends full-text rank and snippet columns to a row scan, so
+// results can be scanned with the same helper as plain rows
+type searchRow struct {
+	row     pgx.Row
+	rank    **float32
+	snippet *string
+}
+
+func (r searchRow) Scan(dest ...any) error {
+	return r.row.Scan(append(dest, r.rank, r.snippet)...)
+}
+
+// cursor is the position of the last row of a page for keyset pagination
+type cursor struct {
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func encodeCursor(value string, id int) string {
+	encoded, _ := json.Marshal(cursor{Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeCursor(token string) (*cursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var position cursor
+	if err := json.Unmarshal(decoded, &position); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &position, nil
+}
+
+// lowerAll lower-cases and trims every value, dropping empty and duplicate ones
+func lowerAll(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	lowered := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value != "" && !seen[value] {
+			seen[value] = true
+			lowered = append(lowered, value)
+		}
+	}
+	return lowered
+}
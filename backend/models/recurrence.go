@@ -0,0 +1,320 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxOccurrences bounds how many sessions one recurrence rule can expand to,
+// about two years of weekly sessions
+const MaxOccurrences = 104
+
+// Recurrence frequencies. Sessions recur weekly; imported calendar events
+// may also recur daily.
+const (
+	FrequencyWeekly = "WEEKLY"
+	FrequencyDaily  = "DAILY"
+)
+
+// RecurrenceRule is the subset of an RFC 5545 RRULE that sessions and
+// imported calendars support: weekly or daily recurrence, optionally limited
+// to some weekdays and ending after a count or on a date
+type RecurrenceRule struct {
+	Frequency string
+	Interval  int
+	ByDay     []time.Weekday
+	WeekStart time.Weekday
+	Count     int
+	Until     time.Time
+	// UntilDate is set when UNTIL was a date, which includes the whole day
+	// in the series' timezone
+	UntilDate bool
+}
+
+var rruleDays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// rruleDayName returns the RRULE abbreviation of a weekday, e.g. "TU"
+func rruleDayName(day time.Weekday) string {
+	return strings.ToUpper(day.String()[:2])
+}
+
+// ParseRecurrenceRule parses a session rule such as
+// "FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20261218", with or without an "RRULE:"
+// prefix. Only weekly rules are supported, with COUNT up to MaxOccurrences.
+func ParseRecurrenceRule(raw string) (*RecurrenceRule, error) {
+	return parseRecurrenceRule(raw, false)
+}
+
+// ParseImportedRule parses the rule of an imported calendar event. Unlike
+// session rules, it may recur daily and COUNT is not capped, since
+// ImportedOccurrences is bounded by the import horizon instead.
+func ParseImportedRule(raw string) (*RecurrenceRule, error) {
+	return parseRecurrenceRule(raw, true)
+}
+
+// parseRecurrenceRule parses a session rule, or an imported one if imported
+func parseRecurrenceRule(raw string, imported bool) (*RecurrenceRule, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) >= 6 && strings.EqualFold(raw[:6], "RRULE:") {
+		raw = raw[6:]
+	}
+	if raw == "" {
+		return nil, errors.New("rrule is empty")
+	}
+
+	rule := &RecurrenceRule{Frequency: FrequencyWeekly, Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ";") {
+		name, value, found := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("rrule repeats %s", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			switch {
+			case value == FrequencyWeekly, value == FrequencyDaily && imported:
+				rule.Frequency = value
+			case imported:
+				return nil, fmt.Errorf("unsupported FREQ %s: only WEEKLY and DAILY are supported", value)
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %s: only WEEKLY is supported", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, errors.New("INTERVAL must be a positive number")
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, name := range strings.Split(value, ",") {
+				day, ok := rruleDays[name]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY day %q", name)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "WKST":
+			day, ok := rruleDays[value]
+			if !ok {
+				return nil, fmt.Errorf("invalid WKST day %q", value)
+			}
+			rule.WeekStart = day
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if imported && (err != nil || count < 1) {
+				return nil, errors.New("COUNT must be a positive number")
+			}
+			if !imported && (err != nil || count < 1 || count > MaxOccurrences) {
+				return nil, fmt.Errorf("COUNT must be between 1 and %d", MaxOccurrences)
+			}
+			rule.Count = count
+		case "UNTIL":
+			if until, err := time.Parse("20060102T150405Z", value); err == nil {
+				rule.Until = until
+			} else if until, err := time.Parse("20060102", value); err == nil {
+				rule.Until, rule.UntilDate = until, true
+			} else {
+				return nil, errors.New("UNTIL must be a date (YYYYMMDD) or a UTC time (YYYYMMDDTHHMMSSZ)")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rrule part %s", name)
+		}
+	}
+
+	switch {
+	case !seen["FREQ"]:
+		return nil, errors.New("rrule must set FREQ")
+	case seen["COUNT"] && seen["UNTIL"]:
+		return nil, errors.New("rrule cannot set both COUNT and UNTIL")
+	}
+
+	// Keep the days in week order, without repeats
+	sort.Slice(rule.ByDay, func(i, j int) bool { return rule.dayIndex(rule.ByDay[i]) < rule.dayIndex(rule.ByDay[j]) })
+	days := rule.ByDay[:0]
+	for i, day := range rule.ByDay {
+		if i == 0 || day != rule.ByDay[i-1] {
+			days = append(days, day)
+		}
+	}
+	rule.ByDay = days
+
+	return rule, nil
+}
+
+// Bounded reports whether the rule ends, with COUNT or UNTIL
+func (r *RecurrenceRule) Bounded() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// dayIndex returns a weekday's position in the rule's week
+func (r *RecurrenceRule) dayIndex(day time.Weekday) int {
+	return (int(day) - int(r.WeekStart) + 7) % 7
+}
+
+// String returns the rule in canonical RRULE form, without the "RRULE:" prefix
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Frequency}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, rruleDayName(day))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+rruleDayName(r.WeekStart))
+	}
+	switch {
+	case r.Count > 0:
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	case r.UntilDate:
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	case !r.Until.IsZero():
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences expands the rule from the first occurrence start, keeping its
+// wall-clock time in loc across DST changes. The start must fall on one of
+// the BYDAY days; without BYDAY the rule repeats on the start's weekday.
+// Occurrences from limit on are left out; without a limit, rules expanding
+// to more than MaxOccurrences are rejected.
+func (r *RecurrenceRule) Occurrences(start time.Time, loc *time.Location, limit time.Time) ([]time.Time, error) {
+	local := start.In(loc)
+	days := r.ByDay
+	if len(days) == 0 {
+		days = []time.Weekday{local.Weekday()}
+	}
+
+	onRuleDay := false
+	for _, day := range days {
+		onRuleDay = onRuleDay || day == local.Weekday()
+	}
+	if !onRuleDay {
+		return nil, errors.New("the first session must fall on one of the rule's days")
+	}
+
+	hour, minute, second := local.Clock()
+	weekStart := local.AddDate(0, 0, -r.dayIndex(local.Weekday()))
+
+	occurrences := []time.Time{}
+	for week := 0; ; week += r.Interval {
+		for _, day := range days {
+			occurrence := time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day()+7*week+r.dayIndex(day),
+				hour, minute, second, local.Nanosecond(), loc)
+			if occurrence.Before(start) {
+				continue
+			}
+			if r.pastUntil(occurrence) || (!limit.IsZero() && !occurrence.Before(limit)) {
+				return occurrences, nil
+			}
+			if limit.IsZero() && len(occurrences) == MaxOccurrences {
+				return nil, fmt.Errorf("rrule expands to more than %d sessions", MaxOccurrences)
+			}
+
+			occurrences = append(occurrences, occurrence)
+			if len(occurrences) == r.Count {
+				return occurrences, nil
+			}
+		}
+	}
+}
+
+// ImportedOccurrences expands an imported event's rule from its start up to
+// limit, keeping its wall-clock time in loc across DST changes. As RFC 5545
+// allows, the start need not fall on a BYDAY day: it is always the first
+// occurrence and the rest follow on the rule's days. Daily rules with BYDAY
+// only recur on those days.
+func (r *RecurrenceRule) ImportedOccurrences(start time.Time, loc *time.Location, limit time.Time) []time.Time {
+	occurrences := []time.Time{}
+	if !start.Before(limit) {
+		return occurrences
+	}
+	occurrences = append(occurrences, start)
+	if r.Count == 1 {
+		return occurrences
+	}
+
+	local := start.In(loc)
+	hour, minute, second := local.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, local.Nanosecond(), loc)
+	}
+	days := r.ByDay
+	if len(days) == 0 {
+		days = []time.Weekday{local.Weekday()}
+	}
+	weekStart := local.AddDate(0, 0, -r.dayIndex(local.Weekday()))
+
+	// Each period is a day of a daily rule or a week of a weekly one
+	for period := 0; ; period += r.Interval {
+		var candidates []time.Time
+		if r.Frequency == FrequencyDaily {
+			candidates = append(candidates, at(local.Year(), local.Month(), local.Day()+period))
+		} else {
+			for _, day := range days {
+				candidates = append(candidates, at(weekStart.Year(), weekStart.Month(), weekStart.Day()+7*period+r.dayIndex(day)))
+			}
+		}
+
+		for _, occurrence := range candidates {
+			if r.pastUntil(occurrence) || !occurrence.Before(limit) {
+				return occurrences
+			}
+			if !occurrence.After(start) || !r.onDay(occurrence.Weekday()) {
+				continue
+			}
+			occurrences = append(occurrences, occurrence)
+			if len(occurrences) == r.Count {
+				return occurrences
+			}
+		}
+	}
+}
+
+// onDay reports whether a rule without BYDAY, or one listing the day,
+// recurs on a weekday
+func (r *RecurrenceRule) onDay(day time.Weekday) bool {
+	for _, ruleDay := range r.ByDay {
+		if ruleDay == day {
+			return true
+		}
+	}
+	return len(r.ByDay) == 0
+}
+
+// pastUntil reports whether an occurrence falls after the rule's UNTIL
+func (r *RecurrenceRule) pastUntil(occurrence time.Time) bool {
+	switch {
+	case r.Count > 0 || r.Until.IsZero():
+		return false
+	case r.UntilDate:
+		day := time.Date(occurrence.Year(), occurrence.Month(), occurrence.Day(), 0, 0, 0, 0, time.UTC)
+		return day.After(r.Until)
+	default:
+		return occurrence.After(r.Until)
+	}
+}
@@ -0,0 +1,211 @@
+package models
+
+import "time"
+
+// TutorRepository stores tutor profiles. Lookups of missing or soft-deleted
+// tutors return pgx.ErrNoRows, as do updates and deletes of them. Here and
+// in the other repositories, a change given a *ChangeAudit is saved together
+// with its audit entries or not at all.
+type TutorRepository interface {
+	// List returns every tutor that is not deleted, newest first
+	List() ([]Tutor, error)
+	// Search returns one page of tutors matching the filter
+	Search(filter TutorFilter) (*TutorPage, error)
+	// Facets counts tutors per facet value under the filter
+	Facets(filter TutorFilter) (*TutorFacets, error)
+	// AvailableAt returns the tutors whose weekly availability covers the
+	// instant and who have no availability exception then
+	AvailableAt(at time.Time) ([]Tutor, error)
+	GetByID(id int) (*Tutor, error)
+	GetByEmail(email string) (*Tutor, error)
+	// Create saves a new tutor, setting its ID and timestamps. New tutors
+	// start unrated.
+	Create(tutor *Tutor) error
+	// Update replaces a tutor's fields, keeping its limit when MaxClients is
+	// zero. The rating is derived from reviews and is never written.
+	Update(tutor *Tutor, audit *ChangeAudit) error
+	// Delete soft-deletes a tutor and ends their open pairings
+	Delete(id int, audit *ChangeAudit) error
+	// ListDeleted returns soft-deleted tutors, most recently deleted first
+	ListDeleted() ([]Tutor, error)
+	// Restore undoes a soft delete and returns the restored tutor
+	Restore(id int, audit *ChangeAudit) (*Tutor, error)
+	// Purge permanently removes tutors soft-deleted before the cutoff,
+	// keeping those with invoiced sessions
+	Purge(cutoff time.Time) (int64, error)
+	// AvailabilityExceptions returns the exceptions of the tutors that
+	// overlap the range, earliest first, by tutor ID
+	AvailabilityExceptions(tutorIDs []int, from, to time.Time) (map[int][]AvailabilityException, error)
+	// ReplaceAvailabilityExceptions replaces a tutor's exceptions from a
+	// source with the given busy spans
+	ReplaceAvailabilityExceptions(tutorID int, source string, blocks []Interval) ([]AvailabilityException, error)
+}
+
+// ClientRepository stores client profiles. Lookups of missing or soft-deleted
+// clients return pgx.ErrNoRows, as do updates and deletes of them.
+type ClientRepository interface {
+	// List returns every client that is not deleted, newest first
+	List() ([]Client, error)
+	// Search full-text searches client descriptions, most relevant first
+	Search(query string, limit int) ([]ClientResult, error)
+	// AvailableAt returns the clients whose weekly availability covers the instant
+	AvailableAt(at time.Time) ([]Client, error)
+	GetByID(id int) (*Client, error)
+	GetByEmail(email string) (*Client, error)
+	// Create saves a new client, setting its ID and timestamps
+	Create(client *Client) error
+	// Update replaces a client's fields, keeping its active flag when Active is nil
+	Update(client *Client, audit *ChangeAudit) error
+	// Delete soft-deletes a client and ends their open pairing
+	Delete(id int, audit *ChangeAudit) error
+	// ListDeleted returns soft-deleted clients, most recently deleted first
+	ListDeleted() ([]Client, error)
+	// Restore undoes a soft delete and returns the restored client
+	Restore(id int, audit *ChangeAudit) (*Client, error)
+	// Purge permanently removes clients soft-deleted before the cutoff,
+	// keeping those with invoiced sessions, and recomputes the ratings of
+	// the tutors they reviewed
+	Purge(cutoff time.Time) (int64, error)
+}
+
+// SessionRepository stores sessions. Missing sessions return pgx.ErrNoRows.
+// Writes that leave a session confirmed fail with ErrSessionConflict if it
+// would overlap another confirmed session of its tutor or client.
+type SessionRepository interface {
+	// List returns the sessions matching the filter, earliest first
+	List(filter SessionFilter) ([]Session, error)
+	GetByID(id int) (*Session, error)
+	// Conflicts returns confirmed sessions of the tutor or client overlapping
+	// the span, other than the session with excludeID
+	Conflicts(tutorID, clientID int, start, end time.Time, excludeID int) ([]Session, error)
+	// Create saves a new session, setting its ID and timestamps
+	Create(session *Session) error
+	// Update saves a session's subject, times, status and cancellation
+	Update(session *Session) error
+	// UpdateMany saves several sessions; either all of them are saved or none
+	UpdateMany(sessions []Session) error
+	GetSeries(id int) (*SessionSeries, error)
+	// CreateSeries saves a new series and its occurrences, setting their IDs
+	// and timestamps; either all of them are saved or none
+	CreateSeries(series *SessionSeries, occurrences []Session) error
+}
+
+// CalendarFeedRepository stores calendar feeds, one per user email. Missing
+// feeds return pgx.ErrNoRows.
+type CalendarFeedRepository interface {
+	GetByEmail(email string) (*CalendarFeed, error)
+	GetByTokenHash(tokenHash string) (*CalendarFeed, error)
+	// Save creates the user's feed or replaces its token, setting CreatedAt
+	Save(feed *CalendarFeed) error
+	Delete(email string) error
+}
+
+// ReviewRepository stores reviews, at most one per session. Creating or
+// moderating a review recomputes its tutor's rating from their published
+// reviews. Missing reviews return pgx.ErrNoRows.
+type ReviewRepository interface {
+	// List returns one page of the reviews matching the filter, newest first
+	List(filter ReviewFilter) (*ReviewPage, error)
+	GetByID(id int) (*Review, error)
+	GetBySession(sessionID int) (*Review, error)
+	// Create saves a new review, setting its ID and creation time, or fails
+	// with ErrAlreadyReviewed if the session has one
+	Create(review *Review) error
+	// Moderate saves a review's status, comment, redaction and moderator,
+	// setting its moderation time
+	Moderate(review *Review, audit *ChangeAudit) error
+}
+
+// LedgerRepository stores the double-entry ledger: invoices for completed
+// sessions and the balanced journal entries posted for invoices, payments,
+// credits and refunds. Missing invoices return pgx.ErrNoRows.
+type LedgerRepository interface {
+	// Post saves a balanced journal entry, setting its ID and creation time.
+	// A payment or refund with an InvoiceID is applied to that invoice of
+	// the client; it returns ErrOverpayment if a payment is more than is due
+	// and ErrRefundExceedsPaid if a refund is more than was paid.
+	Post(entry *JournalEntry, audit *ChangeAudit) error
+	// AccountTotals returns the sum of an account's lines by entry kind
+	AccountTotals(account string) (map[string]int64, error)
+	// InvoiceSessions invoices the completed sessions not yet on an invoice
+	// and returns the new invoices
+	InvoiceSessions(sessions []Session, audit *ChangeAudit) ([]Invoice, error)
+	// ListInvoices returns one page of the invoices matching the filter, newest first
+	ListInvoices(filter InvoiceFilter) (*InvoicePage, error)
+	GetInvoice(id int) (*Invoice, error)
+}
+
+// PaymentRepository stores checkouts started with the payment provider and
+// the webhook events that settle them
+type PaymentRepository interface {
+	// CreateIntent saves a new payment intent, setting its ID and times
+	CreateIntent(intent *PaymentIntent) error
+	// ListIntents returns the payment intents of an invoice, newest first
+	ListIntents(invoiceID int) ([]PaymentIntent, error)
+	// ApplyEvent applies a webhook event to its intent, posting any payment
+	// or refund to the ledger. Each event is applied once; it returns false
+	// for one applied before, pgx.ErrNoRows if the intent is unknown and
+	// ErrEventMismatch if the event's amount or currency differ from it.
+	ApplyEvent(event PaymentEvent) (bool, error)
+}
+
+// PayoutRepository stores tutor payout statements and posts their commission
+// and payouts to the ledger. Missing statements return pgx.ErrNoRows.
+type PayoutRepository interface {
+	// CreateStatements builds a statement per tutor for the period from the
+	// sessions on invoices paid before it ended that no statement covers
+	// yet, taking commission at rate basis points, and returns them
+	CreateStatements(period PayoutPeriod, rate int64, audit *ChangeAudit) ([]PayoutStatement, error)
+	// ListStatements returns one page of the statements matching the filter, newest first
+	ListStatements(filter PayoutFilter) (*PayoutPage, error)
+	GetStatement(id int) (*PayoutStatement, error)
+	// MarkPaid saves a pending statement as paid by PaidBy with Reference,
+	// setting its payment time, or fails with ErrStatementPaid
+	MarkPaid(statement *PayoutStatement, audit *ChangeAudit) error
+}
+
+// RoleRepository stores the roles granted to users and the permissions they
+// carry. Granting or revoking an unknown role fails with ErrUnknownRole.
+type RoleRepository interface {
+	// Roles returns every role with its permissions
+	Roles() ([]Role, error)
+	// Users returns every user holding at least one role, ordered by email
+	Users() ([]User, error)
+	// Access returns the roles and permissions granted to an email
+	Access(email string) (*UserAccess, error)
+	// Grant gives a role to the user with the email, creating the user if
+	// needed; granting a role already held is a no-op
+	Grant(email, role, grantedBy string, audit *ChangeAudit) error
+	// Revoke removes a role from the user with the email, returning
+	// pgx.ErrNoRows if they do not hold it and ErrLastAdmin if it would
+	// leave no admin
+	Revoke(email, role string, audit *ChangeAudit) error
+}
+
+// PairingRepository stores pairings of clients with tutors
+type PairingRepository interface {
+	// ListActive returns the pairings that have not ended, skipping those
+	// whose tutor or client is soft-deleted, newest first
+	ListActive() ([]Pairing, error)
+	// Create saves a batch of pairings, setting their IDs, status and
+	// creation times. Either all are saved or, if a client is inactive or
+	// already paired or a tutor has no free seat, none are and a
+	// *PairingConflictError is returned.
+	Create(pairings []Pairing, audit *ChangeAudit) error
+}
+
+// AuditRepository stores the audit log of mutating admin actions
+type AuditRepository interface {
+	// Record saves an entry, setting its ID and creation time
+	Record(entry *AuditEntry) error
+	// List returns one page of the entries matching the filter, newest first
+	List(filter AuditFilter) (*AuditPage, error)
+}
+
+// SubjectRepository stores the subject taxonomy
+type SubjectRepository interface {
+	// Seed inserts the built-in taxonomy, leaving existing subjects and
+	// aliases untouched
+	Seed() error
+	Taxonomy() (*SubjectTaxonomy, error)
+}
@@ -0,0 +1,410 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Review moderation states. Only published reviews are shown and count
+// toward a tutor's rating.
+const (
+	ReviewStatusPending   = "pending"
+	ReviewStatusPublished = "published"
+	ReviewStatusRejected  = "rejected"
+)
+
+// DefaultBlockedWords hold reviews for moderation when REVIEW_BLOCKED_WORDS
+// is not set
+var DefaultBlockedWords = []string{"fuck", "fucking", "shit", "bitch", "bastard", "asshole", "cunt", "dick", "retard", "idiot", "scam"}
+
+// Bounds on review content
+const (
+	MinReviewRating  = 1
+	MaxReviewRating  = 5
+	MaxReviewComment = 2000
+)
+
+// The rating prior: every tutor's average is computed as if they also had
+// RatingPriorWeight reviews of RatingPriorMean stars, so a single review
+// moves it only part of the way
+const (
+	RatingPriorMean   = 4.0
+	RatingPriorWeight = 5
+)
+
+// ErrAlreadyReviewed is returned when a session already has a review
+var ErrAlreadyReviewed = errors.New("the session has already been reviewed")
+
+// Review is a client's rating of a completed session with a tutor. Reviews
+// whose comment trips the keyword filter are held as pending with the
+// reason, until a moderator publishes or rejects them.
+type Review struct {
+	ID          int        `json:"id"`
+	SessionID   int        `json:"session_id"`
+	TutorID     int        `json:"tutor_id"`
+	ClientID    int        `json:"client_id"`
+	Rating      int        `json:"rating"`
+	Comment     string     `json:"comment"`
+	Status      string     `json:"status"`
+	HoldReason  string     `json:"hold_reason,omitempty"`
+	Redacted    bool       `json:"redacted"`
+	ModeratedBy string     `json:"moderated_by,omitempty"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ReviewFilter selects one page of reviews, of one tutor if TutorID is set
+// and with one of Statuses if any are given
+type ReviewFilter struct {
+	TutorID  int
+	Statuses []string
+	Cursor   string
+	Limit    int
+}
+
+// ReviewPage is one page of reviews, newest first
+type ReviewPage struct {
+	Reviews    []Review `json:"reviews"`
+	Total      int      `json:"total"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// BayesianRating returns the rating of a tutor with count reviews whose
+// ratings add up to total, rounded to two decimals. Tutors without reviews
+// are unrated and get 0.
+func BayesianRating(count, total int) float64 {
+	if count == 0 {
+		return 0
+	}
+	rating := (RatingPriorMean*RatingPriorWeight + float64(total)) / float64(RatingPriorWeight+count)
+	return math.Round(rating*100) / 100
+}
+
+// ValidateReview checks a review's rating and comment length
+func ValidateReview(review *Review) error {
+	if review.Rating < MinReviewRating || review.Rating > MaxReviewRating {
+		return fmt.Errorf("rating must be between %d and %d", MinReviewRating, MaxReviewRating)
+	}
+	if len([]rune(review.Comment)) > MaxReviewComment {
+		return fmt.Errorf("comment must be at most %d characters", MaxReviewComment)
+	}
+	return nil
+}
+
+// BlockedWords returns the words and phrases that hold a review for
+// moderation, from the comma-separated REVIEW_BLOCKED_WORDS
+func BlockedWords() []string {
+	value, ok := os.LookupEnv("REVIEW_BLOCKED_WORDS")
+	if !ok {
+		return DefaultBlockedWords
+	}
+
+	words := []string{}
+	for _, word := range strings.Split(value, ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// HeldWords returns the blocked words and phrases that appear in the text as
+// whole words, ignoring case and punctuation
+func HeldWords(text string, blocked []string) []string {
+	normalized := " " + normalizeWords(text) + " "
+
+	held := []string{}
+	for _, word := range blocked {
+		if phrase := normalizeWords(word); phrase != "" && strings.Contains(normalized, " "+phrase+" ") {
+			held = append(held, word)
+		}
+	}
+	return held
+}
+
+// normalizeWords lower-cases text and reduces it to words separated by single spaces
+func normalizeWords(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Screen sets a new review's moderation state: pending if its comment
+// contains a blocked word, published otherwise
+func (review *Review) Screen(blocked []string) {
+	review.Status, review.HoldReason = ReviewStatusPublished, ""
+	if held := HeldWords(review.Comment, blocked); len(held) > 0 {
+		review.Status = ReviewStatusPending
+		review.HoldReason = "contains " + strings.Join(held, ", ")
+	}
+}
+
+// PublicReview is a review as shown on a tutor's profile, without who wrote
+// it or how it was moderated
+type PublicReview struct {
+	ID        int       `json:"id"`
+	SessionID int       `json:"session_id"`
+	TutorID   int       `json:"tutor_id"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment"`
+	Status    string    `json:"status"`
+	Redacted  bool      `json:"redacted"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Public returns the review without its author or moderation details, for
+// showing outside the moderation queue
+func (review Review) Public() PublicReview {
+	return PublicReview{
+		ID:        review.ID,
+		SessionID: review.SessionID,
+		TutorID:   review.TutorID,
+		Rating:    review.Rating,
+		Comment:   review.Comment,
+		Status:    review.Status,
+		Redacted:  review.Redacted,
+		CreatedAt: review.CreatedAt,
+	}
+}
+
+// ValidReviewStatus reports whether a status is a moderation state
+func ValidReviewStatus(status string) bool {
+	switch status {
+	case ReviewStatusPending, ReviewStatusPublished, ReviewStatusRejected:
+		return true
+	}
+	return false
+}
+
+// pageLimit returns the requested page size within the allowed bounds
+func (f ReviewFilter) pageLimit() int {
+	if f.Limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(f.Limit, MaxPageSize)
+}
+
+// reviewCursor encodes the position of a review in newest-first order
+func reviewCursor(review Review) string {
+	return encodeCursor(review.CreatedAt.UTC().Format(time.RFC3339Nano), review.ID)
+}
+
+// decodeReviewCursor returns the creation time and ID a review cursor points at
+func decodeReviewCursor(token string) (time.Time, int, error) {
+	position, err := decodeCursor(token)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, position.Value)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+	return createdAt, position.ID, nil
+}
+
+// reviewColumns is the column list scanned by scanReview
+const reviewColumns = `id, session_id, tutor_id, client_id, rating, comment, status, hold_reason, redacted, COALESCE(moderated_by, ''), moderated_at, created_at`
+
+// scanReview scans a row selected with reviewColumns
+func scanReview(row pgx.Row) (Review, error) {
+	var review Review
+	err := row.Scan(
+		&review.ID,
+		&review.SessionID,
+		&review.TutorID,
+		&review.ClientID,
+		&review.Rating,
+		&review.Comment,
+		&review.Status,
+		&review.HoldReason,
+		&review.Redacted,
+		&review.ModeratedBy,
+		&review.ModeratedAt,
+		&review.CreatedAt,
+	)
+	return review, err
+}
+
+// PostgresReviewRepository stores reviews in the reviews table
+type PostgresReviewRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresReviewRepository returns a review repository backed by the pool
+func NewPostgresReviewRepository(db *pgxpool.Pool) *PostgresReviewRepository {
+	return &PostgresReviewRepository{db: db}
+}
+
+// List returns one page of the reviews matching the filter, newest first
+func (r *PostgresReviewRepository) List(filter ReviewFilter) (*ReviewPage, error) {
+	limit := filter.pageLimit()
+	where := &conditions{}
+	if filter.TutorID != 0 {
+		where.add("tutor_id = " + where.arg(filter.TutorID))
+	}
+	if len(filter.Statuses) > 0 {
+		where.add("status = ANY(" + where.arg(filter.Statuses) + ")")
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM reviews ` + where.where()
+	if err := r.db.QueryRow(context.Background(), countQuery, where.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeReviewCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where.add(fmt.Sprintf("(created_at, id) < (%s, %s)", where.arg(createdAt), where.arg(id)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM reviews
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s
+	`, reviewColumns, where.where(), where.arg(limit+1))
+
+	rows, err := r.db.Query(context.Background(), query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &ReviewPage{Reviews: reviews, Total: total}
+	if len(reviews) > limit {
+		page.Reviews = reviews[:limit]
+		page.NextCursor = reviewCursor(page.Reviews[limit-1])
+	}
+	return page, nil
+}
+
+// GetByID returns a review
+func (r *PostgresReviewRepository) GetByID(id int) (*Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE id = $1`
+
+	review, err := scanReview(r.db.QueryRow(context.Background(), query, id))
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetBySession returns the review of a session
+func (r *PostgresReviewRepository) GetBySession(sessionID int) (*Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE session_id = $1`
+
+	review, err := scanReview(r.db.QueryRow(context.Background(), query, sessionID))
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Create saves a new review and recomputes its tutor's rating in the same
+// transaction
+func (r *PostgresReviewRepository) Create(review *Review) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO reviews (session_id, tutor_id, client_id, rating, comment, status, hold_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (session_id) DO NOTHING
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, query, review.SessionID, review.TutorID, review.ClientID, review.Rating, review.Comment,
+		review.Status, review.HoldReason).Scan(&review.ID, &review.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return ErrAlreadyReviewed
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := refreshTutorRating(ctx, tx, review.TutorID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Moderate saves a review's status, comment and moderator, and recomputes
+// its tutor's rating in the same transaction
+func (r *PostgresReviewRepository) Moderate(review *Review, audit *ChangeAudit) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE reviews
+		SET status = $2, comment = $3, redacted = $4, moderated_by = NULLIF($5, ''), moderated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING moderated_at
+	`
+	err = tx.QueryRow(ctx, query, review.ID, review.Status, review.Comment, review.Redacted, review.ModeratedBy).
+		Scan(&review.ModeratedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := refreshTutorRating(ctx, tx, review.TutorID); err != nil {
+		return err
+	}
+	if err := audit.save(ctx, tx, review); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// refreshTutorRating recomputes a tutor's rating from their published reviews. The
+// tutor row is locked first so concurrent reviews cannot both write a stale
+// aggregate.
+func refreshTutorRating(ctx context.Context, tx pgx.Tx, tutorID int) error {
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM tutors WHERE id = $1 FOR UPDATE`, tutorID); err != nil {
+		return err
+	}
+
+	var count, total int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(rating), 0) FROM reviews WHERE tutor_id = $1 AND status = $2
+	`, tutorID, ReviewStatusPublished).
+		Scan(&count, &total)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE tutors SET rating = $2, review_count = $3 WHERE id = $1`,
+		tutorID, BayesianRating(count, total), count)
+	return err
+}
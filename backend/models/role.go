@@ -0,0 +1,264 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Built-in roles
+const (
+	RoleAdmin       = "admin"
+	RoleCoordinator = "coordinator"
+	RoleTutor       = "tutor"
+	RoleClient      = "client"
+)
+
+// Permissions checked by middleware.RequirePermission
+const (
+	PermissionStatsRead        = "stats:read"
+	PermissionTutorsWrite      = "tutors:write"
+	PermissionClientsWrite     = "clients:write"
+	PermissionAssignmentsWrite = "assignments:write"
+	PermissionRolesWrite       = "roles:write"
+	PermissionAuditRead        = "audit:read"
+	PermissionReviewsWrite     = "reviews:write"
+	PermissionBillingWrite     = "billing:write"
+)
+
+// ErrUnknownRole is returned when granting or revoking a role that does not exist
+var ErrUnknownRole = errors.New("unknown role")
+
+// ErrLastAdmin is returned when revoking the admin role from the only admin
+var ErrLastAdmin = errors.New("cannot revoke the last admin")
+
+// Role is a named set of permissions
+type Role struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// User is an account that roles are granted to, identified by email
+type User struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserAccess is the roles and effective permissions of a user
+type UserAccess struct {
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// BootstrapAdmins grants the admin role to the given emails, creating their
+// users as needed. It seeds the first admins from ADMIN_EMAILS; everyone
+// else is managed through the roles API.
+func BootstrapAdmins(roles RoleRepository, emails []string) error {
+	for _, email := range lowerAll(emails) {
+		if err := roles.Grant(email, RoleAdmin, "bootstrap", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasPermission reports whether the access includes a permission
+func (a *UserAccess) HasPermission(permission string) bool {
+	for _, granted := range a.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PostgresRoleRepository stores roles, permissions and the users they are
+// granted to in the roles, permissions, users and user_roles tables
+type PostgresRoleRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresRoleRepository returns a role repository backed by the pool
+func NewPostgresRoleRepository(db *pgxpool.Pool) *PostgresRoleRepository {
+	return &PostgresRoleRepository{db: db}
+}
+
+// Roles returns every role with its permissions
+func (r *PostgresRoleRepository) Roles() ([]Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description,
+			COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		GROUP BY r.id
+		ORDER BY r.id
+	`
+
+	rows, err := r.db.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.Permissions); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// Users returns every user holding at least one role
+func (r *PostgresRoleRepository) Users() ([]User, error) {
+	query := `
+		SELECT u.id, u.email, array_agg(r.name ORDER BY r.name), u.created_at
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id
+		JOIN roles r ON r.id = ur.role_id
+		GROUP BY u.id
+		ORDER BY u.email
+	`
+
+	rows, err := r.db.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Email, &user.Roles, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// Access returns the roles and permissions granted to an email
+func (r *PostgresRoleRepository) Access(email string) (*UserAccess, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	access := &UserAccess{Email: email, Roles: []string{}, Permissions: []string{}}
+
+	query := `
+		SELECT
+			COALESCE(array_agg(DISTINCT r.name) FILTER (WHERE r.name IS NOT NULL), '{}'),
+			COALESCE(array_agg(DISTINCT p.name) FILTER (WHERE p.name IS NOT NULL), '{}')
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id
+		JOIN roles r ON r.id = ur.role_id
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		WHERE u.email = $1
+	`
+
+	if err := r.db.QueryRow(context.Background(), query, email).Scan(&access.Roles, &access.Permissions); err != nil {
+		return nil, err
+	}
+	return access, nil
+}
+
+// Grant gives a role to the user with the email, creating the user if
+// needed. Granting a role the user already holds is a no-op.
+func (r *PostgresRoleRepository) Grant(email, role, grantedBy string, audit *ChangeAudit) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var roleID int
+	err = tx.QueryRow(ctx, `SELECT id FROM roles WHERE name = $1`, role).Scan(&roleID)
+	if err == pgx.ErrNoRows {
+		return ErrUnknownRole
+	}
+	if err != nil {
+		return err
+	}
+
+	var userID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (email) VALUES (lower($1))
+		ON CONFLICT (email) DO UPDATE SET email = EXCLUDED.email
+		RETURNING id
+	`, strings.TrimSpace(email)).Scan(&userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_roles (user_id, role_id, granted_by) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, role_id) DO NOTHING
+	`, userID, roleID, grantedBy)
+	if err != nil {
+		return err
+	}
+	if err := audit.save(ctx, tx, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Revoke removes a role from the user with the email. The admin role
+// cannot be revoked from the last remaining admin.
+func (r *PostgresRoleRepository) Revoke(email, role string, audit *ChangeAudit) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var roleID int
+	err = tx.QueryRow(ctx, `SELECT id FROM roles WHERE name = $1 FOR UPDATE`, role).Scan(&roleID)
+	if err == pgx.ErrNoRows {
+		return ErrUnknownRole
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		DELETE FROM user_roles
+		WHERE role_id = $1 AND user_id = (SELECT id FROM users WHERE email = lower($2))
+	`, roleID, strings.TrimSpace(email))
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if role == RoleAdmin {
+		var remaining int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM user_roles WHERE role_id = $1`, roleID).Scan(&remaining); err != nil {
+			return err
+		}
+		if remaining == 0 {
+			return ErrLastAdmin
+		}
+	}
+	if err := audit.save(ctx, tx, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,407 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Session statuses
+const (
+	SessionStatusRequested = "requested"
+	SessionStatusConfirmed = "confirmed"
+	SessionStatusCancelled = "cancelled"
+)
+
+// Bounds on the length of a bookable session
+const (
+	MinSessionLength = 15 * time.Minute
+	MaxSessionLength = 4 * time.Hour
+)
+
+// ErrSessionConflict is returned when a session would overlap a confirmed
+// session of the same tutor or client
+var ErrSessionConflict = errors.New("the time overlaps a confirmed session")
+
+// ErrOutsideAvailability is returned when a session falls outside the
+// tutor's weekly availability
+var ErrOutsideAvailability = errors.New("the time is outside the tutor's availability")
+
+// Session is a tutoring session a client books with a tutor. Clients request
+// sessions and tutors confirm them; only confirmed sessions hold the time.
+// Occurrences of a recurring series carry its ID and the start the rule gave
+// them, which stays put when the occurrence is moved. HourlyRate is the
+// tutor's rate when the session was booked, in minor currency units; the
+// session is invoiced at that rate.
+type Session struct {
+	ID              int        `json:"id"`
+	TutorID         int        `json:"tutor_id"`
+	ClientID        int        `json:"client_id"`
+	Subject         string     `json:"subject"`
+	StartsAt        time.Time  `json:"starts_at"`
+	EndsAt          time.Time  `json:"ends_at"`
+	Status          string     `json:"status"`
+	CancelledBy     string     `json:"cancelled_by,omitempty"`
+	CancelReason    string     `json:"cancel_reason,omitempty"`
+	HourlyRate      int64      `json:"hourly_rate"`
+	SeriesID        *int       `json:"series_id,omitempty"`
+	OccurrenceStart *time.Time `json:"occurrence_start,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// SessionFilter narrows a session listing. A session matches when it belongs
+// to TutorID or to ClientID (zero IDs are ignored), is part of SeriesID if
+// set, has one of Statuses if any are given, overlaps the From-To range and,
+// if Uninvoiced is set, is on no invoice.
+type SessionFilter struct {
+	TutorID    int
+	ClientID   int
+	SeriesID   int
+	Statuses   []string
+	From       *time.Time
+	To         *time.Time
+	Uninvoiced bool
+}
+
+// Overlaps reports whether the session overlaps the span from start to end
+func (s *Session) Overlaps(start, end time.Time) bool {
+	return s.StartsAt.Before(end) && s.EndsAt.After(start)
+}
+
+// Completed reports whether the session took place: it was confirmed and has
+// ended by now
+func (s *Session) Completed(now time.Time) bool {
+	return s.Status == SessionStatusConfirmed && !s.EndsAt.After(now)
+}
+
+// ValidateSessionTime checks that a proposed session starts after now, ends
+// after it starts and lasts between MinSessionLength and MaxSessionLength
+func ValidateSessionTime(start, end, now time.Time) error {
+	switch length := end.Sub(start); {
+	case start.IsZero() || end.IsZero():
+		return errors.New("starts_at and ends_at are required")
+	case !start.After(now):
+		return errors.New("sessions must start in the future")
+	case length <= 0:
+		return errors.New("ends_at must be after starts_at")
+	case length < MinSessionLength:
+		return fmt.Errorf("sessions must last at least %s", MinSessionLength)
+	case length > MaxSessionLength:
+		return fmt.Errorf("sessions can last at most %s", MaxSessionLength)
+	}
+	return nil
+}
+
+// CheckTutorAvailability returns ErrOutsideAvailability unless the tutor's
+// weekly availability covers the whole span
+func CheckTutorAvailability(tutor *Tutor, start, end time.Time) error {
+	availability, err := ParseAvailability(tutor.Availability, tutor.Timezone)
+	if err != nil {
+		return err
+	}
+	if !availability.Covers(start, end) {
+		return ErrOutsideAvailability
+	}
+	return nil
+}
+
+// Teaches reports whether the tutor covers a subject, directly or through a
+// broader subject in the taxonomy, e.g. a Mathematics tutor teaches Calculus
+func (t *Tutor) Teaches(taxonomy *SubjectTaxonomy, subject string) bool {
+	wanted := strings.ToLower(taxonomy.Canonical(subject))
+	for _, taught := range t.Subjects {
+		for _, name := range taxonomy.Expand(taught) {
+			if name == wanted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sessionColumns is the column list scanned by scanSession
+const sessionColumns = `id, tutor_id, client_id, subject, starts_at, ends_at, status, COALESCE(cancelled_by, ''), COALESCE(cancel_reason, ''), hourly_rate, series_id, occurrence_start, created_at, updated_at`
+
+// scanSession scans a row selected with sessionColumns
+func scanSession(row pgx.Row) (Session, error) {
+	var session Session
+	err := row.Scan(
+		&session.ID,
+		&session.TutorID,
+		&session.ClientID,
+		&session.Subject,
+		&session.StartsAt,
+		&session.EndsAt,
+		&session.Status,
+		&session.CancelledBy,
+		&session.CancelReason,
+		&session.HourlyRate,
+		&session.SeriesID,
+		&session.OccurrenceStart,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	return session, err
+}
+
+// Advisory lock namespaces serializing writes of confirmed sessions per participant
+const (
+	sessionTutorLock  = 1
+	sessionClientLock = 2
+)
+
+// querier is satisfied by both a pool and a transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// queryRower is satisfied by both a pool and a transaction
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// beginner is satisfied by a pool, and by a transaction, where Begin starts
+// a savepoint
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// PostgresSessionRepository stores sessions in the sessions table
+type PostgresSessionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresSessionRepository returns a session repository backed by the pool
+func NewPostgresSessionRepository(db *pgxpool.Pool) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+// listSessions runs a query selecting sessionColumns and scans every row
+func listSessions(ctx context.Context, db querier, query string, args ...any) ([]Session, error) {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// List returns the sessions matching the filter, earliest first
+func (r *PostgresSessionRepository) List(filter SessionFilter) ([]Session, error) {
+	where := &conditions{}
+
+	var participants []string
+	if filter.TutorID != 0 {
+		participants = append(participants, "tutor_id = "+where.arg(filter.TutorID))
+	}
+	if filter.ClientID != 0 {
+		participants = append(participants, "client_id = "+where.arg(filter.ClientID))
+	}
+	if len(participants) > 0 {
+		where.add("(" + strings.Join(participants, " OR ") + ")")
+	}
+	if filter.SeriesID != 0 {
+		where.add("series_id = " + where.arg(filter.SeriesID))
+	}
+	if len(filter.Statuses) > 0 {
+		where.add("status = ANY(" + where.arg(filter.Statuses) + ")")
+	}
+	if filter.From != nil {
+		where.add("ends_at > " + where.arg(*filter.From))
+	}
+	if filter.To != nil {
+		where.add("starts_at < " + where.arg(*filter.To))
+	}
+	if filter.Uninvoiced {
+		where.add("NOT EXISTS (SELECT 1 FROM invoice_lines l WHERE l.session_id = sessions.id)")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM sessions
+		%s
+		ORDER BY starts_at, id
+	`, sessionColumns, where.where())
+
+	return listSessions(context.Background(), r.db, query, where.args...)
+}
+
+// GetByID retrieves a session by ID
+func (r *PostgresSessionRepository) GetByID(id int) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+
+	session, err := scanSession(r.db.QueryRow(context.Background(), query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// findConflicts returns confirmed sessions of the tutor or client overlapping the span
+func findConflicts(ctx context.Context, db querier, tutorID, clientID int, start, end time.Time, excludeID int) ([]Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE status = $1 AND (tutor_id = $2 OR client_id = $3)
+		  AND starts_at < $5 AND ends_at > $4 AND id <> $6
+		ORDER BY starts_at, id
+	`
+	return listSessions(ctx, db, query, SessionStatusConfirmed, tutorID, clientID, start, end, excludeID)
+}
+
+// Conflicts returns confirmed sessions of the tutor or client overlapping the span
+func (r *PostgresSessionRepository) Conflicts(tutorID, clientID int, start, end time.Time, excludeID int) ([]Session, error) {
+	return findConflicts(context.Background(), r.db, tutorID, clientID, start, end, excludeID)
+}
+
+// lockForConfirmed serializes writes of confirmed sessions for the session's
+// participants and fails with ErrSessionConflict if the session would overlap
+// another confirmed one. Sessions that are not confirmed need no lock.
+func lockForConfirmed(ctx context.Context, tx pgx.Tx, session *Session) error {
+	if session.Status != SessionStatusConfirmed {
+		return nil
+	}
+
+	// Tutors are always locked before clients so concurrent writers cannot deadlock
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, sessionTutorLock, session.TutorID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, sessionClientLock, session.ClientID); err != nil {
+		return err
+	}
+
+	conflicts, err := findConflicts(ctx, tx, session.TutorID, session.ClientID, session.StartsAt, session.EndsAt, session.ID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return ErrSessionConflict
+	}
+	return nil
+}
+
+// insertSession inserts a session inside a transaction, checking confirmed
+// sessions for conflicts first
+func insertSession(ctx context.Context, tx pgx.Tx, session *Session) error {
+	if err := lockForConfirmed(ctx, tx, session); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO sessions (tutor_id, client_id, subject, starts_at, ends_at, status, cancelled_by, cancel_reason, hourly_rate, series_id, occurrence_start)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	return tx.QueryRow(
+		ctx,
+		query,
+		session.TutorID,
+		session.ClientID,
+		session.Subject,
+		session.StartsAt,
+		session.EndsAt,
+		session.Status,
+		session.CancelledBy,
+		session.CancelReason,
+		session.HourlyRate,
+		session.SeriesID,
+		session.OccurrenceStart,
+	).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+}
+
+// updateSession updates a session inside a transaction, checking confirmed
+// sessions for conflicts first
+func updateSession(ctx context.Context, tx pgx.Tx, session *Session) error {
+	if err := lockForConfirmed(ctx, tx, session); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE sessions
+		SET subject = $2, starts_at = $3, ends_at = $4, status = $5,
+		    cancelled_by = NULLIF($6, ''), cancel_reason = NULLIF($7, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	return tx.QueryRow(
+		ctx,
+		query,
+		session.ID,
+		session.Subject,
+		session.StartsAt,
+		session.EndsAt,
+		session.Status,
+		session.CancelledBy,
+		session.CancelReason,
+	).Scan(&session.UpdatedAt)
+}
+
+// Create saves a new session to the database
+func (r *PostgresSessionRepository) Create(session *Session) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertSession(ctx, tx, session); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Update saves a session's subject, times, status and cancellation
+func (r *PostgresSessionRepository) Update(session *Session) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateSession(ctx, tx, session); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpdateMany saves several sessions in one transaction, so either all of
+// them are saved or none are
+func (r *PostgresSessionRepository) UpdateMany(sessions []Session) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for i := range sessions {
+		if err := updateSession(ctx, tx, &sessions[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
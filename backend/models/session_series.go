@@ -0,0 +1,139 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionSeries is a weekly recurring booking. Its occurrences are expanded
+// from the rule when the series is booked and stored as ordinary sessions, so
+// each one can be confirmed, moved or cancelled on its own.
+type SessionSeries struct {
+	ID       int    `json:"id"`
+	TutorID  int    `json:"tutor_id"`
+	ClientID int    `json:"client_id"`
+	Subject  string `json:"subject"`
+	// Rule is the series' RRULE in canonical form
+	Rule     string `json:"rrule"`
+	Timezone string `json:"timezone"`
+	// StartsAt and EndsAt are the times of the first occurrence
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Expand returns the series' occurrences as requested sessions. Every
+// occurrence keeps the first one's wall-clock time in the series' timezone.
+func (s *SessionSeries) Expand() ([]Session, error) {
+	rule, err := ParseRecurrenceRule(s.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Bounded() {
+		return nil, errors.New("rrule must end with COUNT or UNTIL")
+	}
+	loc, err := LoadTimezone(s.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	starts, err := rule.Occurrences(s.StartsAt, loc, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if len(starts) == 0 {
+		return nil, errors.New("rrule has no occurrences")
+	}
+
+	length := s.EndsAt.Sub(s.StartsAt)
+	occurrences := make([]Session, 0, len(starts))
+	for _, start := range starts {
+		occurrences = append(occurrences, Session{
+			TutorID:         s.TutorID,
+			ClientID:        s.ClientID,
+			Subject:         s.Subject,
+			StartsAt:        start,
+			EndsAt:          start.Add(length),
+			Status:          SessionStatusRequested,
+			OccurrenceStart: &start,
+		})
+	}
+	return occurrences, nil
+}
+
+// seriesColumns is the column list scanned by scanSeries
+const seriesColumns = `id, tutor_id, client_id, subject, rrule, timezone, starts_at, ends_at, created_at, updated_at`
+
+// scanSeries scans a row selected with seriesColumns
+func scanSeries(row pgx.Row) (SessionSeries, error) {
+	var series SessionSeries
+	err := row.Scan(
+		&series.ID,
+		&series.TutorID,
+		&series.ClientID,
+		&series.Subject,
+		&series.Rule,
+		&series.Timezone,
+		&series.StartsAt,
+		&series.EndsAt,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+	)
+	return series, err
+}
+
+// GetSeries retrieves a session series by ID
+func (r *PostgresSessionRepository) GetSeries(id int) (*SessionSeries, error) {
+	query := `SELECT ` + seriesColumns + ` FROM session_series WHERE id = $1`
+
+	series, err := scanSeries(r.db.QueryRow(context.Background(), query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// CreateSeries saves a new series and its occurrences in one transaction
+func (r *PostgresSessionRepository) CreateSeries(series *SessionSeries, occurrences []Session) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO session_series (tutor_id, client_id, subject, rrule, timezone, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		series.TutorID,
+		series.ClientID,
+		series.Subject,
+		series.Rule,
+		series.Timezone,
+		series.StartsAt,
+		series.EndsAt,
+	).Scan(&series.ID, &series.CreatedAt, &series.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range occurrences {
+		occurrences[i].SeriesID = &series.ID
+		if err := insertSession(ctx, tx, &occurrences[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,303 @@
+package models
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Subject is a canonical subject in the taxonomy
+type Subject struct {
+	ID       int      `json:"id"`
+	Slug     string   `json:"slug"`
+	Name     string   `json:"name"`
+	ParentID *int     `json:"parent_id"`
+	Aliases  []string `json:"aliases"`
+}
+
+// SubjectNode is a subject with its children, for rendering the taxonomy as a tree
+type SubjectNode struct {
+	Subject
+	Children []*SubjectNode `json:"children"`
+}
+
+// SubjectTaxonomy resolves free-form subject names to canonical subjects and
+// expands a subject into its descendants
+type SubjectTaxonomy struct {
+	subjects []Subject
+	byID     map[int]*Subject
+	byKey    map[string]*Subject // lower-cased names and aliases
+	children map[int][]int
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// subjectSlug turns a subject name into a URL-safe identifier, e.g. "C++" -> "c-plus-plus"
+func subjectSlug(name string) string {
+	slug := strings.ToLower(name)
+	slug = strings.ReplaceAll(slug, "+", " plus ")
+	slug = strings.ReplaceAll(slug, "&", " and ")
+	return strings.Trim(slugPattern.ReplaceAllString(slug, "-"), "-")
+}
+
+// newSubjectTaxonomy indexes a list of subjects
+func newSubjectTaxonomy(subjects []Subject) *SubjectTaxonomy {
+	taxonomy := &SubjectTaxonomy{
+		subjects: subjects,
+		byID:     make(map[int]*Subject, len(subjects)),
+		byKey:    make(map[string]*Subject, len(subjects)),
+		children: make(map[int][]int),
+	}
+
+	for i := range taxonomy.subjects {
+		subject := &taxonomy.subjects[i]
+		taxonomy.byID[subject.ID] = subject
+		taxonomy.byKey[strings.ToLower(subject.Name)] = subject
+		if subject.ParentID != nil {
+			taxonomy.children[*subject.ParentID] = append(taxonomy.children[*subject.ParentID], subject.ID)
+		}
+	}
+
+	// Aliases never shadow a canonical name
+	for i := range taxonomy.subjects {
+		subject := &taxonomy.subjects[i]
+		for _, alias := range subject.Aliases {
+			key := strings.ToLower(alias)
+			if _, taken := taxonomy.byKey[key]; !taken {
+				taxonomy.byKey[key] = subject
+			}
+		}
+	}
+
+	return taxonomy
+}
+
+// seedTaxonomy builds the taxonomy from the built-in seed, numbering subjects in seed order
+func seedTaxonomy() *SubjectTaxonomy {
+	ids := make(map[string]int, len(subjectSeeds))
+	subjects := make([]Subject, 0, len(subjectSeeds))
+	for i, seed := range subjectSeeds {
+		subject := Subject{ID: i + 1, Slug: subjectSlug(seed.Name), Name: seed.Name, Aliases: seed.Aliases}
+		if subject.Aliases == nil {
+			subject.Aliases = []string{}
+		}
+		if parentID, ok := ids[seed.Parent]; ok {
+			subject.ParentID = &parentID
+		}
+		ids[seed.Name] = subject.ID
+		subjects = append(subjects, subject)
+	}
+	return newSubjectTaxonomy(subjects)
+}
+
+// PostgresSubjectRepository stores the taxonomy in the subjects and
+// subject_aliases tables, caching it after the first load
+type PostgresSubjectRepository struct {
+	db       *pgxpool.Pool
+	mu       sync.Mutex
+	taxonomy *SubjectTaxonomy
+}
+
+// NewPostgresSubjectRepository returns a subject repository backed by the pool
+func NewPostgresSubjectRepository(db *pgxpool.Pool) *PostgresSubjectRepository {
+	return &PostgresSubjectRepository{db: db}
+}
+
+// Seed inserts the built-in taxonomy. Existing subjects and aliases are left
+// untouched, so it is safe to run on every start.
+func (r *PostgresSubjectRepository) Seed() error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, seed := range subjectSeeds {
+		var parentSlug *string
+		if seed.Parent != "" {
+			slug := subjectSlug(seed.Parent)
+			parentSlug = &slug
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO subjects (slug, name, parent_id)
+			VALUES ($1, $2, (SELECT id FROM subjects WHERE slug = $3))
+			ON CONFLICT (slug) DO NOTHING
+		`, subjectSlug(seed.Name), seed.Name, parentSlug)
+		if err != nil {
+			return err
+		}
+
+		for _, alias := range seed.Aliases {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO subject_aliases (alias, subject_id)
+				SELECT lower($1), id FROM subjects WHERE slug = $2
+				ON CONFLICT (alias) DO NOTHING
+			`, alias, subjectSlug(seed.Name))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.taxonomy = nil
+	r.mu.Unlock()
+	return nil
+}
+
+// Taxonomy returns the subject taxonomy, loading it on first use
+func (r *PostgresSubjectRepository) Taxonomy() (*SubjectTaxonomy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.taxonomy != nil {
+		return r.taxonomy, nil
+	}
+
+	query := `
+		SELECT s.id, s.slug, s.name, s.parent_id,
+			COALESCE(array_agg(a.alias ORDER BY a.alias) FILTER (WHERE a.alias IS NOT NULL), '{}')
+		FROM subjects s
+		LEFT JOIN subject_aliases a ON a.subject_id = s.id
+		GROUP BY s.id
+		ORDER BY s.id
+	`
+
+	rows, err := r.db.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []Subject{}
+	for rows.Next() {
+		var subject Subject
+		if err := rows.Scan(&subject.ID, &subject.Slug, &subject.Name, &subject.ParentID, &subject.Aliases); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	r.taxonomy = newSubjectTaxonomy(subjects)
+	return r.taxonomy, nil
+}
+
+// MemorySubjectRepository serves the built-in taxonomy
+type MemorySubjectRepository struct {
+	taxonomy *SubjectTaxonomy
+}
+
+// NewMemorySubjectRepository returns a subject repository holding the built-in taxonomy
+func NewMemorySubjectRepository() *MemorySubjectRepository {
+	return &MemorySubjectRepository{taxonomy: seedTaxonomy()}
+}
+
+// Seed does nothing; the built-in taxonomy is always present
+func (r *MemorySubjectRepository) Seed() error {
+	return nil
+}
+
+// Taxonomy returns the built-in taxonomy
+func (r *MemorySubjectRepository) Taxonomy() (*SubjectTaxonomy, error) {
+	return r.taxonomy, nil
+}
+
+// Subjects returns every subject, ordered by ID
+func (t *SubjectTaxonomy) Subjects() []Subject {
+	return t.subjects
+}
+
+// Tree returns the root subjects with their descendants nested beneath them
+func (t *SubjectTaxonomy) Tree() []*SubjectNode {
+	var build func(id int) *SubjectNode
+	build = func(id int) *SubjectNode {
+		node := &SubjectNode{Subject: *t.byID[id], Children: []*SubjectNode{}}
+		for _, child := range t.children[id] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	roots := []*SubjectNode{}
+	for _, subject := range t.subjects {
+		if subject.ParentID == nil {
+			roots = append(roots, build(subject.ID))
+		}
+	}
+	return roots
+}
+
+// Lookup finds the subject with the given name or alias, ignoring case
+func (t *SubjectTaxonomy) Lookup(name string) (*Subject, bool) {
+	subject, ok := t.byKey[strings.ToLower(strings.TrimSpace(name))]
+	return subject, ok
+}
+
+// Canonical returns the canonical name for a subject name or alias. Names
+// outside the taxonomy are returned trimmed but otherwise unchanged.
+func (t *SubjectTaxonomy) Canonical(name string) string {
+	if subject, ok := t.Lookup(name); ok {
+		return subject.Name
+	}
+	return strings.TrimSpace(name)
+}
+
+// Normalize maps subject names to their canonical form, dropping blanks and duplicates
+func (t *SubjectTaxonomy) Normalize(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		canonical := t.Canonical(name)
+		key := strings.ToLower(canonical)
+		if canonical != "" && !seen[key] {
+			seen[key] = true
+			normalized = append(normalized, canonical)
+		}
+	}
+	return normalized
+}
+
+// Expand returns the lower-cased names and aliases of a subject and all of
+// its descendants, so a request for "Mathematics" also covers "Calculus".
+// Subjects outside the taxonomy expand to themselves.
+func (t *SubjectTaxonomy) Expand(name string) []string {
+	subject, ok := t.Lookup(name)
+	if !ok {
+		return lowerAll([]string{name})
+	}
+
+	names := []string{}
+	pending := []int{subject.ID}
+	for len(pending) > 0 {
+		current := t.byID[pending[0]]
+		pending = append(pending[1:], t.children[current.ID]...)
+		names = append(names, current.Name)
+		names = append(names, current.Aliases...)
+	}
+
+	expanded := lowerAll(names)
+	sort.Strings(expanded)
+	return expanded
+}
+
+// normalizeSubjects rewrites subject names to their canonical form before saving
+func normalizeSubjects(subjects SubjectRepository, names []string) ([]string, error) {
+	taxonomy, err := subjects.Taxonomy()
+	if err != nil {
+		return nil, err
+	}
+	return taxonomy.Normalize(names), nil
+}
@@ -0,0 +1,283 @@
+package models
+
+// subjectSeed is a canonical subject with its parent and alternative spellings
+type subjectSeed struct {
+	Name    string
+	Parent  string
+	Aliases []string
+}
+
+// subjectSeeds is the built-in subject taxonomy, parents listed before their
+// children. It mirrors the frontend's constants/subjects.ts, with the flat
+// categories refined into a hierarchy so that broad requests such as
+// "Mathematics" also match tutors of "Calculus".
+var subjectSeeds = []subjectSeed{
+	{Name: "Sciences"},
+	{Name: "Computer Science & Technology"},
+	{Name: "Languages"},
+	{Name: "Social Sciences & Humanities"},
+	{Name: "Arts & Creative"},
+	{Name: "Test Preparation"},
+	{Name: "Engineering & Technical"},
+	{Name: "Medical & Health Sciences"},
+	{Name: "Elementary & K-12"},
+	{Name: "Special Needs & Learning Support"},
+	{Name: "Professional & Career"},
+	{Name: "College & University Applications"},
+	{Name: "Specialized Fields"},
+	{Name: "Life Skills & Practical"},
+	{Name: "Mathematics", Aliases: []string{"Math", "Maths"}},
+	{Name: "Algebra", Parent: "Mathematics"},
+	{Name: "Geometry", Parent: "Mathematics", Aliases: []string{"Geo"}},
+	{Name: "Calculus", Parent: "Mathematics", Aliases: []string{"Calc"}},
+	{Name: "Statistics", Parent: "Mathematics", Aliases: []string{"Stats"}},
+	{Name: "Trigonometry", Parent: "Mathematics", Aliases: []string{"Trig"}},
+	{Name: "Pre-Calculus", Parent: "Mathematics", Aliases: []string{"Precalculus", "Precalc", "Pre-Calc"}},
+	{Name: "Linear Algebra", Parent: "Algebra"},
+	{Name: "Differential Equations", Parent: "Calculus"},
+	{Name: "Discrete Mathematics", Parent: "Mathematics"},
+	{Name: "Number Theory", Parent: "Mathematics"},
+	{Name: "Applied Mathematics", Parent: "Mathematics"},
+	{Name: "Elementary Math", Parent: "Mathematics"},
+	{Name: "Basic Math", Parent: "Mathematics"},
+	{Name: "Mathematical Modeling", Parent: "Applied Mathematics"},
+	{Name: "Physics", Parent: "Sciences", Aliases: []string{"Phys"}},
+	{Name: "Chemistry", Parent: "Sciences", Aliases: []string{"Chem"}},
+	{Name: "Biology", Parent: "Sciences", Aliases: []string{"Bio"}},
+	{Name: "Biochemistry", Parent: "Chemistry"},
+	{Name: "Organic Chemistry", Parent: "Chemistry", Aliases: []string{"Orgo", "O-Chem", "OChem"}},
+	{Name: "Inorganic Chemistry", Parent: "Chemistry"},
+	{Name: "Physical Chemistry", Parent: "Chemistry"},
+	{Name: "Analytical Chemistry", Parent: "Chemistry"},
+	{Name: "Molecular Biology", Parent: "Biology"},
+	{Name: "Cell Biology", Parent: "Biology"},
+	{Name: "Genetics", Parent: "Biology"},
+	{Name: "Microbiology", Parent: "Biology"},
+	{Name: "Anatomy", Parent: "Biology"},
+	{Name: "Physiology", Parent: "Biology"},
+	{Name: "Botany", Parent: "Biology"},
+	{Name: "Zoology", Parent: "Biology"},
+	{Name: "Ecology", Parent: "Biology"},
+	{Name: "Environmental Science", Parent: "Sciences"},
+	{Name: "Earth Science", Parent: "Sciences"},
+	{Name: "Geology", Parent: "Earth Science"},
+	{Name: "Astronomy", Parent: "Sciences"},
+	{Name: "Computer Science", Parent: "Computer Science & Technology", Aliases: []string{"CS", "Comp Sci", "CompSci"}},
+	{Name: "Programming", Parent: "Computer Science"},
+	{Name: "Python", Parent: "Programming"},
+	{Name: "Java", Parent: "Programming"},
+	{Name: "JavaScript", Parent: "Programming", Aliases: []string{"JS"}},
+	{Name: "C++", Parent: "Programming", Aliases: []string{"CPP"}},
+	{Name: "C", Parent: "Programming"},
+	{Name: "Web Development", Parent: "Computer Science"},
+	{Name: "HTML", Parent: "Web Development"},
+	{Name: "CSS", Parent: "Web Development"},
+	{Name: "React", Parent: "Web Development"},
+	{Name: "Node.js", Parent: "Web Development"},
+	{Name: "Data Structures", Parent: "Computer Science"},
+	{Name: "Algorithms", Parent: "Computer Science"},
+	{Name: "Database Design", Parent: "Computer Science"},
+	{Name: "SQL", Parent: "Database Design"},
+	{Name: "Mobile Development", Parent: "Computer Science"},
+	{Name: "Artificial Intelligence", Parent: "Computer Science", Aliases: []string{"AI"}},
+	{Name: "Machine Learning", Parent: "Artificial Intelligence", Aliases: []string{"ML"}},
+	{Name: "Data Science", Parent: "Computer Science"},
+	{Name: "Cybersecurity", Parent: "Computer Science"},
+	{Name: "Software Engineering", Parent: "Computer Science"},
+	{Name: "Computer Networks", Parent: "Computer Science"},
+	{Name: "English", Parent: "Languages"},
+	{Name: "Spanish", Parent: "Languages"},
+	{Name: "French", Parent: "Languages"},
+	{Name: "German", Parent: "Languages"},
+	{Name: "Italian", Parent: "Languages"},
+	{Name: "Portuguese", Parent: "Languages"},
+	{Name: "Chinese (Mandarin)", Parent: "Languages", Aliases: []string{"Mandarin", "Chinese"}},
+	{Name: "Japanese", Parent: "Languages"},
+	{Name: "Korean", Parent: "Languages"},
+	{Name: "Arabic", Parent: "Languages"},
+	{Name: "Russian", Parent: "Languages"},
+	{Name: "Latin", Parent: "Languages"},
+	{Name: "Greek", Parent: "Languages"},
+	{Name: "ESL (English as Second Language)", Parent: "English", Aliases: []string{"ESL", "English as a Second Language", "EFL"}},
+	{Name: "English Literature", Parent: "English", Aliases: []string{"Lit", "Literature"}},
+	{Name: "Writing Skills", Parent: "English"},
+	{Name: "Creative Writing", Parent: "Writing Skills"},
+	{Name: "Grammar", Parent: "English"},
+	{Name: "Vocabulary", Parent: "English"},
+	{Name: "Reading Comprehension", Parent: "English"},
+	{Name: "History", Parent: "Social Sciences & Humanities"},
+	{Name: "World History", Parent: "History"},
+	{Name: "American History", Parent: "History", Aliases: []string{"US History", "U.S. History"}},
+	{Name: "European History", Parent: "History"},
+	{Name: "Ancient History", Parent: "History"},
+	{Name: "Political Science", Parent: "Social Sciences & Humanities", Aliases: []string{"Poli Sci", "PoliSci"}},
+	{Name: "Government", Parent: "Political Science"},
+	{Name: "Psychology", Parent: "Social Sciences & Humanities", Aliases: []string{"Psych"}},
+	{Name: "Sociology", Parent: "Social Sciences & Humanities"},
+	{Name: "Anthropology", Parent: "Social Sciences & Humanities"},
+	{Name: "Philosophy", Parent: "Social Sciences & Humanities"},
+	{Name: "Ethics", Parent: "Philosophy"},
+	{Name: "Economics", Parent: "Social Sciences & Humanities", Aliases: []string{"Econ"}},
+	{Name: "Microeconomics", Parent: "Economics", Aliases: []string{"Micro"}},
+	{Name: "Macroeconomics", Parent: "Economics", Aliases: []string{"Macro"}},
+	{Name: "Business Studies", Parent: "Social Sciences & Humanities"},
+	{Name: "Accounting", Parent: "Business Studies"},
+	{Name: "Finance", Parent: "Business Studies"},
+	{Name: "Marketing", Parent: "Business Studies"},
+	{Name: "Management", Parent: "Business Studies"},
+	{Name: "Geography", Parent: "Social Sciences & Humanities"},
+	{Name: "International Relations", Parent: "Political Science"},
+	{Name: "Art", Parent: "Arts & Creative"},
+	{Name: "Drawing", Parent: "Art"},
+	{Name: "Painting", Parent: "Art"},
+	{Name: "Sculpture", Parent: "Art"},
+	{Name: "Art History", Parent: "Art"},
+	{Name: "Music", Parent: "Arts & Creative"},
+	{Name: "Piano", Parent: "Music"},
+	{Name: "Guitar", Parent: "Music"},
+	{Name: "Violin", Parent: "Music"},
+	{Name: "Voice/Singing", Parent: "Music", Aliases: []string{"Voice", "Singing"}},
+	{Name: "Music Theory", Parent: "Music"},
+	{Name: "Music Composition", Parent: "Music"},
+	{Name: "Theater", Parent: "Arts & Creative", Aliases: []string{"Theatre"}},
+	{Name: "Drama", Parent: "Theater"},
+	{Name: "Dance", Parent: "Arts & Creative"},
+	{Name: "Photography", Parent: "Art"},
+	{Name: "Film Studies", Parent: "Arts & Creative"},
+	{Name: "Poetry", Parent: "Creative Writing"},
+	{Name: "SAT Preparation", Parent: "Test Preparation", Aliases: []string{"SAT", "SAT Prep"}},
+	{Name: "ACT Preparation", Parent: "Test Preparation", Aliases: []string{"ACT", "ACT Prep"}},
+	{Name: "GRE Preparation", Parent: "Test Preparation", Aliases: []string{"GRE", "GRE Prep"}},
+	{Name: "GMAT Preparation", Parent: "Test Preparation", Aliases: []string{"GMAT", "GMAT Prep"}},
+	{Name: "LSAT Preparation", Parent: "Test Preparation", Aliases: []string{"LSAT", "LSAT Prep"}},
+	{Name: "MCAT Preparation", Parent: "Test Preparation", Aliases: []string{"MCAT", "MCAT Prep"}},
+	{Name: "TOEFL Preparation", Parent: "Test Preparation", Aliases: []string{"TOEFL"}},
+	{Name: "IELTS Preparation", Parent: "Test Preparation", Aliases: []string{"IELTS"}},
+	{Name: "AP Biology", Parent: "Biology"},
+	{Name: "AP Chemistry", Parent: "Chemistry"},
+	{Name: "AP Physics", Parent: "Physics"},
+	{Name: "AP Calculus", Parent: "Calculus"},
+	{Name: "AP Statistics", Parent: "Statistics"},
+	{Name: "AP English Language", Parent: "English"},
+	{Name: "AP English Literature", Parent: "English Literature"},
+	{Name: "AP History", Parent: "History", Aliases: []string{"APUSH"}},
+	{Name: "AP Psychology", Parent: "Psychology"},
+	{Name: "AP Computer Science", Parent: "Computer Science"},
+	{Name: "IB Biology", Parent: "Biology"},
+	{Name: "IB Chemistry", Parent: "Chemistry"},
+	{Name: "IB Physics", Parent: "Physics"},
+	{Name: "IB Mathematics", Parent: "Mathematics"},
+	{Name: "IB English", Parent: "English"},
+	{Name: "IB History", Parent: "History"},
+	{Name: "Engineering", Parent: "Engineering & Technical"},
+	{Name: "Mechanical Engineering", Parent: "Engineering"},
+	{Name: "Electrical Engineering", Parent: "Engineering"},
+	{Name: "Civil Engineering", Parent: "Engineering"},
+	{Name: "Chemical Engineering", Parent: "Engineering"},
+	{Name: "Biomedical Engineering", Parent: "Engineering"},
+	{Name: "Aerospace Engineering", Parent: "Engineering"},
+	{Name: "Industrial Engineering", Parent: "Engineering"},
+	{Name: "Materials Science", Parent: "Engineering"},
+	{Name: "Thermodynamics", Parent: "Engineering"},
+	{Name: "Fluid Mechanics", Parent: "Engineering"},
+	{Name: "Statics", Parent: "Engineering"},
+	{Name: "Dynamics", Parent: "Engineering"},
+	{Name: "Circuit Analysis", Parent: "Electrical Engineering"},
+	{Name: "Digital Logic", Parent: "Electrical Engineering"},
+	{Name: "Medicine", Parent: "Medical & Health Sciences"},
+	{Name: "Pre-Med", Parent: "Medical & Health Sciences"},
+	{Name: "Nursing", Parent: "Medical & Health Sciences"},
+	{Name: "Public Health", Parent: "Medical & Health Sciences"},
+	{Name: "Health Sciences", Parent: "Medical & Health Sciences"},
+	{Name: "Pharmacology", Parent: "Medical & Health Sciences"},
+	{Name: "Pathology", Parent: "Medical & Health Sciences"},
+	{Name: "Medical Terminology", Parent: "Medical & Health Sciences"},
+	{Name: "Nutrition", Parent: "Medical & Health Sciences"},
+	{Name: "Kinesiology", Parent: "Medical & Health Sciences"},
+	{Name: "Physical Therapy", Parent: "Medical & Health Sciences"},
+	{Name: "Occupational Therapy", Parent: "Medical & Health Sciences"},
+	{Name: "Elementary Science", Parent: "Sciences"},
+	{Name: "Elementary Reading", Parent: "English"},
+	{Name: "Elementary Writing", Parent: "Writing Skills"},
+	{Name: "Kindergarten", Parent: "Elementary & K-12", Aliases: []string{"K"}},
+	{Name: "Grade 1", Parent: "Elementary & K-12"},
+	{Name: "Grade 2", Parent: "Elementary & K-12"},
+	{Name: "Grade 3", Parent: "Elementary & K-12"},
+	{Name: "Grade 4", Parent: "Elementary & K-12"},
+	{Name: "Grade 5", Parent: "Elementary & K-12"},
+	{Name: "Grade 6", Parent: "Elementary & K-12"},
+	{Name: "Grade 7", Parent: "Elementary & K-12"},
+	{Name: "Grade 8", Parent: "Elementary & K-12"},
+	{Name: "Grade 9", Parent: "Elementary & K-12"},
+	{Name: "Grade 10", Parent: "Elementary & K-12"},
+	{Name: "Grade 11", Parent: "Elementary & K-12"},
+	{Name: "Grade 12", Parent: "Elementary & K-12"},
+	{Name: "Middle School Math", Parent: "Mathematics"},
+	{Name: "Middle School Science", Parent: "Sciences"},
+	{Name: "High School Math", Parent: "Mathematics"},
+	{Name: "High School Science", Parent: "Sciences"},
+	{Name: "High School English", Parent: "English"},
+	{Name: "High School History", Parent: "History"},
+	{Name: "Special Education", Parent: "Special Needs & Learning Support"},
+	{Name: "Learning Disabilities", Parent: "Special Needs & Learning Support"},
+	{Name: "ADHD Support", Parent: "Special Needs & Learning Support"},
+	{Name: "Autism Support", Parent: "Special Needs & Learning Support"},
+	{Name: "Dyslexia Support", Parent: "Special Needs & Learning Support"},
+	{Name: "Study Skills", Parent: "Special Needs & Learning Support"},
+	{Name: "Organization Skills", Parent: "Special Needs & Learning Support"},
+	{Name: "Time Management", Parent: "Special Needs & Learning Support"},
+	{Name: "Note Taking", Parent: "Special Needs & Learning Support"},
+	{Name: "Test Taking Strategies", Parent: "Special Needs & Learning Support"},
+	{Name: "Resume Writing", Parent: "Professional & Career"},
+	{Name: "Interview Preparation", Parent: "Professional & Career"},
+	{Name: "Career Counseling", Parent: "Professional & Career"},
+	{Name: "Job Search Strategies", Parent: "Professional & Career"},
+	{Name: "Professional Development", Parent: "Professional & Career"},
+	{Name: "Public Speaking", Parent: "Professional & Career"},
+	{Name: "Presentation Skills", Parent: "Professional & Career"},
+	{Name: "Leadership Skills", Parent: "Professional & Career"},
+	{Name: "Communication Skills", Parent: "Professional & Career"},
+	{Name: "Project Management", Parent: "Professional & Career"},
+	{Name: "College Application", Parent: "College & University Applications"},
+	{Name: "College Essays", Parent: "College Application"},
+	{Name: "Personal Statement", Parent: "College Application"},
+	{Name: "University Application", Parent: "College & University Applications"},
+	{Name: "Admissions Counseling", Parent: "College & University Applications"},
+	{Name: "Scholarship Applications", Parent: "College & University Applications"},
+	{Name: "College Preparation", Parent: "College & University Applications"},
+	{Name: "Essay Writing", Parent: "Writing Skills"},
+	{Name: "Graduate School Application", Parent: "College & University Applications"},
+	{Name: "Medical School Application", Parent: "Graduate School Application"},
+	{Name: "Law School Application", Parent: "Graduate School Application"},
+	{Name: "Architecture", Parent: "Specialized Fields"},
+	{Name: "Urban Planning", Parent: "Specialized Fields"},
+	{Name: "Journalism", Parent: "Specialized Fields"},
+	{Name: "Communications", Parent: "Specialized Fields"},
+	{Name: "Public Relations", Parent: "Specialized Fields"},
+	{Name: "Social Work", Parent: "Specialized Fields"},
+	{Name: "Education", Parent: "Specialized Fields"},
+	{Name: "Library Science", Parent: "Specialized Fields"},
+	{Name: "Information Science", Parent: "Specialized Fields"},
+	{Name: "Criminal Justice", Parent: "Specialized Fields"},
+	{Name: "Law", Parent: "Specialized Fields"},
+	{Name: "Paralegal Studies", Parent: "Specialized Fields"},
+	{Name: "Real Estate", Parent: "Specialized Fields"},
+	{Name: "Insurance", Parent: "Specialized Fields"},
+	{Name: "Hospitality Management", Parent: "Specialized Fields"},
+	{Name: "Tourism", Parent: "Specialized Fields"},
+	{Name: "Agriculture", Parent: "Specialized Fields"},
+	{Name: "Veterinary Science", Parent: "Specialized Fields"},
+	{Name: "Forestry", Parent: "Specialized Fields"},
+	{Name: "Environmental Studies", Parent: "Specialized Fields"},
+	{Name: "Study Habits", Parent: "Life Skills & Practical"},
+	{Name: "Academic Writing", Parent: "Writing Skills"},
+	{Name: "Research Methods", Parent: "Life Skills & Practical"},
+	{Name: "Critical Thinking", Parent: "Life Skills & Practical"},
+	{Name: "Problem Solving", Parent: "Life Skills & Practical"},
+	{Name: "Memory Techniques", Parent: "Life Skills & Practical"},
+	{Name: "Speed Reading", Parent: "Life Skills & Practical"},
+	{Name: "Essay Structure", Parent: "Life Skills & Practical"},
+	{Name: "Citation Styles (APA, MLA, Chicago)", Parent: "Life Skills & Practical"},
+	{Name: "Thesis Writing", Parent: "Life Skills & Practical"},
+	{Name: "Dissertation Support", Parent: "Life Skills & Practical"},
+}
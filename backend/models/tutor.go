@@ -3,102 +3,159 @@ package models
 import (
 	"context"
 	"time"
-	"tutor-backend/database"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Tutor represents a tutor in the system
 type Tutor struct {
-	ID            int       `json:"id"`
-	Name          string    `json:"name"`
-	Email         string    `json:"email"`
-	Subjects      []string  `json:"subjects"`
-	Pay           float64   `json:"pay"`
-	Rating        float64   `json:"rating"`
-	Bio           string    `json:"bio"`
-	Language      string    `json:"language"`
-	Location      string    `json:"location"`
-	Availability  string    `json:"availability"`
-	Experience    string    `json:"experience"`
-	Education     string    `json:"education"`
-	Certification string    `json:"certification"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Subjects      []string   `json:"subjects"`
+	Pay           float64    `json:"pay"`
+	Rating        float64    `json:"rating"`
+	ReviewCount   int        `json:"review_count"`
+	Bio           string     `json:"bio"`
+	Language      string     `json:"language"`
+	Location      string     `json:"location"`
+	Availability  string     `json:"availability"`
+	Experience    string     `json:"experience"`
+	Education     string     `json:"education"`
+	Certification string     `json:"certification"`
+	MaxClients    int        `json:"max_clients"`
+	Timezone      string     `json:"timezone"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
 }
 
-// GetTutors returns all tutors from the database
-func GetTutors() ([]Tutor, error) {
-	db := database.GetDB()
-	if db == nil {
-		// Return sample data if database is not available
-		return getSampleTutors(), nil
+// DefaultMaxClients is how many clients a tutor takes on when they have not set a limit
+const DefaultMaxClients = 3
+
+// tutorColumns is the column list scanned by scanTutor
+const tutorColumns = `id, name, email, subjects, pay, rating, review_count, bio, language, location, availability, experience, education, certification, max_clients, timezone, created_at, updated_at, deleted_at`
+
+// scanTutor scans a row selected with tutorColumns
+func scanTutor(row pgx.Row) (Tutor, error) {
+	var tutor Tutor
+	err := row.Scan(
+		&tutor.ID,
+		&tutor.Name,
+		&tutor.Email,
+		&tutor.Subjects,
+		&tutor.Pay,
+		&tutor.Rating,
+		&tutor.ReviewCount,
+		&tutor.Bio,
+		&tutor.Language,
+		&tutor.Location,
+		&tutor.Availability,
+		&tutor.Experience,
+		&tutor.Education,
+		&tutor.Certification,
+		&tutor.MaxClients,
+		&tutor.Timezone,
+		&tutor.CreatedAt,
+		&tutor.UpdatedAt,
+		&tutor.DeletedAt,
+	)
+	return tutor, err
+}
+
+// Capacity returns the maximum number of clients the tutor can be paired with
+func (t *Tutor) Capacity() int {
+	if t.MaxClients <= 0 {
+		return DefaultMaxClients
 	}
+	return t.MaxClients
+}
 
-	// Query with consistent column order
-	query := `
-		SELECT id, name, email, subjects, pay, rating, bio, language, location, availability, experience, education, certification, created_at, updated_at
-		FROM tutors 
-		ORDER BY created_at DESC
-	`
+// PostgresTutorRepository stores tutors in the tutors table, with their
+// parsed availability in availability_slots
+type PostgresTutorRepository struct {
+	db       *pgxpool.Pool
+	subjects SubjectRepository
+}
 
-	rows, err := db.Query(context.Background(), query)
+// NewPostgresTutorRepository returns a tutor repository backed by the pool,
+// normalizing subjects through the taxonomy in subjects
+func NewPostgresTutorRepository(db *pgxpool.Pool, subjects SubjectRepository) *PostgresTutorRepository {
+	return &PostgresTutorRepository{db: db, subjects: subjects}
+}
+
+// list runs a query selecting tutorColumns and scans every row
+func (r *PostgresTutorRepository) list(query string, args ...any) ([]Tutor, error) {
+	rows, err := r.db.Query(context.Background(), query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tutors []Tutor
+	tutors := []Tutor{}
 	for rows.Next() {
-		var tutor Tutor
-		err := rows.Scan(
-			&tutor.ID,
-			&tutor.Name,
-			&tutor.Email,
-			&tutor.Subjects,
-			&tutor.Pay,
-			&tutor.Rating,
-			&tutor.Bio,
-			&tutor.Language,
-			&tutor.Location,
-			&tutor.Availability,
-			&tutor.Experience,
-			&tutor.Education,
-			&tutor.Certification,
-			&tutor.CreatedAt,
-			&tutor.UpdatedAt,
-		)
+		tutor, err := scanTutor(rows)
 		if err != nil {
 			return nil, err
 		}
 		tutors = append(tutors, tutor)
 	}
 
-	return tutors, nil
+	return tutors, rows.Err()
 }
 
-// CreateTutor saves a new tutor to the database
-func CreateTutor(tutor *Tutor) error {
-	db := database.GetDB()
-	if db == nil {
-		return nil // Skip database operations if not available
+// List returns all tutors that are not deleted, newest first
+func (r *PostgresTutorRepository) List() ([]Tutor, error) {
+	return r.list(`
+		SELECT ` + tutorColumns + `
+		FROM tutors 
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+	`)
+}
+
+// AvailableAt returns the tutors whose weekly availability covers the given instant,
+// evaluated in each tutor's own timezone, leaving out tutors busy at that instant
+func (r *PostgresTutorRepository) AvailableAt(at time.Time) ([]Tutor, error) {
+	return r.list(`
+		SELECT `+tutorColumns+`
+		FROM tutors 
+		WHERE deleted_at IS NULL AND EXISTS (
+			SELECT 1 FROM availability_slots s
+			WHERE s.owner_type = 'tutor' AND s.owner_id = tutors.id
+			  AND minute_of_week($1, s.timezone) >= s.start_minute_of_week
+			  AND minute_of_week($1, s.timezone) < s.end_minute_of_week
+		) AND NOT EXISTS (
+			SELECT 1 FROM availability_exceptions e
+			WHERE e.owner_type = 'tutor' AND e.owner_id = tutors.id
+			  AND e.starts_at <= $1 AND e.ends_at > $1
+		)
+		ORDER BY created_at DESC
+	`, at)
+}
+
+// Create saves a new tutor to the database
+func (r *PostgresTutorRepository) Create(tutor *Tutor) error {
+	subjects, err := normalizeSubjects(r.subjects, tutor.Subjects)
+	if err != nil {
+		return err
 	}
+	tutor.Subjects = subjects
 
-	// First, try to insert with email column (for updated schema)
 	query := `
-		INSERT INTO tutors (name, email, subjects, pay, rating, bio, language, location, availability, experience, education, certification)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-		RETURNING id, created_at, updated_at
+		INSERT INTO tutors (name, email, subjects, pay, bio, language, location, availability, experience, education, certification, max_clients, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE(NULLIF($12, 0), $13), $14)
+		RETURNING id, rating, review_count, max_clients, created_at, updated_at
 	`
 
-	err := db.QueryRow(
+	err = r.db.QueryRow(
 		context.Background(),
 		query,
 		tutor.Name,
 		tutor.Email,
 		tutor.Subjects,
 		tutor.Pay,
-		tutor.Rating,
 		tutor.Bio,
 		tutor.Language,
 		tutor.Location,
@@ -106,67 +163,27 @@ func CreateTutor(tutor *Tutor) error {
 		tutor.Experience,
 		tutor.Education,
 		tutor.Certification,
-	).Scan(&tutor.ID, &tutor.CreatedAt, &tutor.UpdatedAt)
+		tutor.MaxClients,
+		DefaultMaxClients,
+		tutor.Timezone,
+	).Scan(&tutor.ID, &tutor.Rating, &tutor.ReviewCount, &tutor.MaxClients, &tutor.CreatedAt, &tutor.UpdatedAt)
 
 	if err != nil {
-		// If that fails (email column doesn't exist), try without email
-		queryWithoutEmail := `
-			INSERT INTO tutors (name, subjects, pay, rating, bio, language, location, availability, experience, education, certification)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-			RETURNING id, created_at, updated_at
-		`
-
-		err = db.QueryRow(
-			context.Background(),
-			queryWithoutEmail,
-			tutor.Name,
-			tutor.Subjects,
-			tutor.Pay,
-			tutor.Rating,
-			tutor.Bio,
-			tutor.Language,
-			tutor.Location,
-			tutor.Availability,
-			tutor.Experience,
-			tutor.Education,
-			tutor.Certification,
-		).Scan(&tutor.ID, &tutor.CreatedAt, &tutor.UpdatedAt)
+		return err
 	}
 
-	return err
+	return syncAvailabilitySlots(r.db, OwnerTypeTutor, tutor.ID, tutor.Availability, tutor.Timezone)
 }
 
-// GetTutorByID retrieves a tutor by ID from the database
-func GetTutorByID(id int) (*Tutor, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, pgx.ErrNoRows
-	}
-
+// GetByID retrieves a tutor by ID from the database
+func (r *PostgresTutorRepository) GetByID(id int) (*Tutor, error) {
 	query := `
-		SELECT id, name, email, subjects, pay, rating, bio, language, location, availability, experience, education, certification, created_at, updated_at
+		SELECT ` + tutorColumns + `
 		FROM tutors 
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	var tutor Tutor
-	err := db.QueryRow(context.Background(), query, id).Scan(
-		&tutor.ID,
-		&tutor.Name,
-		&tutor.Email,
-		&tutor.Subjects,
-		&tutor.Pay,
-		&tutor.Rating,
-		&tutor.Bio,
-		&tutor.Language,
-		&tutor.Location,
-		&tutor.Availability,
-		&tutor.Experience,
-		&tutor.Education,
-		&tutor.Certification,
-		&tutor.CreatedAt,
-		&tutor.UpdatedAt,
-	)
+	tutor, err := scanTutor(r.db.QueryRow(context.Background(), query, id))
 
 	if err != nil {
 		return nil, err
@@ -175,44 +192,15 @@ func GetTutorByID(id int) (*Tutor, error) {
 	return &tutor, nil
 }
 
-// GetTutorByEmail retrieves a tutor by email from the database
-func GetTutorByEmail(email string) (*Tutor, error) {
-	db := database.GetDB()
-	if db == nil {
-		// Check sample data if database is not available
-		tutors := getSampleTutors()
-		for _, tutor := range tutors {
-			if tutor.Email == email {
-				return &tutor, nil
-			}
-		}
-		return nil, pgx.ErrNoRows
-	}
-
+// GetByEmail retrieves a tutor by email from the database
+func (r *PostgresTutorRepository) GetByEmail(email string) (*Tutor, error) {
 	query := `
-		SELECT id, name, email, subjects, pay, rating, bio, language, location, availability, experience, education, certification, created_at, updated_at
+		SELECT ` + tutorColumns + `
 		FROM tutors 
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`
 
-	var tutor Tutor
-	err := db.QueryRow(context.Background(), query, email).Scan(
-		&tutor.ID,
-		&tutor.Name,
-		&tutor.Email,
-		&tutor.Subjects,
-		&tutor.Pay,
-		&tutor.Rating,
-		&tutor.Bio,
-		&tutor.Language,
-		&tutor.Location,
-		&tutor.Availability,
-		&tutor.Experience,
-		&tutor.Education,
-		&tutor.Certification,
-		&tutor.CreatedAt,
-		&tutor.UpdatedAt,
-	)
+	tutor, err := scanTutor(r.db.QueryRow(context.Background(), query, email))
 
 	if err != nil {
 		return nil, err
@@ -221,31 +209,39 @@ func GetTutorByEmail(email string) (*Tutor, error) {
 	return &tutor, nil
 }
 
-// UpdateTutor updates an existing tutor in the database
-func UpdateTutor(tutor *Tutor) error {
-	db := database.GetDB()
-	if db == nil {
-		return nil // Skip database operations if not available
+// Update updates an existing tutor in the database
+func (r *PostgresTutorRepository) Update(tutor *Tutor, audit *ChangeAudit) error {
+	subjects, err := normalizeSubjects(r.subjects, tutor.Subjects)
+	if err != nil {
+		return err
 	}
+	tutor.Subjects = subjects
 
 	query := `
 		UPDATE tutors 
-		SET name = $2, email = $3, subjects = $4, pay = $5, rating = $6, bio = $7, 
-		    language = $8, location = $9, availability = $10, experience = $11, 
-		    education = $12, certification = $13, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1
-		RETURNING updated_at
+		SET name = $2, email = $3, subjects = $4, pay = $5, bio = $6, 
+		    language = $7, location = $8, availability = $9, experience = $10, 
+		    education = $11, certification = $12, max_clients = COALESCE(NULLIF($13, 0), max_clients),
+		    timezone = $14, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING rating, review_count, max_clients, updated_at
 	`
 
-	err := db.QueryRow(
-		context.Background(),
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(
+		ctx,
 		query,
 		tutor.ID,
 		tutor.Name,
 		tutor.Email,
 		tutor.Subjects,
 		tutor.Pay,
-		tutor.Rating,
 		tutor.Bio,
 		tutor.Language,
 		tutor.Location,
@@ -253,21 +249,38 @@ func UpdateTutor(tutor *Tutor) error {
 		tutor.Experience,
 		tutor.Education,
 		tutor.Certification,
-	).Scan(&tutor.UpdatedAt)
+		tutor.MaxClients,
+		tutor.Timezone,
+	).Scan(&tutor.Rating, &tutor.ReviewCount, &tutor.MaxClients, &tutor.UpdatedAt)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	if err := syncAvailabilitySlots(tx, OwnerTypeTutor, tutor.ID, tutor.Availability, tutor.Timezone); err != nil {
+		return err
+	}
+	if err := audit.save(ctx, tx, tutor); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-// DeleteTutor removes a tutor from the database
-func DeleteTutor(id int) error {
-	db := database.GetDB()
-	if db == nil {
-		return nil // Skip database operations if not available
+// Delete soft-deletes a tutor. The profile is hidden from every query
+// until it is restored, and purged for good after the retention window.
+// Its open pairings end with it and stay ended if it is restored.
+func (r *PostgresTutorRepository) Delete(id int, audit *ChangeAudit) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	query := `DELETE FROM tutors WHERE id = $1`
+	query := `UPDATE tutors SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
 
-	result, err := db.Exec(context.Background(), query, id)
+	result, err := tx.Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -277,29 +290,54 @@ func DeleteTutor(id int) error {
 		return pgx.ErrNoRows
 	}
 
-	return nil
+	if err := endPairings(ctx, tx, "tutor_id", id); err != nil {
+		return err
+	}
+	if err := audit.save(ctx, tx, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-// getSampleTutors returns sample tutor data (fallback when database is not available)
-func getSampleTutors() []Tutor {
-	return []Tutor{
-		{
-			ID:       1,
-			Name:     "John Smith",
-			Email:    "john.smith@email.com",
-			Subjects: []string{"Mathematics", "Physics"},
-			Pay:      50.0,
-			Rating:   4.8,
-			Bio:      "Experienced math and physics tutor with 5+ years of experience",
-		},
-		{
-			ID:       2,
-			Name:     "Sarah Johnson",
-			Email:    "sarah.johnson@email.com",
-			Subjects: []string{"English", "Literature", "Writing"},
-			Pay:      45.0,
-			Rating:   4.9,
-			Bio:      "English literature expert specializing in creative writing and essay composition",
-		},
+// ListDeleted returns soft-deleted tutors, most recently deleted first
+func (r *PostgresTutorRepository) ListDeleted() ([]Tutor, error) {
+	return r.list(`
+		SELECT ` + tutorColumns + `
+		FROM tutors
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`)
+}
+
+// Restore undoes a soft delete and returns the restored tutor
+func (r *PostgresTutorRepository) Restore(id int, audit *ChangeAudit) (*Tutor, error) {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE tutors
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING ` + tutorColumns
+
+	tutor, err := scanTutor(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := audit.save(ctx, tx, &tutor); err != nil {
+		return nil, err
 	}
+
+	return &tutor, tx.Commit(ctx)
+}
+
+// Purge permanently removes tutors soft-deleted before the cutoff, along
+// with their availability slots
+func (r *PostgresTutorRepository) Purge(cutoff time.Time) (int64, error) {
+	return purgeProfiles(r.db, "tutors", OwnerTypeTutor, cutoff)
 }
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FacetCount is the number of tutors sharing a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// TutorFacets are facet counts for the tutor directory sidebar
+type TutorFacets struct {
+	Subjects       []FacetCount `json:"subjects"`
+	Languages      []FacetCount `json:"languages"`
+	Education      []FacetCount `json:"education"`
+	PayBrackets    []FacetCount `json:"pay_brackets"`
+	RatingBrackets []FacetCount `json:"rating_brackets"`
+}
+
+// bracket is a labelled numeric range; Max is exclusive and zero means unbounded
+type bracket struct {
+	value string
+	label string
+	min   float64
+	max   float64
+}
+
+var payBrackets = []bracket{
+	{value: "0-25", label: "Under $25", min: 0, max: 25},
+	{value: "25-50", label: "$25 - $50", min: 25, max: 50},
+	{value: "50-75", label: "$50 - $75", min: 50, max: 75},
+	{value: "75-100", label: "$75 - $100", min: 75, max: 100},
+	{value: "100+", label: "$100+", min: 100},
+}
+
+var ratingBrackets = []bracket{
+	{value: "4.5+", label: "4.5 & up", min: 4.5},
+	{value: "4-4.5", label: "4.0 - 4.5", min: 4, max: 4.5},
+	{value: "3-4", label: "3.0 - 4.0", min: 3, max: 4},
+	{value: "0-3", label: "Under 3.0", min: 0, max: 3},
+}
+
+// Facets counts tutors per subject, language, education level, pay
+// bracket and rating bracket under the filter. Each facet ignores its own
+// filter so the sidebar still shows the alternatives to the current choice.
+func (r *PostgresTutorRepository) Facets(filter TutorFilter) (*TutorFacets, error) {
+	facets := &TutorFacets{
+		Subjects:       []FacetCount{},
+		Languages:      []FacetCount{},
+		Education:      []FacetCount{},
+		PayBrackets:    []FacetCount{},
+		RatingBrackets: []FacetCount{},
+	}
+
+	if err := filter.expandSubjects(r.subjects); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	withoutSubjects := filter
+	withoutSubjects.Subjects, withoutSubjects.subjectGroups = nil, nil
+	where, _ := withoutSubjects.conditions()
+	facets.Subjects, err = countFacet(r.db, where, `
+		SELECT subject, subject, COUNT(DISTINCT id)
+		FROM tutors, unnest(subjects) AS subject
+		%s
+		GROUP BY subject
+		ORDER BY COUNT(DISTINCT id) DESC, subject`)
+	if err != nil {
+		return nil, err
+	}
+
+	withoutLanguage := filter
+	withoutLanguage.Language = ""
+	where, _ = withoutLanguage.conditions()
+	where.add("COALESCE(language, '') <> ''")
+	facets.Languages, err = countFacet(r.db, where, `
+		SELECT language, language, COUNT(*)
+		FROM tutors
+		%s
+		GROUP BY language
+		ORDER BY COUNT(*) DESC, language`)
+	if err != nil {
+		return nil, err
+	}
+
+	withoutEducation := filter
+	withoutEducation.Education = ""
+	where, _ = withoutEducation.conditions()
+	where.add("COALESCE(education, '') <> ''")
+	facets.Education, err = countFacet(r.db, where, `
+		SELECT education, education, COUNT(*)
+		FROM tutors
+		%s
+		GROUP BY education
+		ORDER BY COUNT(*) DESC, education`)
+	if err != nil {
+		return nil, err
+	}
+
+	withoutPay := filter
+	withoutPay.MinPay, withoutPay.MaxPay = nil, nil
+	where, _ = withoutPay.conditions()
+	facets.PayBrackets, err = countBrackets(r.db, where, "pay", payBrackets)
+	if err != nil {
+		return nil, err
+	}
+
+	withoutRating := filter
+	withoutRating.MinRating = nil
+	where, _ = withoutRating.conditions()
+	facets.RatingBrackets, err = countBrackets(r.db, where, "COALESCE(rating, 0)", ratingBrackets)
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// countFacet runs a query selecting (value, label, count) rows; the query
+// template receives the WHERE statement as its only verb
+func countFacet(db *pgxpool.Pool, where *conditions, template string) ([]FacetCount, error) {
+	rows, err := db.Query(context.Background(), fmt.Sprintf(template, where.where()), where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []FacetCount{}
+	for rows.Next() {
+		var count FacetCount
+		if err := rows.Scan(&count.Value, &count.Label, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// countBrackets counts rows per bracket of a numeric expression, in bracket order
+func countBrackets(db *pgxpool.Pool, where *conditions, expr string, brackets []bracket) ([]FacetCount, error) {
+	selects := ""
+	for i, b := range brackets {
+		condition := fmt.Sprintf("%s >= %s", expr, where.arg(b.min))
+		if b.max > 0 {
+			condition += fmt.Sprintf(" AND %s < %s", expr, where.arg(b.max))
+		}
+		if i > 0 {
+			selects += ", "
+		}
+		selects += fmt.Sprintf("COUNT(*) FILTER (WHERE %s)", condition)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM tutors %s", selects, where.where())
+
+	totals := make([]int, len(brackets))
+	dest := make([]any, len(brackets))
+	for i := range totals {
+		dest[i] = &totals[i]
+	}
+	if err := db.QueryRow(context.Background(), query, where.args...).Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	counts := make([]FacetCount, 0, len(brackets))
+	for i, b := range brackets {
+		counts = append(counts, FacetCount{Value: b.value, Label: b.label, Count: totals[i]})
+	}
+	return counts, nil
+}
@@ -0,0 +1,292 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TutorFilter narrows and orders a tutor search
+type TutorFilter struct {
+	Query       string // full-text search over bio, experience and certification
+	Subjects    []string
+	MatchAll    bool // require every subject instead of any of them
+	MinPay      *float64
+	MaxPay      *float64
+	MinRating   *float64
+	Language    string
+	Location    string
+	Education   string
+	AvailableAt *time.Time
+	Sort        string
+	Cursor      string
+	Limit       int
+
+	// subjectGroups holds, per requested subject, the names it matches once
+	// expanded through the taxonomy; see expandSubjects
+	subjectGroups [][]string
+}
+
+// TutorResult is a tutor in search results. Rank and Snippet are only set
+// for full-text searches; Snippet is HTML-escaped with matches in <mark> tags.
+type TutorResult struct {
+	Tutor
+	Rank    *float32 `json:"rank,omitempty"`
+	Snippet string   `json:"snippet,omitempty"`
+}
+
+// TutorPage is one page of tutor search results
+type TutorPage struct {
+	Tutors     []TutorResult `json:"tutors"`
+	Total      int           `json:"total"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// tutorSort describes how a sort option orders rows and encodes its cursor
+type tutorSort struct {
+	expr  string
+	cast  string
+	desc  bool
+	value func(TutorResult) string
+}
+
+func tutorCreatedValue(t TutorResult) string { return t.CreatedAt.Format(time.RFC3339Nano) }
+func tutorPayValue(t TutorResult) string     { return strconv.FormatFloat(t.Pay, 'f', -1, 64) }
+func tutorRatingValue(t TutorResult) string  { return strconv.FormatFloat(t.Rating, 'f', -1, 64) }
+func tutorRankValue(t TutorResult) string    { return strconv.FormatFloat(float64(*t.Rank), 'g', -1, 32) }
+
+// tutorSorts are the supported values of TutorFilter.Sort. "newest" is the
+// default, or "relevance" when searching by Query.
+var tutorSorts = map[string]tutorSort{
+	"newest":   {expr: "created_at", cast: "timestamptz", desc: true, value: tutorCreatedValue},
+	"oldest":   {expr: "created_at", cast: "timestamptz", value: tutorCreatedValue},
+	"pay_asc":  {expr: "pay", cast: "numeric", value: tutorPayValue},
+	"pay_desc": {expr: "pay", cast: "numeric", desc: true, value: tutorPayValue},
+	"rating":   {expr: "COALESCE(rating, 0)", cast: "numeric", desc: true, value: tutorRatingValue},
+	"name": {expr: "lower(name)", cast: "text", value: func(t TutorResult) string {
+		return strings.ToLower(t.Name)
+	}},
+	"relevance": {expr: "search_rank", cast: "real", desc: true, value: tutorRankValue},
+}
+
+// tutorSearchText is the text shown in snippets, escaped so it is safe to render as HTML
+const tutorSearchText = `replace(replace(replace(concat_ws(' … ', NULLIF(bio, ''), NULLIF(experience, ''), NULLIF(certification, '')),
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+
+// headlineOptions configures ts_headline snippets
+const headlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`
+
+// Validate checks the filter's sort and cursor, which Search would
+// otherwise fail on
+func (f TutorFilter) Validate() error {
+	if _, err := f.sortOrder(); err != nil {
+		return err
+	}
+	if f.Cursor != "" {
+		if _, err := decodeCursor(f.Cursor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandSubjects resolves the requested subjects through the taxonomy, so
+// each one also matches its aliases and descendants
+func (f *TutorFilter) expandSubjects(subjects SubjectRepository) error {
+	f.subjectGroups = nil
+	if len(f.Subjects) == 0 {
+		return nil
+	}
+
+	taxonomy, err := subjects.Taxonomy()
+	if err != nil {
+		return err
+	}
+	for _, subject := range lowerAll(f.Subjects) {
+		f.subjectGroups = append(f.subjectGroups, taxonomy.Expand(subject))
+	}
+	return nil
+}
+
+// conditions builds the WHERE clauses shared by searches and counts. When
+// searching by Query it also returns the tsquery expression to rank against.
+func (f TutorFilter) conditions() (*conditions, string) {
+	where := &conditions{}
+	where.add("deleted_at IS NULL")
+
+	var tsquery string
+	if query := strings.TrimSpace(f.Query); query != "" {
+		tsquery = "websearch_to_tsquery('english', " + where.arg(query) + ")"
+		where.add("search_vector @@ " + tsquery)
+	}
+
+	groups := f.subjectGroups
+	if groups == nil {
+		for _, subject := range lowerAll(f.Subjects) {
+			groups = append(groups, []string{subject})
+		}
+	}
+	if len(groups) > 0 {
+		if f.MatchAll {
+			for _, group := range groups {
+				where.add(fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(subjects) s WHERE lower(s) = ANY(%s))", where.arg(group)))
+			}
+		} else {
+			var names []string
+			for _, group := range groups {
+				names = append(names, group...)
+			}
+			where.add(fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(subjects) s WHERE lower(s) = ANY(%s))", where.arg(names)))
+		}
+	}
+	if f.MinPay != nil {
+		where.add("pay >= " + where.arg(*f.MinPay))
+	}
+	if f.MaxPay != nil {
+		where.add("pay <= " + where.arg(*f.MaxPay))
+	}
+	if f.MinRating != nil {
+		where.add("rating >= " + where.arg(*f.MinRating))
+	}
+	if f.Language != "" {
+		where.add("lower(language) = lower(" + where.arg(f.Language) + ")")
+	}
+	if f.Location != "" {
+		where.add("location ILIKE '%' || " + where.arg(f.Location) + " || '%'")
+	}
+	if f.Education != "" {
+		where.add("lower(education) = lower(" + where.arg(f.Education) + ")")
+	}
+	if f.AvailableAt != nil {
+		at := where.arg(*f.AvailableAt)
+		where.add(fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM availability_slots s
+			WHERE s.owner_type = 'tutor' AND s.owner_id = tutors.id
+			  AND minute_of_week(%s, s.timezone) >= s.start_minute_of_week
+			  AND minute_of_week(%s, s.timezone) < s.end_minute_of_week
+		)`, at, at))
+		where.add(fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM availability_exceptions e
+			WHERE e.owner_type = 'tutor' AND e.owner_id = tutors.id
+			  AND e.starts_at <= %s AND e.ends_at > %s
+		)`, at, at))
+	}
+
+	return where, tsquery
+}
+
+// sortOrder returns the sort option the filter asks for, defaulting to
+// "newest", or "relevance" when searching by Query
+func (f TutorFilter) sortOrder() (tutorSort, error) {
+	searching := strings.TrimSpace(f.Query) != ""
+
+	sortName := f.Sort
+	if sortName == "" {
+		sortName = "newest"
+		if searching {
+			sortName = "relevance"
+		}
+	}
+	order, ok := tutorSorts[sortName]
+	if !ok {
+		return tutorSort{}, fmt.Errorf("unsupported sort %q", f.Sort)
+	}
+	if sortName == "relevance" && !searching {
+		return tutorSort{}, fmt.Errorf("sorting by relevance requires a search query")
+	}
+	return order, nil
+}
+
+// pageLimit returns the requested page size within the allowed bounds
+func (f TutorFilter) pageLimit() int {
+	if f.Limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(f.Limit, MaxPageSize)
+}
+
+// Search returns one page of tutors matching the filter, along with
+// the total number of matches. Pages are keyset-paginated by the sort column
+// and ID, so NextCursor stays valid while rows are added.
+func (r *PostgresTutorRepository) Search(filter TutorFilter) (*TutorPage, error) {
+	if err := filter.expandSubjects(r.subjects); err != nil {
+		return nil, err
+	}
+	where, tsquery := filter.conditions()
+
+	order, err := filter.sortOrder()
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.pageLimit()
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM tutors ` + where.where()
+	if err := r.db.QueryRow(context.Background(), countQuery, where.args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	page := where.clone()
+	direction, comparison := "ASC", ">"
+	if order.desc {
+		direction, comparison = "DESC", "<"
+	}
+	cursorClause := ""
+	if filter.Cursor != "" {
+		position, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursorClause = fmt.Sprintf("WHERE (%s, id) %s (%s::%s, %s)",
+			order.expr, comparison, page.arg(position.Value), order.cast, page.arg(position.ID))
+	}
+
+	// Rank and snippet columns are only computed for full-text searches
+	searchColumns := "NULL::real AS search_rank, ''::text AS snippet"
+	if tsquery != "" {
+		searchColumns = fmt.Sprintf("ts_rank_cd(search_vector, %s) AS search_rank, ts_headline('english', %s, %s, '%s') AS snippet",
+			tsquery, tutorSearchText, tsquery, headlineOptions)
+	}
+
+	// Wrap the select so the cursor condition can refer to search_rank
+	query := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT %s, %s
+			FROM tutors
+			%s
+		) results
+		%s
+		ORDER BY %s %s, id %s
+		LIMIT %s
+	`, tutorColumns, searchColumns, where.where(), cursorClause, order.expr, direction, direction, page.arg(limit+1))
+
+	rows, err := r.db.Query(context.Background(), query, page.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tutors := []TutorResult{}
+	for rows.Next() {
+		var result TutorResult
+		result.Tutor, err = scanTutor(searchRow{rows, &result.Rank, &result.Snippet})
+		if err != nil {
+			return nil, err
+		}
+		tutors = append(tutors, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &TutorPage{Tutors: tutors, Total: total}
+	if len(tutors) > limit {
+		result.Tutors = tutors[:limit]
+		last := result.Tutors[limit-1]
+		result.NextCursor = encodeCursor(order.value(last), last.ID)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,226 @@
+// Package payments collects payments through a Stripe-style provider. It
+// creates checkout intents over the provider's API and verifies the signed
+// webhooks the provider sends as payments settle.
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the provider's API
+const DefaultAPIURL = "https://api.stripe.com"
+
+// Webhook event types
+const (
+	EventIntentSucceeded = "payment_intent.succeeded"
+	EventIntentFailed    = "payment_intent.payment_failed"
+	EventChargeRefunded  = "charge.refunded"
+)
+
+var (
+	// ErrInvalidSignature is returned for webhooks that are unsigned, forged
+	// or too old
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+
+	// ErrProvider is returned when the provider rejects a request or cannot
+	// be reached
+	ErrProvider = errors.New("payment provider error")
+)
+
+// Config configures a Client
+type Config struct {
+	// APIKey authenticates API requests
+	APIKey string
+
+	// WebhookSecret signs the webhooks the provider sends
+	WebhookSecret string
+
+	// APIURL is the provider's API; defaults to DefaultAPIURL
+	APIURL string
+
+	// HTTPClient makes API requests; defaults to a client with a short timeout
+	HTTPClient *http.Client
+
+	// Now returns the current time; defaults to time.Now
+	Now func() time.Time
+}
+
+// ConfigFromEnv reads PAYMENTS_API_KEY, PAYMENTS_WEBHOOK_SECRET and the
+// optional PAYMENTS_API_URL
+func ConfigFromEnv() Config {
+	return Config{
+		APIKey:        os.Getenv("PAYMENTS_API_KEY"),
+		WebhookSecret: os.Getenv("PAYMENTS_WEBHOOK_SECRET"),
+		APIURL:        os.Getenv("PAYMENTS_API_URL"),
+	}
+}
+
+// Provider starts checkouts and verifies webhooks
+type Provider interface {
+	// CreateIntent asks the provider to collect an amount
+	CreateIntent(ctx context.Context, request IntentRequest) (*Intent, error)
+	// ParseWebhook verifies a webhook's signature header and decodes its event
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// IntentRequest asks to collect an amount in minor units for an invoice.
+// Requests with the same IdempotencyKey return the same intent.
+type IntentRequest struct {
+	Amount         int64
+	Currency       string
+	InvoiceID      int
+	ClientID       int
+	IdempotencyKey string
+}
+
+// Intent is a checkout at the provider. The client secret lets the frontend
+// complete the payment; CheckoutURL is set by providers with a hosted page.
+type Intent struct {
+	ID           string            `json:"id"`
+	Amount       int64             `json:"amount"`
+	Currency     string            `json:"currency"`
+	Status       string            `json:"status"`
+	ClientSecret string            `json:"client_secret"`
+	CheckoutURL  string            `json:"url,omitempty"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// Event is a webhook event. Its object is the payment intent, or for
+// refunds the charge, whose AmountRefunded is the running total refunded.
+type Event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object EventObject `json:"object"`
+	} `json:"data"`
+}
+
+// EventObject is the object an event is about
+type EventObject struct {
+	ID             string            `json:"id"`
+	Object         string            `json:"object"`
+	Amount         int64             `json:"amount"`
+	AmountRefunded int64             `json:"amount_refunded"`
+	Currency       string            `json:"currency"`
+	Status         string            `json:"status"`
+	PaymentIntent  string            `json:"payment_intent,omitempty"`
+	Metadata       map[string]string `json:"metadata"`
+}
+
+// IntentID returns the ID of the payment intent the event is about
+func (e *Event) IntentID() string {
+	if object := e.Data.Object; object.PaymentIntent != "" {
+		return object.PaymentIntent
+	}
+	return e.Data.Object.ID
+}
+
+// Client is a Provider speaking the Stripe API
+type Client struct {
+	apiKey        string
+	webhookSecret string
+	apiURL        string
+	http          *http.Client
+	now           func() time.Time
+}
+
+// NewClient returns a client for the configured account
+func NewClient(config Config) (*Client, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("PAYMENTS_API_KEY is not set")
+	}
+	if config.WebhookSecret == "" {
+		return nil, fmt.Errorf("PAYMENTS_WEBHOOK_SECRET is not set")
+	}
+	if config.APIURL == "" {
+		config.APIURL = DefaultAPIURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &Client{
+		apiKey:        config.APIKey,
+		webhookSecret: config.WebhookSecret,
+		apiURL:        strings.TrimSuffix(config.APIURL, "/"),
+		http:          config.HTTPClient,
+		now:           config.Now,
+	}, nil
+}
+
+// CreateIntent creates a payment intent tagged with the invoice and client
+func (c *Client) CreateIntent(ctx context.Context, request IntentRequest) (*Intent, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(request.Amount, 10))
+	form.Set("currency", request.Currency)
+	form.Set("metadata[invoice_id]", strconv.Itoa(request.InvoiceID))
+	form.Set("metadata[client_id]", strconv.Itoa(request.ClientID))
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/v1/payment_intents", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+c.apiKey)
+	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if request.IdempotencyKey != "" {
+		httpRequest.Header.Set("Idempotency-Key", request.IdempotencyKey)
+	}
+
+	response, err := c.http.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &failure) == nil && failure.Error.Message != "" {
+			return nil, fmt.Errorf("%w: %s", ErrProvider, failure.Error.Message)
+		}
+		return nil, fmt.Errorf("%w: status %d", ErrProvider, response.StatusCode)
+	}
+
+	var intent Intent
+	if err := json.Unmarshal(body, &intent); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	return &intent, nil
+}
+
+// ParseWebhook verifies a webhook's signature header and decodes its event
+func (c *Client) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	if err := VerifySignature(payload, signature, c.webhookSecret, c.now()); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("malformed event: %v", err)
+	}
+	if event.ID == "" || event.Type == "" {
+		return nil, fmt.Errorf("malformed event: missing id or type")
+	}
+	return &event, nil
+}
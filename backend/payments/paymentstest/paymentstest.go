@@ -0,0 +1,319 @@
+// Package paymentstest is a local stand-in for a Stripe-style payment
+// provider. It serves the payment intent API over HTTP, settles intents on
+// demand and delivers signed webhooks, so the checkout flow can be exercised
+// without reaching the provider.
+package paymentstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tutor-backend/payments"
+)
+
+// Intent statuses, as the provider reports them
+const (
+	StatusRequiresPayment = "requires_payment_method"
+	StatusSucceeded       = "succeeded"
+	StatusCanceled        = "canceled"
+)
+
+// Provider is a fake payment provider. Webhooks go to WebhookURL, which can
+// be set once the server receiving them is running.
+type Provider struct {
+	APIKey        string
+	WebhookSecret string
+	WebhookURL    string
+	Server        *httptest.Server
+
+	mu         sync.Mutex
+	intents    map[string]*payments.Intent
+	refunded   map[string]int64
+	idempotent map[string]string
+	events     map[string][]byte
+	count      int
+}
+
+// NewProvider starts a fake provider sending webhooks to webhookURL; call
+// Close when done
+func NewProvider(webhookURL string) *Provider {
+	provider := &Provider{
+		APIKey:        "sk_test_paymentstest",
+		WebhookSecret: "whsec_paymentstest",
+		WebhookURL:    webhookURL,
+		intents:       map[string]*payments.Intent{},
+		refunded:      map[string]int64{},
+		idempotent:    map[string]string{},
+		events:        map[string][]byte{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/payment_intents", provider.createIntent)
+	mux.HandleFunc("GET /v1/payment_intents/{id}", provider.getIntent)
+	mux.HandleFunc("GET /checkout/{id}", provider.checkoutPage)
+	mux.HandleFunc("POST /checkout/{id}/pay", provider.checkoutAction(provider.Succeed))
+	mux.HandleFunc("POST /checkout/{id}/decline", provider.checkoutAction(provider.Fail))
+	provider.Server = httptest.NewServer(mux)
+	return provider
+}
+
+// Close stops the provider
+func (p *Provider) Close() {
+	p.Server.Close()
+}
+
+// Config returns a client configuration for this provider
+func (p *Provider) Config() payments.Config {
+	return payments.Config{
+		APIKey:        p.APIKey,
+		WebhookSecret: p.WebhookSecret,
+		APIURL:        p.Server.URL,
+		HTTPClient:    p.Server.Client(),
+	}
+}
+
+// Intent returns a copy of an intent, or nil if there is none with the ID
+func (p *Provider) Intent(id string) *payments.Intent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[id]
+	if !ok {
+		return nil
+	}
+	copied := *intent
+	return &copied
+}
+
+// Succeed settles an intent and delivers a payment_intent.succeeded webhook,
+// returning the event's ID
+func (p *Provider) Succeed(intentID string) (string, error) {
+	return p.settle(intentID, StatusSucceeded, payments.EventIntentSucceeded)
+}
+
+// Fail declines an intent's payment and delivers a
+// payment_intent.payment_failed webhook, returning the event's ID
+func (p *Provider) Fail(intentID string) (string, error) {
+	return p.settle(intentID, StatusRequiresPayment, payments.EventIntentFailed)
+}
+
+// settle moves an intent to a status and delivers an event about it
+func (p *Provider) settle(intentID, status, eventType string) (string, error) {
+	p.mu.Lock()
+	intent, ok := p.intents[intentID]
+	if !ok {
+		p.mu.Unlock()
+		return "", fmt.Errorf("no intent %q", intentID)
+	}
+	if intent.Status == StatusSucceeded {
+		p.mu.Unlock()
+		return "", fmt.Errorf("intent %q has already succeeded", intentID)
+	}
+	intent.Status = status
+	object := payments.EventObject{
+		ID:       intent.ID,
+		Object:   "payment_intent",
+		Amount:   intent.Amount,
+		Currency: intent.Currency,
+		Status:   intent.Status,
+		Metadata: intent.Metadata,
+	}
+	p.mu.Unlock()
+
+	return p.emit(eventType, object)
+}
+
+// Refund refunds part or all of a succeeded intent and delivers a
+// charge.refunded webhook, returning the event's ID
+func (p *Provider) Refund(intentID string, amount int64) (string, error) {
+	p.mu.Lock()
+	intent, ok := p.intents[intentID]
+	if !ok || intent.Status != StatusSucceeded {
+		p.mu.Unlock()
+		return "", fmt.Errorf("no succeeded intent %q", intentID)
+	}
+	if amount <= 0 || p.refunded[intentID]+amount > intent.Amount {
+		p.mu.Unlock()
+		return "", fmt.Errorf("refund of %d exceeds what is left of intent %q", amount, intentID)
+	}
+	p.refunded[intentID] += amount
+	object := payments.EventObject{
+		ID:             "ch_" + strings.TrimPrefix(intent.ID, "pi_"),
+		Object:         "charge",
+		Amount:         intent.Amount,
+		AmountRefunded: p.refunded[intentID],
+		Currency:       intent.Currency,
+		Status:         StatusSucceeded,
+		PaymentIntent:  intent.ID,
+		Metadata:       intent.Metadata,
+	}
+	p.mu.Unlock()
+
+	return p.emit(payments.EventChargeRefunded, object)
+}
+
+// Resend delivers an event again, freshly signed, as providers do when an
+// earlier delivery was not acknowledged
+func (p *Provider) Resend(eventID string) error {
+	p.mu.Lock()
+	payload, ok := p.events[eventID]
+	p.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no event %q", eventID)
+	}
+	return p.deliver(payload)
+}
+
+// emit records an event about the object and delivers it
+func (p *Provider) emit(eventType string, object payments.EventObject) (string, error) {
+	p.mu.Lock()
+	p.count++
+	event := payments.Event{ID: fmt.Sprintf("evt_test_%d", p.count), Type: eventType, Created: time.Now().Unix()}
+	event.Data.Object = object
+	payload, err := json.Marshal(event)
+	if err != nil {
+		p.mu.Unlock()
+		return "", err
+	}
+	p.events[event.ID] = payload
+	p.mu.Unlock()
+
+	return event.ID, p.deliver(payload)
+}
+
+// deliver posts a signed webhook, failing unless it is acknowledged with a 2xx
+func (p *Provider) deliver(payload []byte) error {
+	request, err := http.NewRequest(http.MethodPost, p.WebhookURL, strings.NewReader(string(payload)))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(payments.SignatureHeader, payments.Sign(payload, p.WebhookSecret, time.Now()))
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook was answered with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error in the provider's format
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]string{"message": message}})
+}
+
+// createIntent serves POST /v1/payment_intents
+func (p *Provider) createIntent(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+p.APIKey {
+		writeError(w, http.StatusUnauthorized, "Invalid API key provided")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	amount, err := strconv.ParseInt(r.PostForm.Get("amount"), 10, 64)
+	if err != nil || amount <= 0 {
+		writeError(w, http.StatusBadRequest, "amount must be a positive integer")
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := r.Header.Get("Idempotency-Key")
+	if id, ok := p.idempotent[key]; ok && key != "" {
+		writeJSON(w, http.StatusOK, p.intents[id])
+		return
+	}
+
+	metadata := map[string]string{}
+	for name, values := range r.PostForm {
+		if field, ok := strings.CutPrefix(name, "metadata["); ok && strings.HasSuffix(field, "]") {
+			metadata[strings.TrimSuffix(field, "]")] = values[0]
+		}
+	}
+
+	p.count++
+	id := fmt.Sprintf("pi_test_%d", p.count)
+	intent := &payments.Intent{
+		ID:           id,
+		Amount:       amount,
+		Currency:     r.PostForm.Get("currency"),
+		Status:       StatusRequiresPayment,
+		ClientSecret: id + "_secret",
+		CheckoutURL:  p.Server.URL + "/checkout/" + id,
+		Metadata:     metadata,
+	}
+	p.intents[id] = intent
+	if key != "" {
+		p.idempotent[key] = id
+	}
+	writeJSON(w, http.StatusOK, intent)
+}
+
+// getIntent serves GET /v1/payment_intents/{id}
+func (p *Provider) getIntent(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+p.APIKey {
+		writeError(w, http.StatusUnauthorized, "Invalid API key provided")
+		return
+	}
+	intent := p.Intent(r.PathValue("id"))
+	if intent == nil {
+		writeError(w, http.StatusNotFound, "No such payment_intent")
+		return
+	}
+	writeJSON(w, http.StatusOK, intent)
+}
+
+// checkoutTemplate is the hosted checkout page
+var checkoutTemplate = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Test checkout</title></head>
+<body>
+<h1>Pay {{.Amount}} {{.Currency}} (minor units)</h1>
+<p>Intent {{.ID}} is {{.Status}}.</p>
+<form method="post" action="/checkout/{{.ID}}/pay"><button>Pay</button></form>
+<form method="post" action="/checkout/{{.ID}}/decline"><button>Decline</button></form>
+</body>
+</html>
+`))
+
+// checkoutPage serves GET /checkout/{id}, a page to pay or decline an intent by hand
+func (p *Provider) checkoutPage(w http.ResponseWriter, r *http.Request) {
+	intent := p.Intent(r.PathValue("id"))
+	if intent == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	checkoutTemplate.Execute(w, intent)
+}
+
+// checkoutAction serves the checkout page's buttons
+func (p *Provider) checkoutAction(settle func(string) (string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, err := settle(r.PathValue("id")); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Redirect(w, r, "/checkout/"+r.PathValue("id"), http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,78 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries a webhook's signature
+const SignatureHeader = "Stripe-Signature"
+
+// signatureTolerance is how old a webhook's timestamp may be, which bounds
+// how long a captured webhook can be replayed
+const signatureTolerance = 5 * time.Minute
+
+// Sign returns the signature header for a payload sent at a time: the Unix
+// timestamp and the hex HMAC-SHA256 of "timestamp.payload" keyed with the
+// webhook secret, as "t=...,v1=..."
+func Sign(payload []byte, secret string, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + computeSignature(payload, secret, timestamp)
+}
+
+// computeSignature returns the hex HMAC-SHA256 of "timestamp.payload"
+func computeSignature(payload []byte, secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a signature header against the payload. Any of
+// several v1 signatures may match, as they do while a secret is rolled.
+func VerifySignature(payload []byte, header, secret string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return invalid("malformed signature header")
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return invalid("malformed timestamp")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return invalid("timestamp outside the tolerance")
+	}
+
+	expected := []byte(computeSignature(payload, secret, timestamp))
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+	return invalid("no matching signature")
+}
+
+// invalid wraps ErrInvalidSignature with a reason
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSignature, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,56 @@
+package payments
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded"}`)
+	secret := "whsec_test"
+	now := time.Unix(1_800_000_000, 0)
+	signed := Sign(payload, secret, now)
+
+	valid := map[string]struct {
+		header string
+		at     time.Time
+	}{
+		"fresh":                  {signed, now},
+		"within tolerance":       {signed, now.Add(4 * time.Minute)},
+		"rolled secret":          {signed + ",v1=" + strings.Repeat("0", 64), now},
+		"old secret listed last": {"t=1800000000,v1=" + strings.Repeat("0", 64) + "," + strings.Split(signed, ",")[1], now},
+	}
+	for name, test := range valid {
+		t.Run(name, func(t *testing.T) {
+			if err := VerifySignature(payload, test.header, secret, test.at); err != nil {
+				t.Errorf("VerifySignature: %v", err)
+			}
+		})
+	}
+
+	invalid := map[string]struct {
+		payload []byte
+		header  string
+		secret  string
+		at      time.Time
+	}{
+		"wrong secret":       {payload, Sign(payload, "whsec_other", now), secret, now},
+		"tampered payload":   {[]byte(`{"id":"evt_2"}`), signed, secret, now},
+		"stale timestamp":    {payload, signed, secret, now.Add(6 * time.Minute)},
+		"future timestamp":   {payload, signed, secret, now.Add(-6 * time.Minute)},
+		"missing header":     {payload, "", secret, now},
+		"missing signature":  {payload, "t=1800000000", secret, now},
+		"malformed time":     {payload, "t=soon,v1=" + strings.Split(signed, "v1=")[1], secret, now},
+		"unsigned timestamp": {payload, strings.Replace(signed, "t=1800000000", "t=1800000060", 1), secret, now},
+	}
+	for name, test := range invalid {
+		t.Run(name, func(t *testing.T) {
+			err := VerifySignature(test.payload, test.header, test.secret, test.at)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifySignature error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
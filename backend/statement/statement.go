@@ -0,0 +1,206 @@
+// Package statement renders payout statements as CSV and PDF documents
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Document is a payout statement as it is rendered
+type Document struct {
+	Number         int
+	Payee          string
+	Period         string
+	Currency       string
+	Status         string
+	Issued         time.Time
+	Lines          []Line
+	Gross          int64
+	CommissionRate int64
+	Commission     int64
+	Net            int64
+}
+
+// Line is one paid session on a statement
+type Line struct {
+	SessionID   int
+	InvoiceID   int
+	Description string
+	Minutes     int
+	Amount      int64
+}
+
+// FormatAmount formats minor units as a decimal amount, e.g. 1205 as "12.05"
+func FormatAmount(minor int64) string {
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
+}
+
+// FormatRate formats basis points as a percentage, e.g. 1250 as "12.5%"
+func FormatRate(basisPoints int64) string {
+	return strconv.FormatFloat(float64(basisPoints)/100, 'f', -1, 64) + "%"
+}
+
+// Filename returns the download name of the statement with an extension
+func (d *Document) Filename(extension string) string {
+	return fmt.Sprintf("payout-statement-%s-%d.%s", d.Period, d.Number, extension)
+}
+
+// csvText neutralises text that spreadsheets would read as a formula by
+// prefixing it with an apostrophe
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// EncodeCSV renders the statement as a CSV table of its sessions followed by
+// the gross, commission and net rows. Descriptions that start like a formula
+// are escaped; amounts are left as numbers.
+func EncodeCSV(d *Document) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	currency := strings.ToUpper(d.Currency)
+	records := [][]string{{"session_id", "invoice_id", "description", "minutes", "amount_" + strings.ToLower(d.Currency)}}
+	for _, line := range d.Lines {
+		records = append(records, []string{
+			strconv.Itoa(line.SessionID),
+			strconv.Itoa(line.InvoiceID),
+			csvText(line.Description),
+			strconv.Itoa(line.Minutes),
+			FormatAmount(line.Amount),
+		})
+	}
+	records = append(records,
+		[]string{"", "", "Gross " + currency, "", FormatAmount(d.Gross)},
+		[]string{"", "", "Commission " + FormatRate(d.CommissionRate), "", FormatAmount(-d.Commission)},
+		[]string{"", "", "Net " + currency, "", FormatAmount(d.Net)},
+	)
+
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// PDF page layout, in points on a US Letter page
+const (
+	pageWidth    = 612
+	pageHeight   = 792
+	margin       = 54
+	fontSize     = 10
+	lineHeight   = 14
+	linesPerPage = (pageHeight - 2*margin) / lineHeight
+)
+
+// textLines lays the statement out as fixed-width lines of text
+func textLines(d *Document) []string {
+	currency := strings.ToUpper(d.Currency)
+	lines := []string{
+		fmt.Sprintf("Payout statement %d", d.Number),
+		"",
+		"Tutor:   " + d.Payee,
+		"Period:  " + d.Period,
+		"Issued:  " + d.Issued.UTC().Format("2006-01-02"),
+		"Status:  " + d.Status,
+		"",
+		fmt.Sprintf("%-8s %-8s %-44s %7s %12s", "Session", "Invoice", "Description", "Minutes", "Amount "+currency),
+		strings.Repeat("-", 83),
+	}
+	for _, line := range d.Lines {
+		description := line.Description
+		if len(description) > 44 {
+			description = description[:41] + "..."
+		}
+		lines = append(lines, fmt.Sprintf("%-8d %-8d %-44s %7d %12s",
+			line.SessionID, line.InvoiceID, description, line.Minutes, FormatAmount(line.Amount)))
+	}
+	return append(lines,
+		strings.Repeat("-", 83),
+		fmt.Sprintf("%-70s %12s", "Gross", FormatAmount(d.Gross)),
+		fmt.Sprintf("%-70s %12s", "Commission "+FormatRate(d.CommissionRate), FormatAmount(-d.Commission)),
+		fmt.Sprintf("%-70s %12s", "Net payout", FormatAmount(d.Net)),
+	)
+}
+
+// escapePDFText escapes a string for a PDF literal, replacing characters
+// outside printable ASCII, which the built-in fonts cannot show
+func escapePDFText(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r < ' ' || r > '~':
+			escaped.WriteByte('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
+
+// EncodePDF renders the statement as a plain PDF in a monospaced font,
+// paginated as needed
+func EncodePDF(d *Document) []byte {
+	lines := textLines(d)
+	var pages [][]string
+	for len(lines) > 0 {
+		count := min(len(lines), linesPerPage)
+		pages = append(pages, lines[:count])
+		lines = lines[count:]
+	}
+
+	// Objects 1-3 are the catalog, page tree and font; each page then takes
+	// a page object and a content stream
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	)
+	for i, page := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDFText(line))
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buffer.Len()
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buffer.Bytes()
+}
@@ -0,0 +1,122 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// document returns a statement with the given number of session lines
+func document(lines int) *Document {
+	d := &Document{
+		Number:         7,
+		Payee:          "Ada (Lovelace)",
+		Period:         "2026-03",
+		Currency:       "usd",
+		Status:         "pending",
+		Issued:         time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		CommissionRate: 1500,
+	}
+	for i := 0; i < lines; i++ {
+		d.Lines = append(d.Lines, Line{SessionID: i + 1, InvoiceID: 1, Description: "Math", Minutes: 60, Amount: 4000})
+		d.Gross += 4000
+	}
+	d.Commission = d.Gross * 15 / 100
+	d.Net = d.Gross - d.Commission
+	return d
+}
+
+func TestEncodeCSVEscapesFormulas(t *testing.T) {
+	d := document(0)
+	descriptions := map[string]string{
+		"=HYPERLINK(\"http://x\")": "'=HYPERLINK(\"http://x\")",
+		"+1":                       "'+1",
+		"-2+3":                     "'-2+3",
+		"@SUM(A1)":                 "'@SUM(A1)",
+		"Math on 2026-03-02":       "Math on 2026-03-02",
+	}
+	for description := range descriptions {
+		d.Lines = append(d.Lines, Line{SessionID: 1, InvoiceID: 1, Description: description, Minutes: -60, Amount: -4000})
+	}
+
+	encoded, err := EncodeCSV(d)
+	if err != nil {
+		t.Fatalf("EncodeCSV: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(encoded)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading the CSV back: %v", err)
+	}
+	if len(records) != 1+len(descriptions)+3 {
+		t.Fatalf("got %d records, want a header, %d lines and 3 totals", len(records), len(descriptions))
+	}
+	if got := strings.Join(records[0], ","); got != "session_id,invoice_id,description,minutes,amount_usd" {
+		t.Errorf("header = %q", got)
+	}
+	for i, line := range d.Lines {
+		record := records[1+i]
+		if want := descriptions[line.Description]; record[2] != want {
+			t.Errorf("description %q encoded as %q, want %q", line.Description, record[2], want)
+		}
+		if record[4] != "-40.00" {
+			t.Errorf("amount = %q, want the number -40.00 unescaped", record[4])
+		}
+	}
+	if got := records[len(records)-2]; got[2] != "Commission 15%" {
+		t.Errorf("commission row = %q", got)
+	}
+}
+
+func TestEncodePDF(t *testing.T) {
+	objectPattern := regexp.MustCompile(`(?m)^(\d+) 0 obj$`)
+	tests := []struct {
+		name  string
+		lines int
+		pages int
+	}{
+		{"one page", 3, 1},
+		{"several pages", 2 * linesPerPage, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf := EncodePDF(document(tt.lines))
+			if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+				t.Fatalf("missing PDF header or trailer")
+			}
+			if want := fmt.Sprintf("/Count %d", tt.pages); !bytes.Contains(pdf, []byte(want)) {
+				t.Errorf("page tree does not contain %q", want)
+			}
+			if got := bytes.Count(pdf, []byte("/Type /Page /Parent")); got != tt.pages {
+				t.Errorf("got %d pages, want %d", got, tt.pages)
+			}
+			if !bytes.Contains(pdf, []byte(`Ada \(Lovelace\)`)) {
+				t.Errorf("payee is not escaped in the content stream")
+			}
+
+			// Every xref entry must point at its object
+			text := string(pdf)
+			startxref := text[strings.LastIndex(text, "startxref\n")+len("startxref\n"):]
+			xref, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(startxref), "%%EOF")))
+			if err != nil || !strings.HasPrefix(text[xref:], "xref\n") {
+				t.Fatalf("startxref %q does not point at the xref table", startxref)
+			}
+			objects := objectPattern.FindAllStringSubmatchIndex(text, -1)
+			entries := strings.Split(text[xref:], "\n")[3:]
+			if want := 3 + 2*tt.pages; len(objects) != want {
+				t.Fatalf("got %d objects, want %d", len(objects), want)
+			}
+			for i, object := range objects {
+				offset, err := strconv.Atoi(entries[i][:10])
+				if err != nil || offset != object[0] {
+					t.Errorf("xref entry %d = %q, want offset %d", i+1, entries[i], object[0])
+				}
+			}
+		})
+	}
+}
@@ -36,7 +36,7 @@ const AdminDashboard: React.FC = () => {
     try {
       setLoading(true);
       const [statsResponse, tutorsResponse, clientsResponse] = await Promise.all([
-        apiService.getAdminStats(currentUser.email),
+        apiService.getAdminStats(),
         apiService.getTutors(),
         apiService.getClients(),
       ]);
@@ -66,7 +66,7 @@ const AdminDashboard: React.FC = () => {
     if (!currentUser?.email || !editingTutor) return;
 
     try {
-      await apiService.updateTutor(editingTutor, editData, currentUser.email);
+      await apiService.updateTutor(editingTutor, editData);
       setEditingTutor(null);
       setEditData({});
       loadAdminData();
@@ -80,7 +80,7 @@ const AdminDashboard: React.FC = () => {
     if (!currentUser?.email || !editingClient) return;
 
     try {
-      await apiService.updateClient(editingClient, editData, currentUser.email);
+      await apiService.updateClient(editingClient, editData);
       setEditingClient(null);
       setEditData({});
       loadAdminData();
@@ -94,7 +94,7 @@ const AdminDashboard: React.FC = () => {
     if (!currentUser?.email || !window.confirm('Are you sure you want to delete this tutor?')) return;
 
     try {
-      await apiService.deleteTutor(id, currentUser.email);
+      await apiService.deleteTutor(id);
       loadAdminData();
     } catch (err) {
       setError('Failed to delete tutor');
@@ -106,7 +106,7 @@ const AdminDashboard: React.FC = () => {
     if (!currentUser?.email || !window.confirm('Are you sure you want to delete this client?')) return;
 
     try {
-      await apiService.deleteClient(id, currentUser.email);
+      await apiService.deleteClient(id);
       loadAdminData();
     } catch (err) {
       setError('Failed to delete client');
@@ -62,7 +62,8 @@ const CreateClientForm: React.FC<CreateClientFormProps> = ({ onProfileCompleted
         language: formData.language,
         location: formData.location,
         availability: formData.availability,
-        education: formData.education
+        education: formData.education,
+        timezone: Intl.DateTimeFormat().resolvedOptions().timeZone
       };
 
       const response = await apiService.createClient(clientData);
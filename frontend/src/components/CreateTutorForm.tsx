@@ -68,7 +68,8 @@ const CreateTutorForm: React.FC<CreateTutorFormProps> = ({ onProfileCompleted })
         availability: formData.availability,
         experience: formData.experience,
         education: formData.education,
-        certification: formData.certification
+        certification: formData.certification,
+        timezone: Intl.DateTimeFormat().resolvedOptions().timeZone
       };
 
       const response = await apiService.createTutor(tutorData);
@@ -38,19 +38,18 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
   const [isAdmin, setIsAdmin] = useState(false);
   const [loading, setLoading] = useState(true);
 
-  // Admin email list - you can configure this or move to environment variables
-  const adminEmails: string[] = import.meta.env.VITE_ADMIN_EMAILS?.split(',') || [];
-
-  const checkAdminStatus = (email: string | null) => {
-    if (!email) {
+  // Admin access comes from the roles granted on the server
+  const checkAdminStatus = async () => {
+    try {
+      const response = await apiService.getMyAccess();
+      const adminStatus = response.data.permissions.includes('stats:read');
+      setIsAdmin(adminStatus);
+      return adminStatus;
+    } catch (error) {
+      console.error('Error checking admin status:', error);
       setIsAdmin(false);
       return false;
     }
-    const adminStatus = adminEmails.some(adminEmail => 
-      email.toLowerCase() === adminEmail.toLowerCase()
-    );
-    setIsAdmin(adminStatus);
-    return adminStatus;
   };
 
   const login = async () => {
@@ -96,7 +95,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
     if (!user.email) return;
 
     try {
-      const profileCheck = await apiService.checkUserProfile(user.email);
+      const profileCheck = await apiService.checkUserProfile();
       
       if (profileCheck.hasProfile) {
         // User has an existing profile, set the state accordingly
@@ -135,7 +134,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
       
       if (user) {
         // Check admin status
-        checkAdminStatus(user.email);
+        await checkAdminStatus();
         
         // Check for existing profile in database
         await checkExistingProfile(user);
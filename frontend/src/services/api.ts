@@ -1,3 +1,5 @@
+import { auth } from '../firebase/config';
+
 export interface Tutor {
   id?: number;
   name: string;
@@ -12,6 +14,7 @@ export interface Tutor {
   experience: string;
   education: string;
   certification: string;
+  timezone?: string;
 }
 
 export interface Client {
@@ -25,6 +28,13 @@ export interface Client {
   location: string;
   availability: string;
   education: string;
+  timezone?: string;
+}
+
+export interface UserAccess {
+  email: string;
+  roles: string[];
+  permissions: string[];
 }
 
 export interface ApiResponse<T> {
@@ -34,49 +44,29 @@ export interface ApiResponse<T> {
 }
 
 class ApiService {
-  private async request<T>(
-    endpoint: string,
-    options: RequestInit = {}
-  ): Promise<ApiResponse<T>> {
-    const url = `${import.meta.env.VITE_API_BASE_URL}${endpoint}`;
-    
-    const config: RequestInit = {
-      headers: {
-        'Content-Type': 'application/json',
-        ...options.headers,
-      },
-      ...options,
-    };
-
-    try {
-      const response = await fetch(url, config);
-      
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`);
-      }
-      
-      const data = await response.json();
-      return data;
-    } catch (error) {
-      console.error('API request failed:', error);
-      throw error;
+  // Firebase ID token of the signed-in user, refreshed by the SDK when it expires
+  private async authHeaders(): Promise<Record<string, string>> {
+    const user = auth.currentUser;
+    if (!user) {
+      return {};
     }
+    const token = await user.getIdToken();
+    return { Authorization: `Bearer ${token}` };
   }
 
-  private async adminRequest<T>(
+  private async request<T>(
     endpoint: string,
-    userEmail: string,
     options: RequestInit = {}
   ): Promise<ApiResponse<T>> {
     const url = `${import.meta.env.VITE_API_BASE_URL}${endpoint}`;
     
     const config: RequestInit = {
+      ...options,
       headers: {
         'Content-Type': 'application/json',
-        'X-User-Email': userEmail,
+        ...(await this.authHeaders()),
         ...options.headers,
       },
-      ...options,
     };
 
     try {
@@ -89,7 +79,7 @@ class ApiService {
       const data = await response.json();
       return data;
     } catch (error) {
-      console.error('Admin API request failed:', error);
+      console.error('API request failed:', error);
       throw error;
     }
   }
@@ -106,9 +96,15 @@ class ApiService {
     });
   }
 
-  async getTutorByEmail(email: string): Promise<ApiResponse<Tutor | null>> {
-    const encodedEmail = encodeURIComponent(email);
-    return this.request<Tutor | null>(`/tutors/by-email/${encodedEmail}`);
+  async getMyTutor(): Promise<ApiResponse<Tutor | null>> {
+    return this.request<Tutor | null>('/me/tutor');
+  }
+
+  async updateMyTutor(changes: Partial<Omit<Tutor, 'id' | 'email' | 'rating'>>): Promise<ApiResponse<Tutor>> {
+    return this.request<Tutor>('/me/tutor', {
+      method: 'PATCH',
+      body: JSON.stringify(changes),
+    });
   }
 
   // Client endpoints
@@ -123,20 +119,30 @@ class ApiService {
     });
   }
 
-  async getClientByEmail(email: string): Promise<ApiResponse<Client | null>> {
-    const encodedEmail = encodeURIComponent(email);
-    return this.request<Client | null>(`/clients/by-email/${encodedEmail}`);
+  async getMyClient(): Promise<ApiResponse<Client | null>> {
+    return this.request<Client | null>('/me/client');
+  }
+
+  async updateMyClient(changes: Partial<Omit<Client, 'id' | 'email'>>): Promise<ApiResponse<Client>> {
+    return this.request<Client>('/me/client', {
+      method: 'PATCH',
+      body: JSON.stringify(changes),
+    });
+  }
+
+  async getMyAccess(): Promise<ApiResponse<UserAccess>> {
+    return this.request<UserAccess>('/me/access');
   }
 
-  // Check if user has any existing profile
-  async checkUserProfile(email: string): Promise<{ 
+  // Check if the signed-in user has any existing profile
+  async checkUserProfile(): Promise<{ 
     hasProfile: boolean; 
     userType: 'tutor' | 'student' | null;
     profileData: Tutor | Client | null;
   }> {
     try {
       // Check for client profile first
-      const clientResponse = await this.getClientByEmail(email);
+      const clientResponse = await this.getMyClient();
       if (clientResponse.data) {
         return {
           hasProfile: true,
@@ -146,7 +152,7 @@ class ApiService {
       }
 
       // Check for tutor profile
-      const tutorResponse = await this.getTutorByEmail(email);
+      const tutorResponse = await this.getMyTutor();
       if (tutorResponse.data) {
         return {
           hasProfile: true,
@@ -177,40 +183,40 @@ class ApiService {
   }
 
   // Admin endpoints
-  async getAdminStats(userEmail: string): Promise<ApiResponse<{
+  async getAdminStats(): Promise<ApiResponse<{
     tutors_count: number;
     clients_count: number;
     total_users: number;
   }>> {
-    return this.adminRequest<{
+    return this.request<{
       tutors_count: number;
       clients_count: number;
       total_users: number;
-    }>('/admin/stats', userEmail);
+    }>('/admin/stats');
   }
 
-  async updateTutor(id: number, tutor: Omit<Tutor, 'id'>, userEmail: string): Promise<ApiResponse<Tutor>> {
-    return this.adminRequest<Tutor>(`/admin/tutors/${id}`, userEmail, {
+  async updateTutor(id: number, tutor: Omit<Tutor, 'id'>): Promise<ApiResponse<Tutor>> {
+    return this.request<Tutor>(`/admin/tutors/${id}`, {
       method: 'PUT',
       body: JSON.stringify(tutor),
     });
   }
 
-  async deleteTutor(id: number, userEmail: string): Promise<ApiResponse<null>> {
-    return this.adminRequest<null>(`/admin/tutors/${id}`, userEmail, {
+  async deleteTutor(id: number): Promise<ApiResponse<null>> {
+    return this.request<null>(`/admin/tutors/${id}`, {
       method: 'DELETE',
     });
   }
 
-  async updateClient(id: number, client: Omit<Client, 'id'>, userEmail: string): Promise<ApiResponse<Client>> {
-    return this.adminRequest<Client>(`/admin/clients/${id}`, userEmail, {
+  async updateClient(id: number, client: Omit<Client, 'id'>): Promise<ApiResponse<Client>> {
+    return this.request<Client>(`/admin/clients/${id}`, {
       method: 'PUT',
       body: JSON.stringify(client),
     });
   }
 
-  async deleteClient(id: number, userEmail: string): Promise<ApiResponse<null>> {
-    return this.adminRequest<null>(`/admin/clients/${id}`, userEmail, {
+  async deleteClient(id: number): Promise<ApiResponse<null>> {
+    return this.request<null>(`/admin/clients/${id}`, {
       method: 'DELETE',
     });
   }